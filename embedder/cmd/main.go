@@ -14,10 +14,13 @@ import (
 
 	"speakr/embedder/internal/adapters/nats_adapter"
 	"speakr/embedder/internal/adapters/openai_adapter"
+	"speakr/embedder/internal/adapters/otel_adapter"
 	"speakr/embedder/internal/adapters/pgvector_adapter"
 	"speakr/embedder/internal/core"
+	"speakr/embedder/internal/ports"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -39,6 +42,22 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Set up tracing; deferred first, so the spans of the other shutdown steps are flushed
+	shutdownTracing, err := otel_adapter.SetupTracing(ctx, "speakr-embedder", logger,
+		otel_adapter.WithExporter(config.TracingExporter),
+	)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Connect to NATS
 	natsConn, err := nats.Connect(config.NatsURL)
 	if err != nil {
@@ -81,8 +100,32 @@ func main() {
 	// Create core service
 	service := core.NewService(embedder, vectorStore, logger)
 
-	// Create NATS subscriber
-	subscriber := nats_adapter.NewSubscriber(natsConn, logger)
+	// Create NATS subscriber. In jetstream mode events wait in a stream until they are
+	// processed, so none are lost while the service is down. Events that fail for good
+	// go to the dead-letter queue, where the transcriber can list and replay them.
+	deadLetters := nats_adapter.NewDeadLetterQueue(natsConn, "embedder", logger)
+	var subscriber ports.EventSubscriber = nats_adapter.NewSubscriber(natsConn, logger, deadLetters)
+	if config.NatsMode == "jetstream" {
+		js, err := natsConn.JetStream()
+		if err != nil {
+			logger.Error("Failed to create JetStream context", "error", err)
+			os.Exit(1)
+		}
+		if err := nats_adapter.EnsureEventStream(js, logger, config.JetStreamMaxAge); err != nil {
+			logger.Error("Failed to set up JetStream stream", "error", err)
+			os.Exit(1)
+		}
+		if err := nats_adapter.EnsureDeadLetterStream(js, logger); err != nil {
+			logger.Error("Failed to set up JetStream stream", "error", err)
+			os.Exit(1)
+		}
+		subscriber = nats_adapter.NewJetStreamSubscriber(js, logger,
+			nats_adapter.WithMaxDeliver(config.JetStreamMaxDeliver),
+			nats_adapter.WithBackoff(config.JetStreamBackoff...),
+			nats_adapter.WithAckWait(config.JetStreamAckWait),
+			nats_adapter.WithDeadLetterQueue(deadLetters),
+		)
+	}
 	defer subscriber.Close()
 
 	// Subscribe to transcription.succeeded events
@@ -95,7 +138,7 @@ func main() {
 	// Start health check server
 	go startHealthServer(logger, config.HealthPort)
 
-	logger.Info("Embedding Service started successfully")
+	logger.Info("Embedding Service started successfully", "nats_mode", config.NatsMode)
 
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
@@ -125,6 +168,12 @@ type Config struct {
 	DBPassword                string
 	DBName                    string
 	HealthPort                string
+	NatsMode                  string
+	JetStreamMaxDeliver       int
+	JetStreamBackoff          []time.Duration
+	JetStreamAckWait          time.Duration
+	JetStreamMaxAge           time.Duration
+	TracingExporter           string
 }
 
 func loadConfig() (*Config, error) {
@@ -138,6 +187,8 @@ func loadConfig() (*Config, error) {
 		DBPassword:    getEnvOrDefault("DB_PASSWORD", "postgres"),
 		DBName:        getEnvOrDefault("DB_NAME", "speakr"),
 		HealthPort:    getEnvOrDefault("HEALTH_PORT", "8081"),
+		NatsMode:      getEnvOrDefault("NATS_MODE", "core"),
+		TracingExporter: strings.ToLower(getEnvOrDefault("TRACING_EXPORTER", "none")),
 	}
 
 	// Handle embedding-specific configuration with fallback to shared config
@@ -153,6 +204,37 @@ func loadConfig() (*Config, error) {
 	}
 	config.DBPort = dbPort
 
+	// Parse JetStream delivery settings, used when NATS_MODE is jetstream
+	if config.NatsMode != "core" && config.NatsMode != "jetstream" {
+		return nil, fmt.Errorf("invalid NATS_MODE %q (expected core or jetstream)", config.NatsMode)
+	}
+
+	maxDeliver, err := strconv.Atoi(getEnvOrDefault("JETSTREAM_MAX_DELIVER", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_MAX_DELIVER: %w", err)
+	}
+	config.JetStreamMaxDeliver = maxDeliver
+
+	for _, step := range strings.Split(getEnvOrDefault("JETSTREAM_BACKOFF", "10s,1m,5m"), ",") {
+		delay, err := time.ParseDuration(strings.TrimSpace(step))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JETSTREAM_BACKOFF: %w", err)
+		}
+		config.JetStreamBackoff = append(config.JetStreamBackoff, delay)
+	}
+
+	ackWait, err := time.ParseDuration(getEnvOrDefault("JETSTREAM_ACK_WAIT", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_ACK_WAIT: %w", err)
+	}
+	config.JetStreamAckWait = ackWait
+
+	maxAge, err := time.ParseDuration(getEnvOrDefault("JETSTREAM_MAX_AGE", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_MAX_AGE: %w", err)
+	}
+	config.JetStreamMaxAge = maxAge
+
 	// Validate required fields
 	if config.OpenAIEmbeddingAPIKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY or OPENAI_EMBEDDING_API_KEY environment variable is required")
@@ -193,6 +275,8 @@ func startHealthServer(logger *slog.Logger, port string) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"healthy","service":"embedder"}`))
 	})
+	// Prometheus metrics of the service and the Go runtime
+	mux.Handle("/metrics", promhttp.Handler())
 
 	server := &http.Server{
 		Addr:    ":" + port,
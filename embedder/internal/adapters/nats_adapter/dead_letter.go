@@ -0,0 +1,123 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DeadLetterStream keeps the messages published to the dead-letter subjects until
+// they are replayed. The transcriber creates the same stream and answers the admin
+// commands that list and replay its entries.
+const DeadLetterStream = "SPEAKR_DLQ"
+
+// deadLetterPrefix is prepended to the subject of a failed message
+const deadLetterPrefix = "speakr.dlq."
+
+// deadLetterHeaderAllowance is left of the server's max payload for the trace headers
+// of a dead letter
+const deadLetterHeaderAllowance = 4096
+
+// DeadLetter is an event that could not be handled, published on
+// speakr.dlq.<original subject>. Truncated is set when the payload was dropped to
+// fit the server's max payload.
+type DeadLetter struct {
+	Subject       string    `json:"subject"`
+	Payload       string    `json:"payload,omitempty"`
+	Truncated     bool      `json:"truncated,omitempty"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	Service       string    `json:"service"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+// DeadLetterQueue publishes events that failed for good to their dead-letter subject
+type DeadLetterQueue struct {
+	conn    *nats.Conn
+	service string
+	logger  *slog.Logger
+}
+
+// NewDeadLetterQueue creates a dead-letter queue for a service
+func NewDeadLetterQueue(conn *nats.Conn, service string, logger *slog.Logger) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		conn:    conn,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Publish sends a failed event to speakr.dlq.<subject>. Failures are logged, as
+// there is nowhere left to send the event.
+func (q *DeadLetterQueue) Publish(ctx context.Context, subject string, payload []byte, cause error, attempts int) {
+	correlationID := ports.CorrelationID(ctx)
+	logger := q.logger.With(
+		"correlation_id", correlationID,
+		"subject", subject,
+		"attempts", attempts,
+	)
+
+	data, err := marshalDeadLetter(DeadLetter{
+		Subject:       subject,
+		Payload:       string(payload),
+		Error:         cause.Error(),
+		Attempts:      attempts,
+		Service:       q.service,
+		CorrelationID: correlationID,
+		FailedAt:      time.Now().UTC(),
+	}, int(q.conn.MaxPayload())-deadLetterHeaderAllowance)
+	if err != nil {
+		logger.Error("Failed to marshal dead letter", "error", err)
+		return
+	}
+
+	ctx, span := startProducerSpan(ctx, deadLetterPrefix+subject)
+	err = q.conn.PublishMsg(newMsg(ctx, deadLetterPrefix+subject, data))
+	endSpan(span, err)
+	if err != nil {
+		logger.Error("Failed to publish dead letter", "error", err)
+		return
+	}
+
+	logger.Warn("Event sent to the dead-letter queue", "dlq_subject", deadLetterPrefix+subject, "error", cause)
+}
+
+// marshalDeadLetter encodes a dead letter in at most limit bytes, dropping its payload
+// if it does not fit
+func marshalDeadLetter(letter DeadLetter, limit int) ([]byte, error) {
+	data, err := json.Marshal(letter)
+	if err != nil || len(data) <= limit {
+		return data, err
+	}
+
+	letter.Payload = ""
+	letter.Truncated = true
+	return json.Marshal(letter)
+}
+
+// EnsureDeadLetterStream creates the dead-letter stream if it does not exist yet
+func EnsureDeadLetterStream(js nats.JetStreamContext, logger *slog.Logger) error {
+	_, err := js.StreamInfo(DeadLetterStream)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		logger.Info("Stream does not exist, creating it", "stream", DeadLetterStream)
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:        DeadLetterStream,
+			Description: "Speakr messages that could not be handled",
+			Subjects:    []string{deadLetterPrefix + ">"},
+			// Kept until replayed, the oldest dropped if they pile up
+			MaxMsgs: 10000,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up stream %s: %w", DeadLetterStream, err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package nats_adapter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMarshalDeadLetter_DropsOversizedPayload(t *testing.T) {
+	letter := DeadLetter{
+		Subject: "speakr.event.transcription.succeeded",
+		Payload: `{"text":"` + strings.Repeat("word ", 1000) + `"}`,
+		Error:   "database unavailable",
+	}
+
+	data, err := marshalDeadLetter(letter, 1024)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var got DeadLetter
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal dead letter: %v", err)
+	}
+	if len(data) > 1024 || !got.Truncated || got.Payload != "" || got.Error != letter.Error {
+		t.Errorf("Expected the payload dropped and the entry kept, got %d bytes: %+v", len(data), got)
+	}
+}
@@ -0,0 +1,102 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+)
+
+// Headers that trace a request across services. Every message gets its own
+// message_id; the messages published while handling it carry that ID as their
+// causation_id and keep its correlation_id.
+const (
+	headerMessageID     = "message_id"
+	headerCorrelationID = "correlation_id"
+	headerCausationID   = "causation_id"
+)
+
+// headerCarrier adapts NATS headers to the trace context propagator
+type headerCarrier nats.Header
+
+// Get returns the first value of a header
+func (c headerCarrier) Get(key string) string {
+	return nats.Header(c).Get(key)
+}
+
+// Set replaces the values of a header
+func (c headerCarrier) Set(key, value string) {
+	nats.Header(c).Set(key, value)
+}
+
+// Keys lists the headers
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// newMsg creates a message with a new message ID, the correlation and causation IDs
+// of the context and its trace context
+func newMsg(ctx context.Context, subject string, data []byte) *nats.Msg {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(msg.Header))
+	msg.Header.Set(headerMessageID, uuid.New().String())
+	if correlationID := ports.CorrelationID(ctx); correlationID != "" {
+		msg.Header.Set(headerCorrelationID, correlationID)
+	}
+	if causationID := ports.CausationID(ctx); causationID != "" {
+		msg.Header.Set(headerCausationID, causationID)
+	}
+	return msg
+}
+
+// traceIDs holds the IDs a sender may put in the payload when it cannot set headers
+type traceIDs struct {
+	MessageID     string `json:"message_id"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+// messageIDs returns the message and correlation IDs of a received message, read
+// from its headers and else from its payload. A message without a correlation ID
+// starts a new one.
+func messageIDs(msg *nats.Msg) (messageID, correlationID string) {
+	messageID = msg.Header.Get(headerMessageID)
+	correlationID = msg.Header.Get(headerCorrelationID)
+
+	if messageID == "" || correlationID == "" {
+		var ids traceIDs
+		_ = json.Unmarshal(msg.Data, &ids)
+		if messageID == "" {
+			messageID = ids.MessageID
+		}
+		if correlationID == "" {
+			correlationID = ids.CorrelationID
+		}
+	}
+
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+	return messageID, correlationID
+}
+
+// messageContext returns the context for handling a received message: it keeps the
+// sender's correlation ID and trace, and the message is the cause of anything
+// published for it
+func messageContext(msg *nats.Msg) context.Context {
+	messageID, correlationID := messageIDs(msg)
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(msg.Header))
+	ctx = ports.WithCorrelationID(ctx, correlationID)
+	if messageID != "" {
+		ctx = ports.WithCausationID(ctx, messageID)
+	}
+	return ctx
+}
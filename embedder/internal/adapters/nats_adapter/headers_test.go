@@ -0,0 +1,37 @@
+package nats_adapter
+
+import (
+	"testing"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestMessageContext(t *testing.T) {
+	msg := nats.NewMsg("speakr.event.transcription.succeeded")
+	msg.Header.Set(headerMessageID, "msg-1")
+	msg.Header.Set(headerCorrelationID, "corr-1")
+
+	ctx := messageContext(msg)
+	if got := ports.CorrelationID(ctx); got != "corr-1" {
+		t.Errorf("Expected correlation ID corr-1, got %q", got)
+	}
+	if got := ports.CausationID(ctx); got != "msg-1" {
+		t.Errorf("Expected causation ID msg-1, got %q", got)
+	}
+
+	// Publishers that cannot set headers may put the IDs in the payload
+	payloadOnly := &nats.Msg{
+		Subject: "speakr.event.transcription.succeeded",
+		Data:    []byte(`{"recording_id":"rec-1","correlation_id":"corr-2"}`),
+	}
+	if got := ports.CorrelationID(messageContext(payloadOnly)); got != "corr-2" {
+		t.Errorf("Expected correlation ID corr-2 from the payload, got %q", got)
+	}
+
+	bare := &nats.Msg{Subject: "speakr.event.transcription.succeeded", Data: []byte(`{}`)}
+	if got := ports.CorrelationID(messageContext(bare)); got == "" {
+		t.Error("Expected a new correlation ID")
+	}
+}
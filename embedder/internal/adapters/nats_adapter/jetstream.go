@@ -0,0 +1,311 @@
+package nats_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// EventStream is the JetStream stream that keeps events while their consumers are down.
+// The transcriber creates the same stream; whichever service starts first creates it.
+const EventStream = "SPEAKR_EVENTS"
+
+// EnsureEventStream creates the event stream if it does not exist yet. An existing
+// stream keeps its limits, so they can be tuned on the server, but gets its subjects
+// corrected.
+func EnsureEventStream(js nats.JetStreamContext, logger *slog.Logger, maxAge time.Duration) error {
+	subjects := []string{"speakr.event.>"}
+
+	info, err := js.StreamInfo(EventStream)
+	switch {
+	case errors.Is(err, nats.ErrStreamNotFound):
+		logger.Info("Stream does not exist, creating it", "stream", EventStream)
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:        EventStream,
+			Description: "Speakr events",
+			Subjects:    subjects,
+			MaxAge:      maxAge,
+		})
+	case err == nil && !slices.Equal(info.Config.Subjects, subjects):
+		logger.Warn("Correcting stream subjects", "stream", EventStream, "subjects", subjects, "previous", info.Config.Subjects)
+		existing := info.Config
+		existing.Subjects = subjects
+		_, err = js.UpdateStream(&existing)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up stream %s: %w", EventStream, err)
+	}
+	return nil
+}
+
+// JetStreamSubscriberConfig holds configuration for the JetStream subscriber
+type JetStreamSubscriberConfig struct {
+	Durable     string
+	MaxDeliver  int
+	Backoff     []time.Duration
+	AckWait     time.Duration
+	DeadLetters *DeadLetterQueue
+}
+
+// JetStreamSubscriberOption is a functional option for configuring the JetStream subscriber
+type JetStreamSubscriberOption func(*JetStreamSubscriberConfig)
+
+// WithDurable sets the prefix of the durable consumer names, shared by every replica
+func WithDurable(durable string) JetStreamSubscriberOption {
+	return func(c *JetStreamSubscriberConfig) {
+		c.Durable = durable
+	}
+}
+
+// WithMaxDeliver sets how often an event is delivered before it is given up
+func WithMaxDeliver(maxDeliver int) JetStreamSubscriberOption {
+	return func(c *JetStreamSubscriberConfig) {
+		c.MaxDeliver = maxDeliver
+	}
+}
+
+// WithBackoff sets the delays before each redelivery of a failed event; the last one repeats
+func WithBackoff(backoff ...time.Duration) JetStreamSubscriberOption {
+	return func(c *JetStreamSubscriberConfig) {
+		c.Backoff = backoff
+	}
+}
+
+// WithAckWait sets how long a handler may go without reporting progress before its
+// event is delivered again
+func WithAckWait(ackWait time.Duration) JetStreamSubscriberOption {
+	return func(c *JetStreamSubscriberConfig) {
+		c.AckWait = ackWait
+	}
+}
+
+// WithDeadLetterQueue sends events that are given up to the dead-letter queue
+func WithDeadLetterQueue(deadLetters *DeadLetterQueue) JetStreamSubscriberOption {
+	return func(c *JetStreamSubscriberConfig) {
+		c.DeadLetters = deadLetters
+	}
+}
+
+// JetStreamSubscriber implements the EventSubscriber port with durable JetStream
+// consumers. An event is acknowledged only after its handler succeeds, so events
+// published while the service is down are processed once it is back.
+type JetStreamSubscriber struct {
+	js     nats.JetStreamContext
+	config JetStreamSubscriberConfig
+	logger *slog.Logger
+
+	subs    []*nats.Subscription
+	cancel  context.CancelFunc
+	ctx     context.Context
+	running sync.WaitGroup
+}
+
+// NewJetStreamSubscriber creates a new JetStream subscriber
+func NewJetStreamSubscriber(js nats.JetStreamContext, logger *slog.Logger, opts ...JetStreamSubscriberOption) *JetStreamSubscriber {
+	config := JetStreamSubscriberConfig{
+		Durable:    "speakr-embedder",
+		MaxDeliver: 5,
+		Backoff:    []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute},
+		AckWait:    30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JetStreamSubscriber{
+		js:     js,
+		config: config,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Subscribe consumes a subject of the event stream through a durable consumer
+func (s *JetStreamSubscriber) Subscribe(ctx context.Context, subject string, handler ports.EventHandler) error {
+	durable := durableName(s.config.Durable, subject)
+	logger := s.logger.With("subject", subject, "stream", EventStream, "consumer", durable)
+
+	if s.config.MaxDeliver < 1 || s.config.AckWait <= 0 {
+		return fmt.Errorf("invalid delivery settings for %s: max deliver %d, ack wait %s", subject, s.config.MaxDeliver, s.config.AckWait)
+	}
+
+	consumer := &nats.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: subject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       s.config.AckWait,
+		MaxDeliver:    s.config.MaxDeliver,
+		DeliverPolicy: nats.DeliverAllPolicy,
+	}
+
+	// The consumer is created here rather than by the subscription, which would
+	// delete it again on unsubscribe
+	_, err := s.js.ConsumerInfo(EventStream, durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = s.js.AddConsumer(EventStream, consumer)
+	} else if err == nil {
+		_, err = s.js.UpdateConsumer(EventStream, consumer)
+	}
+	if err != nil {
+		logger.Error("Failed to set up consumer", "error", err)
+		return fmt.Errorf("failed to set up consumer %s: %w", durable, err)
+	}
+
+	sub, err := s.js.PullSubscribe(subject, durable, nats.Bind(EventStream, durable))
+	if err != nil {
+		logger.Error("Failed to subscribe to subject", "error", err)
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+	s.subs = append(s.subs, sub)
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.fetch(logger, sub, handler)
+	}()
+
+	logger.Info("Successfully subscribed to subject", "max_deliver", s.config.MaxDeliver)
+	return nil
+}
+
+// fetch handles the events of a subscription one at a time until the subscriber closes
+func (s *JetStreamSubscriber) fetch(logger *slog.Logger, sub *nats.Subscription, handler ports.EventHandler) {
+	for s.ctx.Err() == nil {
+		msgs, err := sub.Fetch(1, nats.Context(s.ctx))
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			continue
+		}
+		if err != nil {
+			logger.Warn("Failed to fetch from stream", "error", err)
+			select {
+			case <-s.ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			s.handle(logger, msg, handler)
+		}
+	}
+}
+
+// handle runs the handler for an event and acknowledges it once it succeeds, or
+// schedules its redelivery when it failed for a reason that may pass
+func (s *JetStreamSubscriber) handle(logger *slog.Logger, msg *nats.Msg, handler ports.EventHandler) {
+	msgCtx, span := startConsumerSpan(messageContext(msg), msg.Subject)
+	defer span.End()
+	logger = logger.With("correlation_id", ports.CorrelationID(msgCtx))
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		logger.Error("Failed to read stream message metadata", "error", err)
+		return
+	}
+	logger = logger.With("stream_sequence", meta.Sequence.Stream, "delivery", meta.NumDelivered)
+
+	// Slow handlers keep the event from being delivered again while they run
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.config.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					logger.Warn("Failed to extend ack deadline", "error", err)
+				}
+			}
+		}
+	}()
+	err = handler(msgCtx, msg.Subject, msg.Data)
+	close(done)
+	countEvent(msg.Subject, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	var ackErr error
+	switch {
+	case err == nil:
+		ackErr = msg.Ack()
+	case errors.Is(err, ports.ErrMalformedEvent):
+		logger.Warn("Event cannot be processed, not redelivering it", "error", err)
+		ackErr = msg.Term()
+		s.deadLetter(msgCtx, msg, err, meta.NumDelivered)
+	case int(meta.NumDelivered) >= s.config.MaxDeliver:
+		logger.Error("Handler failed on the last delivery of the event", "error", err)
+		ackErr = msg.Term()
+		s.deadLetter(msgCtx, msg, err, meta.NumDelivered)
+	default:
+		delay := redeliveryDelay(s.config.Backoff, meta.NumDelivered)
+		logger.Warn("Handler failed to process event, redelivering it", "error", err, "delay", delay)
+		ackErr = msg.NakWithDelay(delay)
+		eventRetries.WithLabelValues(msg.Subject).Inc()
+	}
+
+	if ackErr != nil {
+		logger.Error("Failed to acknowledge stream message", "error", ackErr)
+	}
+}
+
+// deadLetter sends an event that is given up to the dead-letter queue
+func (s *JetStreamSubscriber) deadLetter(ctx context.Context, msg *nats.Msg, err error, delivery uint64) {
+	if s.config.DeadLetters != nil {
+		s.config.DeadLetters.Publish(ctx, msg.Subject, msg.Data, err, int(delivery))
+	}
+}
+
+// Close stops fetching events and waits for the running handlers. The durable
+// consumers stay on the server and resume where they left off.
+func (s *JetStreamSubscriber) Close() error {
+	s.logger.Info("Closing JetStream subscriber", "subscriptions", len(s.subs))
+	s.cancel()
+	s.running.Wait()
+
+	var lastErr error
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			s.logger.Error("Failed to unsubscribe", "error", err)
+			lastErr = err
+		}
+	}
+
+	s.subs = nil
+	return lastErr
+}
+
+// durableName derives a consumer name from a subject, as names may not contain
+// dots or wildcards
+func durableName(prefix, subject string) string {
+	return prefix + "_" + strings.NewReplacer(".", "_", "*", "any", ">", "all").Replace(subject)
+}
+
+// redeliveryDelay returns the backoff after a failed delivery, repeating the last
+// step once the schedule runs out
+func redeliveryDelay(backoff []time.Duration, delivery uint64) time.Duration {
+	if len(backoff) == 0 {
+		return 0
+	}
+	step := int(delivery) - 1
+	if step >= len(backoff) {
+		step = len(backoff) - 1
+	}
+	return backoff[step]
+}
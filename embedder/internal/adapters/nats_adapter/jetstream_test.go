@@ -0,0 +1,46 @@
+package nats_adapter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurableName(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"speakr.event.transcription.succeeded", "speakr-embedder_speakr_event_transcription_succeeded"},
+		{"speakr.event.*", "speakr-embedder_speakr_event_any"},
+		{"speakr.event.>", "speakr-embedder_speakr_event_all"},
+	}
+
+	for _, tt := range tests {
+		if got := durableName("speakr-embedder", tt.subject); got != tt.want {
+			t.Errorf("Subject %s: expected %s, got %s", tt.subject, tt.want, got)
+		}
+	}
+}
+
+func TestRedeliveryDelay(t *testing.T) {
+	backoff := []time.Duration{time.Second, time.Minute}
+
+	tests := []struct {
+		delivery uint64
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, time.Minute},
+		{7, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := redeliveryDelay(backoff, tt.delivery); got != tt.want {
+			t.Errorf("Delivery %d: expected delay %s, got %s", tt.delivery, tt.want, got)
+		}
+	}
+
+	if got := redeliveryDelay(nil, 1); got != 0 {
+		t.Errorf("Expected no delay without a backoff, got %s", got)
+	}
+}
@@ -0,0 +1,39 @@
+package nats_adapter
+
+import (
+	"errors"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Event metrics, labelled by the subject the event was received on
+var (
+	eventsHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "embedder",
+		Name:      "events_handled_total",
+		Help:      "Events handled, by subject and outcome: ok, malformed or error.",
+	}, []string{"subject", "outcome"})
+
+	eventRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "embedder",
+		Name:      "event_retries_total",
+		Help:      "Failed stream events scheduled for redelivery, by subject.",
+	}, []string{"subject"})
+)
+
+// countEvent records the outcome of handling an event
+func countEvent(subject string, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, ports.ErrMalformedEvent):
+		outcome = "malformed"
+	case err != nil:
+		outcome = "error"
+	}
+	eventsHandled.WithLabelValues(subject, outcome).Inc()
+}
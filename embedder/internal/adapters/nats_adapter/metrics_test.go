@@ -0,0 +1,31 @@
+package nats_adapter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"speakr/embedder/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCountEvent(t *testing.T) {
+	subject := "speakr.event.transcription.succeeded"
+	testCases := []struct {
+		err     error
+		outcome string
+	}{
+		{nil, "ok"},
+		{fmt.Errorf("recording ID is required: %w", ports.ErrMalformedEvent), "malformed"},
+		{errors.New("database unavailable"), "error"},
+	}
+
+	for _, tc := range testCases {
+		before := testutil.ToFloat64(eventsHandled.WithLabelValues(subject, tc.outcome))
+		countEvent(subject, tc.err)
+		if got := testutil.ToFloat64(eventsHandled.WithLabelValues(subject, tc.outcome)); got != before+1 {
+			t.Errorf("For error %v, expected one more %q event, got %v", tc.err, tc.outcome, got-before)
+		}
+	}
+}
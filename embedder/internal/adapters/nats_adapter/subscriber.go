@@ -10,19 +10,26 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
+// coreDeliveryAttempts is how often core NATS delivers an event: once, as it does not
+// redeliver, so an event whose handler fails is dead-lettered after its only attempt
+const coreDeliveryAttempts = 1
+
 // Subscriber implements the EventSubscriber port using NATS
 type Subscriber struct {
-	conn   *nats.Conn
-	logger *slog.Logger
-	subs   []*nats.Subscription
+	conn        *nats.Conn
+	logger      *slog.Logger
+	deadLetters *DeadLetterQueue
+	subs        []*nats.Subscription
 }
 
-// NewSubscriber creates a new NATS subscriber
-func NewSubscriber(conn *nats.Conn, logger *slog.Logger) *Subscriber {
+// NewSubscriber creates a new NATS subscriber. Events whose handler fails are sent to
+// deadLetters, unless it is nil.
+func NewSubscriber(conn *nats.Conn, logger *slog.Logger, deadLetters *DeadLetterQueue) *Subscriber {
 	return &Subscriber{
-		conn:   conn,
-		logger: logger,
-		subs:   make([]*nats.Subscription, 0),
+		conn:        conn,
+		logger:      logger,
+		deadLetters: deadLetters,
+		subs:        make([]*nats.Subscription, 0),
 	}
 }
 
@@ -34,22 +41,23 @@ func (s *Subscriber) Subscribe(ctx context.Context, subject string, handler port
 
 	// Create NATS message handler that wraps the port handler
 	natsHandler := func(msg *nats.Msg) {
-		// Create context for this message
-		msgCtx := context.Background()
-		
-		// Add correlation ID if available from message headers
-		if msg.Header != nil {
-			if correlationID := msg.Header.Get("correlation_id"); correlationID != "" {
-				msgCtx = context.WithValue(msgCtx, "correlation_id", correlationID)
-			}
-		}
+		// Create context for this message, carrying its correlation ID from the
+		// headers or the payload
+		msgCtx, span := startConsumerSpan(messageContext(msg), msg.Subject)
 
 		// Call the handler
-		if err := handler(msgCtx, msg.Subject, msg.Data); err != nil {
+		err := handler(msgCtx, msg.Subject, msg.Data)
+		endSpan(span, err)
+		countEvent(msg.Subject, err)
+		if err != nil {
 			logger.Error("Handler failed to process message", 
 				"error", err, 
 				"subject", msg.Subject,
 				"data_size", len(msg.Data))
+
+			if s.deadLetters != nil {
+				s.deadLetters.Publish(msgCtx, msg.Subject, msg.Data, err, coreDeliveryAttempts)
+			}
 		}
 	}
 
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // EmbedderConfig holds configuration for the OpenAI embedder
@@ -88,8 +90,10 @@ func NewEmbedder(logger *slog.Logger, opts ...EmbedderOption) (*Embedder, error)
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
 
+	// Requests to the embeddings API are traced as client spans
 	client := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
 	return &Embedder{
@@ -121,7 +125,9 @@ func (e *Embedder) GenerateEmbedding(ctx context.Context, text string) ([]float3
 	for attempt := 1; attempt <= e.config.MaxRetries; attempt++ {
 		logger.Info("Embedding attempt", "attempt", attempt, "max_retries", e.config.MaxRetries)
 
+		started := time.Now()
 		embedding, err := e.generateWithRetry(ctx, text)
+		observeRequest(e.config.Model, started, err)
 		if err == nil {
 			logger.Info("Embedding generated successfully", "dimensions", len(embedding))
 			return embedding, nil
@@ -140,6 +146,7 @@ func (e *Embedder) GenerateEmbedding(ctx context.Context, text string) ([]float3
 		if attempt < e.config.MaxRetries {
 			waitTime := time.Duration(attempt) * time.Second
 			logger.Info("Waiting before retry", "wait_time", waitTime)
+			requestRetries.WithLabelValues(e.config.Model).Inc()
 			time.Sleep(waitTime)
 		}
 	}
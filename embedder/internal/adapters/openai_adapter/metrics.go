@@ -0,0 +1,73 @@
+package openai_adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Embeddings API metrics, labelled by model
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "speakr",
+		Subsystem: "embedder",
+		Name:      "provider_request_duration_seconds",
+		Help:      "Time an embeddings request took, by model and outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"model", "outcome"})
+
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "embedder",
+		Name:      "provider_errors_total",
+		Help:      "Failed embeddings requests, by model and error.",
+	}, []string{"model", "error"})
+
+	requestRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "embedder",
+		Name:      "provider_retries_total",
+		Help:      "Embeddings requests retried after a failed attempt, by model.",
+	}, []string{"model"})
+)
+
+// errorTypes names the sentinel errors of the adapter for the error label
+var errorTypes = []struct {
+	err  error
+	name string
+}{
+	{ErrAPIKeyNotSet, "api_key_not_set"},
+	{ErrAPIKeyInvalid, "api_key_invalid"},
+	{ErrQuotaExceeded, "quota_exceeded"},
+	{ErrTextTooLong, "text_too_long"},
+	{ErrEmptyText, "empty_text"},
+	{ErrRequestTimeout, "request_timeout"},
+	{ErrServiceUnavailable, "service_unavailable"},
+	{ErrEmptyEmbedding, "empty_embedding"},
+	{ErrNetworkError, "network_error"},
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+}
+
+// observeRequest records the duration and outcome of one embeddings request
+func observeRequest(model string, started time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		requestErrors.WithLabelValues(model, errorType(err)).Inc()
+	}
+	requestDuration.WithLabelValues(model, outcome).Observe(time.Since(started).Seconds())
+}
+
+// errorType returns the metric label of a request error
+func errorType(err error) string {
+	for _, errorType := range errorTypes {
+		if errors.Is(err, errorType.err) {
+			return errorType.name
+		}
+	}
+	return "other"
+}
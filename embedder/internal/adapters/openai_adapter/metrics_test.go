@@ -0,0 +1,26 @@
+package openai_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorType(t *testing.T) {
+	testCases := []struct {
+		err  error
+		want string
+	}{
+		{ErrQuotaExceeded, "quota_exceeded"},
+		{fmt.Errorf("embedding generation failed after 3 attempts: %w", ErrServiceUnavailable), "service_unavailable"},
+		{context.Canceled, "canceled"},
+		{errors.New("failed to make request"), "other"},
+	}
+
+	for _, tc := range testCases {
+		if got := errorType(tc.err); got != tc.want {
+			t.Errorf("For error %v, expected %q, got %q", tc.err, tc.want, got)
+		}
+	}
+}
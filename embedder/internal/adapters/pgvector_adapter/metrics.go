@@ -0,0 +1,15 @@
+package pgvector_adapter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// insertDuration measures the upserts of vector records, by outcome
+var insertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "speakr",
+	Subsystem: "embedder",
+	Name:      "db_insert_duration_seconds",
+	Help:      "Time taken to insert a vector record into pgvector, by outcome.",
+	Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+}, []string{"outcome"})
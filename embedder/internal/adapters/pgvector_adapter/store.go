@@ -179,11 +179,19 @@ func (s *Store) StoreRecord(ctx context.Context, record ports.VectorRecord) erro
 			updated_at = NOW()
 	`
 
+	ctx, span := startQuerySpan(ctx, "INSERT", "transcriptions")
+	started := time.Now()
 	_, err := s.db.ExecContext(ctx, query, 
 		record.RecordingID, 
 		record.TranscribedText, 
 		pq.Array(record.Tags), 
 		embeddingStr)
+	endQuerySpan(span, err)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	insertDuration.WithLabelValues(outcome).Observe(time.Since(started).Seconds())
 	
 	if err != nil {
 		logger.Error("Failed to store vector record", "error", err)
@@ -226,12 +234,14 @@ func (s *Store) GetRecord(ctx context.Context, recordingID string) (*ports.Vecto
 	var embeddingStr string
 	var tags pq.StringArray
 
+	ctx, span := startQuerySpan(ctx, "SELECT", "transcriptions")
 	err := s.db.QueryRowContext(ctx, query, recordingID).Scan(
 		&record.RecordingID,
 		&record.TranscribedText,
 		&tags,
 		&embeddingStr,
 	)
+	endQuerySpan(span, err)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
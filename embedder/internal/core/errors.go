@@ -1,11 +1,16 @@
 package core
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"speakr/embedder/internal/ports"
+)
 
 // Custom error types for predictable failures
 var (
-	ErrEmptyText          = errors.New("transcribed text cannot be empty")
-	ErrMissingRecordingID = errors.New("recording ID is required")
+	ErrEmptyText          = fmt.Errorf("transcribed text cannot be empty: %w", ports.ErrMalformedEvent)
+	ErrMissingRecordingID = fmt.Errorf("recording ID is required: %w", ports.ErrMalformedEvent)
 	ErrRecordNotFound     = errors.New("vector record not found")
-	ErrInvalidEvent       = errors.New("invalid event format")
+	ErrInvalidEvent       = fmt.Errorf("invalid event format: %w", ports.ErrMalformedEvent)
 )
\ No newline at end of file
@@ -0,0 +1,16 @@
+package core
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// embeddingDimensions counts the embeddings generated by their size, which changes
+// with the embedding model
+var embeddingDimensions = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: "speakr",
+	Subsystem: "embedder",
+	Name:      "embedding_dimensions",
+	Help:      "Dimensions of the embeddings generated.",
+	Buckets:   []float64{256, 384, 512, 768, 1024, 1536, 2048, 3072, 4096},
+})
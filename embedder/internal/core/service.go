@@ -41,6 +41,9 @@ type TranscriptionSucceededEvent struct {
 
 // ProcessTranscription handles a transcription.succeeded event
 func (s *Service) ProcessTranscription(ctx context.Context, recordingID, transcribedText string, tags []string) error {
+	ctx, span := startSpan(ctx, "Service.ProcessTranscription", recordingID)
+	defer span.End()
+
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
 		"correlation_id", correlationID,
@@ -65,7 +68,9 @@ func (s *Service) ProcessTranscription(ctx context.Context, recordingID, transcr
 
 	// Generate embedding
 	logger.Info("Generating embedding for transcribed text")
-	embedding, err := s.embeddingGenerator.GenerateEmbedding(ctx, transcribedText)
+	embedCtx, embedSpan := tracer.Start(ctx, "EmbeddingGenerator.GenerateEmbedding")
+	embedding, err := s.embeddingGenerator.GenerateEmbedding(embedCtx, transcribedText)
+	endSpan(embedSpan, err)
 	if err != nil {
 		logger.Error("Failed to generate embedding", "error", err)
 		return fmt.Errorf("failed to generate embedding: %w", err)
@@ -73,6 +78,7 @@ func (s *Service) ProcessTranscription(ctx context.Context, recordingID, transcr
 
 	logger.Info("Embedding generated successfully", 
 		"embedding_dimensions", len(embedding))
+	embeddingDimensions.Observe(float64(len(embedding)))
 
 	// Create vector record
 	record := ports.VectorRecord{
@@ -84,7 +90,10 @@ func (s *Service) ProcessTranscription(ctx context.Context, recordingID, transcr
 
 	// Store in vector database
 	logger.Info("Storing vector record in database")
-	if err := s.vectorStore.StoreRecord(ctx, record); err != nil {
+	storeCtx, storeSpan := tracer.Start(ctx, "VectorStore.StoreRecord")
+	err = s.vectorStore.StoreRecord(storeCtx, record)
+	endSpan(storeSpan, err)
+	if err != nil {
 		logger.Error("Failed to store vector record", "error", err)
 		return fmt.Errorf("failed to store vector record: %w", err)
 	}
@@ -112,7 +121,7 @@ func (s *Service) HandleTranscriptionEvent(ctx context.Context, subject string,
 	var event TranscriptionSucceededEvent
 	if err := json.Unmarshal(data, &event); err != nil {
 		logger.Error("Failed to unmarshal transcription event", "error", err, "data", string(data))
-		return fmt.Errorf("failed to unmarshal transcription event: %w", err)
+		return fmt.Errorf("failed to unmarshal transcription event: %w: %v", ErrInvalidEvent, err)
 	}
 
 	// Validate event data
@@ -127,7 +136,7 @@ func (s *Service) HandleTranscriptionEvent(ctx context.Context, subject string,
 	}
 
 	// Add correlation ID to context for downstream processing
-	ctx = context.WithValue(ctx, "correlation_id", correlationID)
+	ctx = ports.WithCorrelationID(ctx, correlationID)
 
 	// Process the transcription
 	return s.ProcessTranscription(ctx, event.RecordingID, event.TranscribedText, event.Tags)
@@ -135,6 +144,9 @@ func (s *Service) HandleTranscriptionEvent(ctx context.Context, subject string,
 
 // GetRecord retrieves a stored vector record
 func (s *Service) GetRecord(ctx context.Context, recordingID string) (*ports.VectorRecord, error) {
+	ctx, span := startSpan(ctx, "Service.GetRecord", recordingID)
+	defer span.End()
+
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
 		"correlation_id", correlationID,
@@ -164,10 +176,8 @@ func (s *Service) GetRecord(ctx context.Context, recordingID string) (*ports.Vec
 }
 
 func (s *Service) getCorrelationID(ctx context.Context) string {
-	if correlationID := ctx.Value("correlation_id"); correlationID != nil {
-		if id, ok := correlationID.(string); ok {
-			return id
-		}
+	if correlationID := ports.CorrelationID(ctx); correlationID != "" {
+		return correlationID
 	}
 	return uuid.New().String()
 }
\ No newline at end of file
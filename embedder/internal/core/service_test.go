@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
@@ -120,6 +121,9 @@ func TestService_HandleTranscriptionEvent_InvalidJSON(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for invalid JSON, got nil")
 	}
+	if !errors.Is(err, ports.ErrMalformedEvent) {
+		t.Errorf("Expected a malformed event error, so it is not redelivered, got %v", err)
+	}
 }
 
 func TestService_HandleTranscriptionEvent_MissingRecordingID(t *testing.T) {
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records how long processing a transcript takes, split into the calls to
+// the embedding generator and the vector store. Spans are dropped until the service
+// installs a tracer provider.
+var tracer = otel.Tracer("speakr/embedder/internal/core")
+
+// startSpan starts the span of an operation on a recording
+func startSpan(ctx context.Context, name, recordingID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attribute.String("recording_id", recordingID)))
+}
+
+// endSpan ends a span, recording the error its operation failed with, if any
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
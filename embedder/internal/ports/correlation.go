@@ -0,0 +1,35 @@
+package ports
+
+import "context"
+
+// contextKey keeps the IDs below from colliding with context values of other packages
+type contextKey int
+
+const (
+	correlationIDKey contextKey = iota
+	causationIDKey
+)
+
+// WithCorrelationID returns a context carrying the ID shared by every message and log
+// line of one request, from the command that started it to its last event
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID of a context, or "" if it has none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// WithCausationID returns a context carrying the ID of the message being handled, which
+// becomes the causation ID of every message published while handling it
+func WithCausationID(ctx context.Context, causationID string) context.Context {
+	return context.WithValue(ctx, causationIDKey, causationID)
+}
+
+// CausationID returns the causation ID of a context, or "" if it has none
+func CausationID(ctx context.Context) string {
+	id, _ := ctx.Value(causationIDKey).(string)
+	return id
+}
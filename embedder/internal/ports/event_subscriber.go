@@ -2,8 +2,13 @@ package ports
 
 import (
 	"context"
+	"errors"
 )
 
+// ErrMalformedEvent is returned, possibly wrapped, by a handler for an event it can never
+// process, so a durable subscriber does not deliver it again
+var ErrMalformedEvent = errors.New("malformed event")
+
 // EventHandler defines the function signature for handling events
 type EventHandler func(ctx context.Context, subject string, data []byte) error
 
@@ -8,12 +8,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/speakr/query_svc/internal/adapters/http_adapter"
 	"github.com/speakr/query_svc/internal/adapters/openai_adapter"
+	"github.com/speakr/query_svc/internal/adapters/otel_adapter"
 	"github.com/speakr/query_svc/internal/adapters/pgvector_adapter"
 	"github.com/speakr/query_svc/internal/core"
 )
@@ -29,6 +31,7 @@ type Config struct {
 	DBUser           string
 	DBPassword       string
 	DBName           string
+	TracingExporter  string
 }
 
 func main() {
@@ -46,6 +49,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Set up tracing; spans are exported only when TRACING_EXPORTER names an exporter
+	shutdownTracing, err := otel_adapter.SetupTracing(context.Background(), "speakr-query", logger,
+		otel_adapter.WithExporter(config.TracingExporter),
+	)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// Setup database connection
 	db, err := setupDatabase(config)
 	if err != nil {
@@ -100,6 +112,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
+
 	logger.Info("Server exited")
 }
 
@@ -115,6 +131,7 @@ func loadConfig() (*Config, error) {
 		DBUser:        getEnvOrDefault("DB_USER", "postgres"),
 		DBPassword:    os.Getenv("DB_PASSWORD"),
 		DBName:        getEnvOrDefault("DB_NAME", "speakr"),
+		TracingExporter: strings.ToLower(getEnvOrDefault("TRACING_EXPORTER", "none")),
 	}
 
 	// Validate required configuration
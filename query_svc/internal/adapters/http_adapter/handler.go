@@ -1,7 +1,6 @@
 package http_adapter
 
 import (
-	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -9,8 +8,10 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/speakr/query_svc/internal/core"
 	"github.com/speakr/query_svc/internal/ports"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // Handler implements the HTTP API for the query service
@@ -40,19 +41,40 @@ func (h *Handler) SetupRoutes() http.Handler {
 	// Health endpoint
 	r.Get("/health", h.healthHandler)
 
+	// Prometheus metrics of the service and the Go runtime
+	r.Handle("/metrics", promhttp.Handler())
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Post("/query", h.queryHandler)
 	})
 
-	return r
+	// Each request gets a server span, continuing the caller's trace if it sent a
+	// traceparent header; health checks and metric scrapes are left out
+	return otelhttp.NewHandler(r, "query_svc",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+		}),
+	)
 }
 
-// correlationIDMiddleware adds correlation ID to context
+// correlationIDHeader carries the correlation ID of a request and its response, so a
+// search can be traced together with the recording and transcription before it
+const correlationIDHeader = "X-Correlation-ID"
+
+// correlationIDMiddleware adds the caller's correlation ID, or else the request ID, to
+// the context and echoes it in the response
 func (h *Handler) correlationIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		correlationID := middleware.GetReqID(r.Context())
-		ctx := context.WithValue(r.Context(), "correlation_id", correlationID)
+		correlationID := r.Header.Get(correlationIDHeader)
+		if correlationID == "" {
+			correlationID = middleware.GetReqID(r.Context())
+		}
+		w.Header().Set(correlationIDHeader, correlationID)
+		ctx := ports.WithCorrelationID(r.Context(), correlationID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -81,7 +103,7 @@ type ErrorResponse struct {
 
 // queryHandler handles search query requests
 func (h *Handler) queryHandler(w http.ResponseWriter, r *http.Request) {
-	correlationID := r.Context().Value("correlation_id")
+	correlationID := ports.CorrelationID(r.Context())
 
 	h.logger.Info("Received query request",
 		"correlation_id", correlationID,
@@ -3,8 +3,10 @@ package openai_adapter
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // Embedder implements the EmbeddingGenerator port using OpenAI API
@@ -19,6 +21,8 @@ func NewEmbedder(apiKey, baseURL, model string) *Embedder {
 	if baseURL != "" {
 		config.BaseURL = baseURL
 	}
+	// Requests to the embeddings API are traced as client spans
+	config.HTTPClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
 	
 	client := openai.NewClientWithConfig(config)
 	
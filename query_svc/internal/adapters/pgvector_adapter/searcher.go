@@ -55,6 +55,14 @@ func (s *Searcher) Search(ctx context.Context, req ports.SearchRequest) ([]ports
 	query += fmt.Sprintf(" ORDER BY similarity DESC LIMIT $%d", argIndex)
 	args = append(args, req.Limit)
 
+	ctx, span := startQuerySpan(ctx, "SELECT", "embeddings")
+	results, err := s.query(ctx, query, args)
+	endQuerySpan(span, err)
+	return results, err
+}
+
+// query runs a search query and reads its results
+func (s *Searcher) query(ctx context.Context, query string, args []interface{}) ([]ports.SearchResult, error) {
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute search query: %w", err)
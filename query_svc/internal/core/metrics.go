@@ -0,0 +1,53 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/speakr/query_svc/internal/ports"
+)
+
+// Search metrics, served on /metrics
+var (
+	searchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "speakr",
+		Subsystem: "query",
+		Name:      "search_duration_seconds",
+		Help:      "Time taken to answer a search, embedding included, by outcome.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"outcome"})
+
+	searchResults = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "speakr",
+		Subsystem: "query",
+		Name:      "search_results",
+		Help:      "Results returned by successful searches.",
+		Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+	})
+)
+
+// observeSearch records the duration and outcome of a search, and the results it found
+func observeSearch(started time.Time, results []ports.SearchResult, err error) {
+	searchDuration.WithLabelValues(searchOutcome(err)).Observe(time.Since(started).Seconds())
+	if err == nil {
+		searchResults.Observe(float64(len(results)))
+	}
+}
+
+// searchOutcome returns the outcome label of a search
+func searchOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrInvalidQuery):
+		return "invalid_query"
+	case errors.Is(err, ErrEmbeddingFailed):
+		return "embedding_failed"
+	case errors.Is(err, ErrSearchFailed):
+		return "search_failed"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/speakr/query_svc/internal/ports"
+)
+
+// sampleCount returns how many values a histogram has observed
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := observer.(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("Failed to read histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestService_Search_RecordsMetrics(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewService(
+		&mockEmbeddingGenerator{embedding: []float32{0.1, 0.2}},
+		&mockVectorSearcher{results: []ports.SearchResult{{RecordingID: "rec-1"}, {RecordingID: "rec-2"}}},
+		logger,
+	)
+
+	succeeded := sampleCount(t, searchDuration.WithLabelValues("ok"))
+	results := sampleCount(t, searchResults)
+
+	if _, err := service.Search(context.Background(), QueryRequest{QueryText: "standup notes"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if got := sampleCount(t, searchDuration.WithLabelValues("ok")); got != succeeded+1 {
+		t.Errorf("Expected one more successful search, got %d", got-succeeded)
+	}
+	if got := sampleCount(t, searchResults); got != results+1 {
+		t.Errorf("Expected the result count to be observed, got %d samples", got-results)
+	}
+}
+
+func TestSearchOutcome(t *testing.T) {
+	testCases := []struct {
+		err  error
+		want string
+	}{
+		{nil, "ok"},
+		{ErrInvalidQuery, "invalid_query"},
+		{fmt.Errorf("%w: %v", ErrEmbeddingFailed, errors.New("API failure")), "embedding_failed"},
+		{ErrSearchFailed, "search_failed"},
+		{errors.New("boom"), "error"},
+	}
+
+	for _, tc := range testCases {
+		if got := searchOutcome(tc.err); got != tc.want {
+			t.Errorf("For error %v, expected %q, got %q", tc.err, tc.want, got)
+		}
+	}
+}
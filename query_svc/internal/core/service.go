@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/speakr/query_svc/internal/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // QueryRequest represents an incoming query request
@@ -33,8 +36,17 @@ func NewService(embeddingGenerator ports.EmbeddingGenerator, vectorSearcher port
 }
 
 // Search performs a semantic search on the vector database
-func (s *Service) Search(ctx context.Context, req QueryRequest) ([]ports.SearchResult, error) {
-	correlationID := ctx.Value("correlation_id")
+func (s *Service) Search(ctx context.Context, req QueryRequest) (results []ports.SearchResult, err error) {
+	started := time.Now()
+	defer func() { observeSearch(started, results, err) }()
+
+	ctx, span := tracer.Start(ctx, "Service.Search", trace.WithAttributes(
+		attribute.Int("query.filter_tags", len(req.FilterTags)),
+		attribute.Int("query.limit", req.Limit),
+	))
+	defer span.End()
+
+	correlationID := ports.CorrelationID(ctx)
 	
 	s.logger.Info("Starting search operation",
 		"correlation_id", correlationID,
@@ -57,7 +69,9 @@ func (s *Service) Search(ctx context.Context, req QueryRequest) ([]ports.SearchR
 	}
 
 	// Generate embedding for the query text
-	embedding, err := s.embeddingGenerator.GenerateEmbedding(ctx, req.QueryText)
+	embedCtx, embedSpan := tracer.Start(ctx, "EmbeddingGenerator.GenerateEmbedding")
+	embedding, err := s.embeddingGenerator.GenerateEmbedding(embedCtx, req.QueryText)
+	endSpan(embedSpan, err)
 	if err != nil {
 		s.logger.Error("Failed to generate embedding",
 			"correlation_id", correlationID,
@@ -78,7 +92,9 @@ func (s *Service) Search(ctx context.Context, req QueryRequest) ([]ports.SearchR
 		Limit:          req.Limit,
 	}
 
-	results, err := s.vectorSearcher.Search(ctx, searchReq)
+	searchCtx, searchSpan := tracer.Start(ctx, "VectorSearcher.Search")
+	results, err = s.vectorSearcher.Search(searchCtx, searchReq)
+	endSpan(searchSpan, err)
 	if err != nil {
 		s.logger.Error("Failed to perform vector search",
 			"correlation_id", correlationID,
@@ -87,6 +103,7 @@ func (s *Service) Search(ctx context.Context, req QueryRequest) ([]ports.SearchR
 		return nil, fmt.Errorf("%w: %v", ErrSearchFailed, err)
 	}
 
+	span.SetAttributes(attribute.Int("query.results", len(results)))
 	s.logger.Info("Search completed successfully",
 		"correlation_id", correlationID,
 		"results_count", len(results),
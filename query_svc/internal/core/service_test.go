@@ -55,7 +55,7 @@ func TestService_Search_Success(t *testing.T) {
 	service := NewService(embeddingGen, vectorSearcher, logger)
 
 	// Test successful search
-	ctx := context.WithValue(context.Background(), "correlation_id", "test-123")
+	ctx := ports.WithCorrelationID(context.Background(), "test-123")
 	req := QueryRequest{
 		QueryText:  "test query",
 		FilterTags: []string{"tag1"},
@@ -0,0 +1,20 @@
+package core
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records how a search splits between embedding the query and searching the
+// vector database. Spans are dropped until the service installs a tracer provider.
+var tracer = otel.Tracer("github.com/speakr/query_svc/internal/core")
+
+// endSpan ends a span, recording the error its step failed with, if any
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
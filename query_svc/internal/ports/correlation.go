@@ -0,0 +1,20 @@
+package ports
+
+import "context"
+
+// contextKey keeps the correlation ID from colliding with context values of other packages
+type contextKey int
+
+const correlationIDKey contextKey = iota
+
+// WithCorrelationID returns a context carrying the ID shared by every log line of one
+// request, and by the messages of the other services that handled it
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID of a context, or "" if it has none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
@@ -2,22 +2,32 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"speakr/transcriber/internal/adapters/ffmpeg_adapter"
+	"speakr/transcriber/internal/adapters/memory_adapter"
 	"speakr/transcriber/internal/adapters/minio_adapter"
 	"speakr/transcriber/internal/adapters/nats_adapter"
 	"speakr/transcriber/internal/adapters/openai_adapter"
+	"speakr/transcriber/internal/adapters/otel_adapter"
+	"speakr/transcriber/internal/adapters/postgres_adapter"
+	"speakr/transcriber/internal/adapters/router_adapter"
+	"speakr/transcriber/internal/adapters/whisper_adapter"
 	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -39,6 +49,15 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Set up tracing; spans are exported only when TRACING_EXPORTER names an exporter
+	shutdownTracing, err := otel_adapter.SetupTracing(ctx, "speakr-transcriber", logger,
+		otel_adapter.WithExporter(config.TracingExporter),
+	)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
 	// Connect to NATS
 	natsConn, err := nats.Connect(config.NatsURL)
 	if err != nil {
@@ -49,17 +68,22 @@ func main() {
 
 	logger.Info("Connected to NATS", "url", config.NatsURL)
 
-	// Create real adapters
-	audioRecorder, err := ffmpeg_adapter.NewRecorder(logger,
-		ffmpeg_adapter.WithTempDir("/tmp/speakr"),
-		ffmpeg_adapter.WithInputDevice(config.AudioInputDevice),
-		ffmpeg_adapter.WithOutputDevice(config.AudioOutputDevice),
-		ffmpeg_adapter.WithSampleRate(44100),
-		ffmpeg_adapter.WithChannels(1),
-	)
-	if err != nil {
-		logger.Error("Failed to create audio recorder", "error", err)
-		os.Exit(1)
+	// Create real adapters. Only the recording role needs a microphone.
+	var audioRecorder ports.AudioRecorder
+	if config.hasRole(nats_adapter.RoleRecording) {
+		recorder, err := ffmpeg_adapter.NewRecorder(logger,
+			ffmpeg_adapter.WithTempDir("/tmp/speakr"),
+			ffmpeg_adapter.WithInputDevice(config.AudioInputDevice),
+			ffmpeg_adapter.WithOutputDevice(config.AudioOutputDevice),
+			ffmpeg_adapter.WithSampleRate(44100),
+			ffmpeg_adapter.WithChannels(1),
+			ffmpeg_adapter.WithSilenceThreshold(config.AutoStopSilenceThreshold),
+		)
+		if err != nil {
+			logger.Error("Failed to create audio recorder", "error", err)
+			os.Exit(1)
+		}
+		audioRecorder = recorder
 	}
 
 	objectStore, err := minio_adapter.NewStorage(logger,
@@ -73,32 +97,123 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create real OpenAI transcription service
-	transcriptionSvc, err := openai_adapter.NewTranscriber(logger,
-		openai_adapter.WithAPIKey(config.OpenAIAPIKey),
-		openai_adapter.WithBaseURL(config.OpenAIBaseURL),
-		openai_adapter.WithModel(config.OpenAITranscriptionModel),
-		openai_adapter.WithTimeout(30*time.Second),
-		openai_adapter.WithMaxRetries(3),
-	)
+	// Create real transcription services, routed in priority order. A host that only
+	// records uses them for live transcription, if a provider is configured.
+	var transcriptionSvc ports.TranscriptionService
+	if config.hasRole(nats_adapter.RoleTranscription) || config.hasProvider() {
+		transcriptionSvc, err = newTranscriptionRouter(config, logger)
+		if err != nil {
+			logger.Error("Failed to create transcription service", "error", err)
+			os.Exit(1)
+		}
+
+		transcriptionSvc, err = newChunkingTranscriber(config, transcriptionSvc, logger)
+		if err != nil {
+			logger.Error("Failed to create chunking transcriber", "error", err)
+			os.Exit(1)
+		}
+	} else {
+		logger.Info("No transcription provider configured, live transcription is unavailable")
+	}
+
+	sessionRegistry, err := newSessionRegistry(config, natsConn, logger)
+	if err != nil {
+		logger.Error("Failed to create session registry", "error", err)
+		os.Exit(1)
+	}
+
+	// In jetstream mode events are stored in a stream until their consumers take them
+	var eventPublisher ports.EventPublisher = nats_adapter.NewPublisher(natsConn, logger)
+	var js nats.JetStreamContext
+	if config.NatsMode == natsModeJetStream {
+		if js, err = natsConn.JetStream(); err != nil {
+			logger.Error("Failed to create JetStream context", "error", err)
+			os.Exit(1)
+		}
+		if err := nats_adapter.EnsureStreams(js, logger, config.JetStreamMaxAge); err != nil {
+			logger.Error("Failed to set up JetStream streams", "error", err)
+			os.Exit(1)
+		}
+		eventPublisher = nats_adapter.NewJetStreamPublisher(js, logger)
+	}
+
+	transcriptionCache, err := newTranscriptionCache(config, natsConn, logger)
 	if err != nil {
-		logger.Error("Failed to create transcription service", "error", err)
+		logger.Error("Failed to create transcription cache", "error", err)
 		os.Exit(1)
 	}
 
-	eventPublisher := nats_adapter.NewPublisher(natsConn, logger)
+	serviceOpts := []core.ServiceOption{core.WithLiveSegmentDuration(config.LiveSegmentDuration)}
+	if preprocessor := newPreprocessor(logger); preprocessor != nil {
+		serviceOpts = append(serviceOpts, core.WithPreprocessor(preprocessor, config.Preprocessing))
+	} else if len(config.Preprocessing) > 0 {
+		logger.Warn("FFmpeg not found, audio will not be pre-processed", "preprocessing", config.Preprocessing)
+	}
+	if config.RecorderID != "" {
+		serviceOpts = append(serviceOpts, core.WithRecorderID(config.RecorderID))
+	}
+	// Commands are published on the plain connection: the command stream takes them
+	// in jetstream mode without acknowledging the publish
+	if !config.hasRole(nats_adapter.RoleTranscription) {
+		serviceOpts = append(serviceOpts, core.WithRemoteTranscription(nats_adapter.NewPublisher(natsConn, logger)))
+	}
+	if transcriptionCache != nil {
+		serviceOpts = append(serviceOpts, core.WithTranscriptionCache(transcriptionCache, config.providerChain()))
+	}
+	if config.StorageFormat != "" {
+		if transcoder := newTranscoder(logger); transcoder != nil {
+			serviceOpts = append(serviceOpts, core.WithStorageTranscoding(transcoder, config.StorageFormat, config.StoragePreset))
+		} else {
+			logger.Warn("FFmpeg not found, audio will be stored as recorded", "storage_format", config.StorageFormat)
+		}
+	}
+	if config.ProfilesFile != "" {
+		profiles, err := loadProfiles(config.ProfilesFile)
+		if err != nil {
+			logger.Error("Failed to load transcription profiles", "file", config.ProfilesFile, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("Loaded transcription profiles", "file", config.ProfilesFile, "profiles", len(profiles.Profiles), "default", profiles.Default)
+		serviceOpts = append(serviceOpts, core.WithProfiles(profiles))
+	}
 
 	// Create core service
 	service := core.NewService(
 		audioRecorder,
 		transcriptionSvc,
 		objectStore,
+		sessionRegistry,
 		eventPublisher,
 		logger,
+		serviceOpts...,
 	)
 
 	// Create and start NATS subscriber
-	subscriber := nats_adapter.NewSubscriber(natsConn, service, logger)
+	subscriberOpts := []nats_adapter.SubscriberOption{
+		nats_adapter.WithRoles(config.Roles...),
+		nats_adapter.WithRecorderID(config.RecorderID),
+		nats_adapter.WithTranscriptionWorkers(config.TranscriptionWorkers),
+		nats_adapter.WithPendingTranscriptions(config.PendingTranscriptions),
+	}
+	if js != nil {
+		subscriberOpts = append(subscriberOpts,
+			nats_adapter.WithJetStream(js),
+			nats_adapter.WithMaxDeliver(config.JetStreamMaxDeliver),
+			nats_adapter.WithBackoff(config.JetStreamBackoff...),
+			nats_adapter.WithAckWait(config.JetStreamAckWait),
+		)
+	}
+	// Dead letters are published in either mode; listing and replaying them needs the
+	// SPEAKR_DLQ stream, created here in jetstream mode or by the NATS setup otherwise
+	dlqJS, err := natsConn.JetStream()
+	if err != nil {
+		logger.Error("Failed to create JetStream context", "error", err)
+		os.Exit(1)
+	}
+	subscriberOpts = append(subscriberOpts,
+		nats_adapter.WithDeadLetterQueue(nats_adapter.NewDeadLetterQueue(natsConn, dlqJS, "transcriber", logger)),
+	)
+	subscriber := nats_adapter.NewSubscriber(natsConn, service, logger, subscriberOpts...)
 	if err := subscriber.Subscribe(ctx); err != nil {
 		logger.Error("Failed to setup NATS subscriptions", "error", err)
 		os.Exit(1)
@@ -107,7 +222,7 @@ func main() {
 	// Start health check server
 	go startHealthServer(logger, config.HealthPort)
 
-	logger.Info("Transcriber Service started successfully")
+	logger.Info("Transcriber Service started successfully", "roles", config.Roles, "recorder_id", config.RecorderID, "nats_mode", config.NatsMode)
 
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
@@ -117,8 +232,15 @@ func main() {
 	logger.Info("Shutting down Transcriber Service")
 	cancel()
 
-	// Give some time for graceful shutdown
-	time.Sleep(2 * time.Second)
+	// Let running transcriptions finish before the connection closes
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+	if err := subscriber.Close(shutdownCtx); err != nil {
+		logger.Warn("Stopped before all transcriptions finished", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
 	logger.Info("Transcriber Service stopped")
 }
 
@@ -128,6 +250,7 @@ type Config struct {
 	OpenAIAPIKey            string
 	OpenAIBaseURL           string
 	OpenAITranscriptionModel string
+	OpenAIWordTimestamps    bool
 	MinioEndpoint           string
 	MinioAccessKey          string
 	MinioSecretKey          string
@@ -135,6 +258,51 @@ type Config struct {
 	HealthPort              string
 	AudioInputDevice        string
 	AudioOutputDevice       string
+	SessionRegistry         string
+	SessionTTL              time.Duration
+	ChunkingEnabled         bool
+	ChunkSplit              string
+	ChunkDuration           time.Duration
+	ChunkOverlap            time.Duration
+	ChunkConcurrency        int
+	ProfilesFile            string
+	LiveSegmentDuration     time.Duration
+	AutoStopSilenceThreshold string
+	Preprocessing           []string
+	StorageFormat           string
+	StoragePreset           string
+	ProviderName            string
+	FallbackProviders       []ProviderConfig
+	ProviderCooldown        time.Duration
+	ProviderFailureThreshold int
+	WhisperCppBinary        string
+	WhisperCppModel         string
+	WhisperCppThreads       int
+	TranscriptionCache      string
+	TranscriptionCacheTTL   time.Duration
+	DBHost                  string
+	DBPort                  int
+	DBUser                  string
+	DBPassword              string
+	DBName                  string
+	Roles                   []string
+	RecorderID              string
+	TranscriptionWorkers    int
+	PendingTranscriptions   int
+	NatsMode                string
+	JetStreamMaxDeliver     int
+	JetStreamBackoff        []time.Duration
+	JetStreamAckWait        time.Duration
+	JetStreamMaxAge         time.Duration
+	TracingExporter         string
+}
+
+// ProviderConfig configures an OpenAI-compatible transcription provider
+type ProviderConfig struct {
+	Name    string
+	BaseURL string
+	APIKey  string
+	Model   string
 }
 
 func loadConfig() (*Config, error) {
@@ -143,27 +311,394 @@ func loadConfig() (*Config, error) {
 		OpenAIAPIKey:            os.Getenv("OPENAI_API_KEY"),
 		OpenAIBaseURL:           getEnvOrDefault("OPENAI_BASE_URL", "https://api.openai.com/v1"),
 		OpenAITranscriptionModel: getEnvOrDefault("OPENAI_TRANSCRIPTION_MODEL", "whisper-1"),
+		OpenAIWordTimestamps:    getEnvOrDefault("OPENAI_WORD_TIMESTAMPS", "false") == "true",
 		MinioEndpoint:           getEnvOrDefault("MINIO_ENDPOINT", "localhost:9000"),
 		MinioAccessKey:          getEnvOrDefault("MINIO_ACCESS_KEY", "minioadmin"),
 		MinioSecretKey:          getEnvOrDefault("MINIO_SECRET_KEY", "minioadmin"),
 		MinioBucketName:         getEnvOrDefault("MINIO_BUCKET_NAME", "speakr-audio"),
 		HealthPort:              getEnvOrDefault("HEALTH_PORT", "8080"),
+		TracingExporter:         strings.ToLower(getEnvOrDefault("TRACING_EXPORTER", "none")),
 		AudioInputDevice:        getEnvOrDefault("AUDIO_INPUT_DEVICE", "default"),
 		AudioOutputDevice:       getEnvOrDefault("AUDIO_OUTPUT_DEVICE", "default"),
+		SessionRegistry:         getEnvOrDefault("SESSION_REGISTRY", "memory"),
+		ChunkingEnabled:         getEnvOrDefault("TRANSCRIPTION_CHUNKING", "true") == "true",
+		ChunkSplit:              getEnvOrDefault("TRANSCRIPTION_CHUNK_SPLIT", "silence"),
+		ProfilesFile:            os.Getenv("TRANSCRIPTION_PROFILES_FILE"),
+		AutoStopSilenceThreshold: getEnvOrDefault("AUTO_STOP_SILENCE_THRESHOLD", "-30dB"),
+		StorageFormat:           strings.ToLower(os.Getenv("STORAGE_FORMAT")),
+		StoragePreset:           getEnvOrDefault("STORAGE_PRESET", ports.RecordingPresetSpeech),
+		ProviderName:            getEnvOrDefault("TRANSCRIPTION_PROVIDER_NAME", "openai"),
+		WhisperCppBinary:        getEnvOrDefault("WHISPER_CPP_BINARY", "whisper-cli"),
+		WhisperCppModel:         os.Getenv("WHISPER_CPP_MODEL"),
+		TranscriptionCache:      getEnvOrDefault("TRANSCRIPTION_CACHE", "off"),
+		DBHost:                  getEnvOrDefault("DB_HOST", "localhost"),
+		DBUser:                  getEnvOrDefault("DB_USER", "postgres"),
+		DBPassword:              getEnvOrDefault("DB_PASSWORD", "postgres"),
+		DBName:                  getEnvOrDefault("DB_NAME", "speakr"),
+		RecorderID:              os.Getenv("RECORDER_ID"),
+		NatsMode:                getEnvOrDefault("NATS_MODE", natsModeCore),
 	}
 
-	if config.OpenAIAPIKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+	sessionTTL, err := time.ParseDuration(getEnvOrDefault("SESSION_TTL", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SESSION_TTL: %w", err)
 	}
+	config.SessionTTL = sessionTTL
 
-	// Validate base URL format
-	if err := validateBaseURL(config.OpenAIBaseURL); err != nil {
-		return nil, fmt.Errorf("invalid OPENAI_BASE_URL: %w", err)
+	for _, role := range strings.Split(getEnvOrDefault("SERVICE_ROLES", "recording,transcription"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			config.Roles = append(config.Roles, role)
+		}
+	}
+
+	if config.ChunkSplit != "silence" && config.ChunkSplit != "fixed" {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_CHUNK_SPLIT %q (expected silence or fixed)", config.ChunkSplit)
+	}
+
+	if config.ChunkDuration, err = time.ParseDuration(getEnvOrDefault("TRANSCRIPTION_CHUNK_DURATION", "10m")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_CHUNK_DURATION: %w", err)
+	}
+
+	if config.ChunkOverlap, err = time.ParseDuration(getEnvOrDefault("TRANSCRIPTION_CHUNK_OVERLAP", "2s")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_CHUNK_OVERLAP: %w", err)
+	}
+
+	if config.LiveSegmentDuration, err = time.ParseDuration(getEnvOrDefault("LIVE_SEGMENT_DURATION", "15s")); err != nil || config.LiveSegmentDuration <= 0 {
+		return nil, fmt.Errorf("invalid LIVE_SEGMENT_DURATION %q (expected a positive duration)", os.Getenv("LIVE_SEGMENT_DURATION"))
+	}
+
+	if config.Preprocessing, err = core.ParsePreprocessing(os.Getenv("AUDIO_PREPROCESSING")); err != nil {
+		return nil, fmt.Errorf("invalid AUDIO_PREPROCESSING: %w", err)
+	}
+
+	if config.StorageFormat != "" {
+		if err := ffmpeg_adapter.ValidateEncoding(config.StorageFormat, config.StoragePreset); err != nil {
+			return nil, fmt.Errorf("invalid STORAGE_FORMAT %q or STORAGE_PRESET %q: %w", config.StorageFormat, config.StoragePreset, err)
+		}
+	}
+
+	// Hosts that only record hand transcriptions to the workers, so the fallback
+	// providers are only read where they are used
+	fallbackProviders := os.Getenv("TRANSCRIPTION_FALLBACK_PROVIDERS")
+	if !config.hasRole(nats_adapter.RoleTranscription) {
+		fallbackProviders = ""
+	}
+	for _, name := range strings.Split(fallbackProviders, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if name == whisperCppProvider {
+			config.FallbackProviders = append(config.FallbackProviders, ProviderConfig{Name: name})
+			continue
+		}
+		provider, err := loadProviderConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		config.FallbackProviders = append(config.FallbackProviders, provider)
+	}
+
+	if config.ProviderCooldown, err = time.ParseDuration(getEnvOrDefault("TRANSCRIPTION_PROVIDER_COOLDOWN", "1m")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_PROVIDER_COOLDOWN: %w", err)
+	}
+
+	if config.ProviderFailureThreshold, err = strconv.Atoi(getEnvOrDefault("TRANSCRIPTION_PROVIDER_FAILURE_THRESHOLD", "1")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_PROVIDER_FAILURE_THRESHOLD: %w", err)
+	}
+
+	if config.WhisperCppThreads, err = strconv.Atoi(getEnvOrDefault("WHISPER_CPP_THREADS", "0")); err != nil {
+		return nil, fmt.Errorf("invalid WHISPER_CPP_THREADS: %w", err)
+	}
+
+	if config.hasRole(nats_adapter.RoleTranscription) && config.usesProvider(whisperCppProvider) && config.WhisperCppModel == "" {
+		return nil, fmt.Errorf("WHISPER_CPP_MODEL environment variable is required for provider %s", whisperCppProvider)
+	}
+
+	if config.TranscriptionCacheTTL, err = time.ParseDuration(getEnvOrDefault("TRANSCRIPTION_CACHE_TTL", "720h")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_CACHE_TTL: %w", err)
+	}
+
+	if config.DBPort, err = strconv.Atoi(getEnvOrDefault("DB_PORT", "5432")); err != nil {
+		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
+	}
+
+	if config.ChunkConcurrency, err = strconv.Atoi(getEnvOrDefault("TRANSCRIPTION_CHUNK_CONCURRENCY", "4")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_CHUNK_CONCURRENCY: %w", err)
+	}
+
+	if config.TranscriptionWorkers, err = strconv.Atoi(getEnvOrDefault("TRANSCRIPTION_WORKERS", "2")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_WORKERS: %w", err)
+	}
+
+	if config.PendingTranscriptions, err = strconv.Atoi(getEnvOrDefault("TRANSCRIPTION_PENDING_LIMIT", "16")); err != nil {
+		return nil, fmt.Errorf("invalid TRANSCRIPTION_PENDING_LIMIT: %w", err)
+	}
+
+	if config.NatsMode != natsModeCore && config.NatsMode != natsModeJetStream {
+		return nil, fmt.Errorf("invalid NATS_MODE %q (expected %s or %s)", config.NatsMode, natsModeCore, natsModeJetStream)
+	}
+
+	if config.JetStreamMaxDeliver, err = strconv.Atoi(getEnvOrDefault("JETSTREAM_MAX_DELIVER", "5")); err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_MAX_DELIVER: %w", err)
+	}
+
+	for _, step := range strings.Split(getEnvOrDefault("JETSTREAM_BACKOFF", "10s,1m,5m"), ",") {
+		delay, err := time.ParseDuration(strings.TrimSpace(step))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JETSTREAM_BACKOFF: %w", err)
+		}
+		config.JetStreamBackoff = append(config.JetStreamBackoff, delay)
+	}
+
+	if config.JetStreamAckWait, err = time.ParseDuration(getEnvOrDefault("JETSTREAM_ACK_WAIT", "30s")); err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_ACK_WAIT: %w", err)
+	}
+
+	if config.JetStreamMaxAge, err = time.ParseDuration(getEnvOrDefault("JETSTREAM_MAX_AGE", "168h")); err != nil {
+		return nil, fmt.Errorf("invalid JETSTREAM_MAX_AGE: %w", err)
+	}
+
+	// A local primary provider needs no OpenAI credentials, and a host that only
+	// records needs a provider only for live transcription
+	if config.hasRole(nats_adapter.RoleTranscription) && config.ProviderName != whisperCppProvider {
+		if config.OpenAIAPIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY environment variable is required")
+		}
+
+		// Validate base URL format
+		if err := validateBaseURL(config.OpenAIBaseURL); err != nil {
+			return nil, fmt.Errorf("invalid OPENAI_BASE_URL: %w", err)
+		}
 	}
 
 	return config, nil
 }
 
+// whisperCppProvider is the provider name that runs whisper.cpp locally, configured by
+// the WHISPER_CPP_* variables, rather than an OpenAI-compatible API
+const whisperCppProvider = "whisper_cpp"
+
+// NATS modes: plain core NATS, or JetStream streams that keep commands and events
+// while their consumers are down
+const (
+	natsModeCore      = "core"
+	natsModeJetStream = "jetstream"
+)
+
+// hasRole reports whether the service takes on a role
+func (c *Config) hasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// hasProvider reports whether the primary provider is configured: whisper.cpp with
+// its model, or an OpenAI-compatible API with its key
+func (c *Config) hasProvider() bool {
+	if c.ProviderName == whisperCppProvider {
+		return c.WhisperCppModel != ""
+	}
+	return c.OpenAIAPIKey != ""
+}
+
+// usesProvider reports whether a provider is the primary or one of the fallbacks
+func (c *Config) usesProvider(name string) bool {
+	if c.ProviderName == name {
+		return true
+	}
+	for _, provider := range c.FallbackProviders {
+		if provider.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadProviderConfig reads a fallback provider from the environment variables
+// prefixed with its upper-cased name, e.g. GROQ_BASE_URL for "groq"
+func loadProviderConfig(name string) (ProviderConfig, error) {
+	prefix := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	provider := ProviderConfig{
+		Name:    name,
+		BaseURL: os.Getenv(prefix + "_BASE_URL"),
+		APIKey:  os.Getenv(prefix + "_API_KEY"),
+		Model:   getEnvOrDefault(prefix+"_TRANSCRIPTION_MODEL", "whisper-1"),
+	}
+
+	if err := validateBaseURL(provider.BaseURL); err != nil {
+		return ProviderConfig{}, fmt.Errorf("invalid %s_BASE_URL for provider %s: %w", prefix, name, err)
+	}
+	if provider.APIKey == "" {
+		return ProviderConfig{}, fmt.Errorf("%s_API_KEY environment variable is required for provider %s", prefix, name)
+	}
+
+	return provider, nil
+}
+
+// providers returns the primary provider followed by its fallbacks
+func (c *Config) providers() []ProviderConfig {
+	primary := ProviderConfig{
+		Name:    c.ProviderName,
+		BaseURL: c.OpenAIBaseURL,
+		APIKey:  c.OpenAIAPIKey,
+		Model:   c.OpenAITranscriptionModel,
+	}
+	return append([]ProviderConfig{primary}, c.FallbackProviders...)
+}
+
+// providerChain describes the providers in order with the model each one uses,
+// e.g. "openai:whisper-1,groq:whisper-large-v3"
+func (c *Config) providerChain() string {
+	var chain []string
+	for _, provider := range c.providers() {
+		model := provider.Model
+		if provider.Name == whisperCppProvider {
+			model = c.WhisperCppModel
+		}
+		chain = append(chain, provider.Name+":"+model)
+	}
+	return strings.Join(chain, ",")
+}
+
+// newTranscriptionRouter creates the configured provider followed by its fallbacks,
+// behind a router that fails over between them
+func newTranscriptionRouter(config *Config, logger *slog.Logger) (ports.TranscriptionService, error) {
+	var providers []router_adapter.Provider
+	for _, provider := range config.providers() {
+		if provider.Name == whisperCppProvider {
+			transcriber, err := whisper_adapter.NewTranscriber(logger.With("provider", provider.Name),
+				whisper_adapter.WithBinary(config.WhisperCppBinary),
+				whisper_adapter.WithModelPath(config.WhisperCppModel),
+				whisper_adapter.WithThreads(config.WhisperCppThreads),
+				whisper_adapter.WithTempDir("/tmp/speakr"),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create provider %s: %w", provider.Name, err)
+			}
+			providers = append(providers, router_adapter.Provider{Name: provider.Name, Service: transcriber})
+			continue
+		}
+
+		transcriber, err := openai_adapter.NewTranscriber(logger.With("provider", provider.Name),
+			openai_adapter.WithAPIKey(provider.APIKey),
+			openai_adapter.WithBaseURL(provider.BaseURL),
+			openai_adapter.WithModel(provider.Model),
+			openai_adapter.WithTimeout(30*time.Second),
+			openai_adapter.WithMaxRetries(3),
+			openai_adapter.WithWordTimestamps(config.OpenAIWordTimestamps),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %s: %w", provider.Name, err)
+		}
+		providers = append(providers, router_adapter.Provider{Name: provider.Name, Service: transcriber})
+	}
+
+	return router_adapter.NewRouter(providers, logger,
+		router_adapter.WithCooldown(config.ProviderCooldown),
+		router_adapter.WithFailureThreshold(config.ProviderFailureThreshold),
+	)
+}
+
+// newSessionRegistry creates the configured recording session registry
+func newSessionRegistry(config *Config, natsConn *nats.Conn, logger *slog.Logger) (ports.SessionRegistry, error) {
+	switch config.SessionRegistry {
+	case "memory":
+		return memory_adapter.NewSessionRegistry(), nil
+	case "nats":
+		return nats_adapter.NewSessionRegistry(natsConn, logger,
+			nats_adapter.WithSessionTTL(config.SessionTTL),
+		)
+	default:
+		return nil, fmt.Errorf("unknown SESSION_REGISTRY %q (expected memory or nats)", config.SessionRegistry)
+	}
+}
+
+// newTranscriptionCache creates the configured transcription cache, or returns nil when caching is off
+func newTranscriptionCache(config *Config, natsConn *nats.Conn, logger *slog.Logger) (ports.TranscriptionCache, error) {
+	switch config.TranscriptionCache {
+	case "off":
+		return nil, nil
+	case "memory":
+		return memory_adapter.NewTranscriptionCache(
+			memory_adapter.WithCacheTTL(config.TranscriptionCacheTTL),
+		), nil
+	case "nats":
+		return nats_adapter.NewTranscriptionCache(natsConn, logger,
+			nats_adapter.WithCacheTTL(config.TranscriptionCacheTTL),
+		)
+	case "postgres":
+		return postgres_adapter.NewTranscriptionCache(logger,
+			postgres_adapter.WithHost(config.DBHost),
+			postgres_adapter.WithPort(config.DBPort),
+			postgres_adapter.WithCredentials(config.DBUser, config.DBPassword),
+			postgres_adapter.WithDatabase(config.DBName),
+			postgres_adapter.WithCacheTTL(config.TranscriptionCacheTTL),
+		)
+	default:
+		return nil, fmt.Errorf("unknown TRANSCRIPTION_CACHE %q (expected off, memory, nats or postgres)", config.TranscriptionCache)
+	}
+}
+
+// newChunkingTranscriber wraps the transcription service so audio over the provider's
+// size limit is transcribed in chunks. Without ffmpeg, the service is used as is.
+func newChunkingTranscriber(config *Config, transcriptionSvc ports.TranscriptionService, logger *slog.Logger) (ports.TranscriptionService, error) {
+	if !config.ChunkingEnabled {
+		return transcriptionSvc, nil
+	}
+
+	chunker, err := ffmpeg_adapter.NewChunkingTranscriber(transcriptionSvc, logger,
+		ffmpeg_adapter.WithChunkTempDir("/tmp/speakr"),
+		ffmpeg_adapter.WithSilenceSplitting(config.ChunkSplit == "silence"),
+		ffmpeg_adapter.WithChunkDuration(config.ChunkDuration),
+		ffmpeg_adapter.WithChunkOverlap(config.ChunkOverlap),
+		ffmpeg_adapter.WithChunkConcurrency(config.ChunkConcurrency),
+	)
+	if errors.Is(err, ffmpeg_adapter.ErrFFmpegNotFound) {
+		logger.Warn("FFmpeg not found, audio over the provider size limit will not be chunked")
+		return transcriptionSvc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return chunker, nil
+}
+
+// newPreprocessor creates the ffmpeg audio pre-processor, or returns nil without ffmpeg
+func newPreprocessor(logger *slog.Logger) ports.AudioPreprocessor {
+	preprocessor, err := ffmpeg_adapter.NewPreprocessor(logger,
+		ffmpeg_adapter.WithPreprocessTempDir("/tmp/speakr"),
+	)
+	if err != nil {
+		if !errors.Is(err, ffmpeg_adapter.ErrFFmpegNotFound) {
+			logger.Warn("Failed to create audio pre-processor", "error", err)
+		}
+		return nil
+	}
+	return preprocessor
+}
+
+// newTranscoder creates the ffmpeg audio transcoder, or returns nil without ffmpeg
+func newTranscoder(logger *slog.Logger) ports.AudioTranscoder {
+	transcoder, err := ffmpeg_adapter.NewTranscoder(logger,
+		ffmpeg_adapter.WithTranscodeTempDir("/tmp/speakr"),
+	)
+	if err != nil {
+		if !errors.Is(err, ffmpeg_adapter.ErrFFmpegNotFound) {
+			logger.Warn("Failed to create audio transcoder", "error", err)
+		}
+		return nil
+	}
+	return transcoder
+}
+
+// loadProfiles reads the transcription profiles from a JSON file
+func loadProfiles(path string) (*core.Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+	return core.ParseProfiles(data)
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -178,6 +713,8 @@ func startHealthServer(logger *slog.Logger, port string) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"healthy","service":"transcriber"}`))
 	})
+	// Prometheus metrics of the service and the Go runtime
+	mux.Handle("/metrics", promhttp.Handler())
 
 	server := &http.Server{
 		Addr:    ":" + port,
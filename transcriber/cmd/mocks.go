@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 // Mock implementations for development and testing
@@ -15,7 +17,7 @@ type mockAudioRecorder struct {
 	logger *slog.Logger
 }
 
-func (m *mockAudioRecorder) StartRecording(ctx context.Context, recordingID string, format string) error {
+func (m *mockAudioRecorder) StartRecording(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
 	m.logger.Info("Mock: Starting recording", "recording_id", recordingID, "format", format)
 	return nil
 }
@@ -32,27 +34,50 @@ func (m *mockAudioRecorder) CancelRecording(ctx context.Context, recordingID str
 	return nil
 }
 
+func (m *mockAudioRecorder) PauseRecording(ctx context.Context, recordingID string) error {
+	m.logger.Info("Mock: Pausing recording", "recording_id", recordingID)
+	return nil
+}
+
+func (m *mockAudioRecorder) ResumeRecording(ctx context.Context, recordingID string) error {
+	m.logger.Info("Mock: Resuming recording", "recording_id", recordingID)
+	return nil
+}
+
+func (m *mockAudioRecorder) ListRecordings(ctx context.Context) ([]ports.RecordingStatus, error) {
+	m.logger.Info("Mock: Listing recordings")
+	return []ports.RecordingStatus{}, nil
+}
+
 type mockTranscriptionService struct {
 	logger *slog.Logger
 }
 
-func (m *mockTranscriptionService) TranscribeAudio(ctx context.Context, audioData io.Reader, format string) (string, error) {
-	m.logger.Info("Mock: Transcribing audio", "format", format)
+func (m *mockTranscriptionService) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	m.logger.Info("Mock: Transcribing audio", "format", format, "task", opts.Task, "language", opts.Language)
 	// Simulate some processing time
 	time.Sleep(100 * time.Millisecond)
-	return "This is a mock transcription of the audio content.", nil
+	text := "This is a mock transcription of the audio content."
+	return &ports.TranscriptionResult{
+		Text:     text,
+		Language: "english",
+		Duration: 3,
+		Segments: []ports.TranscriptSegment{{ID: 0, Start: 0, End: 3, Text: text}},
+	}, nil
 }
 
 type mockObjectStore struct {
-	logger *slog.Logger
-	store  map[string]string
+	logger  *slog.Logger
+	store   map[string]string
+	formats map[string]string
 }
 
-func (m *mockObjectStore) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader) (string, error) {
-	m.logger.Info("Mock: Storing audio", "recording_id", recordingID)
+func (m *mockObjectStore) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+	m.logger.Info("Mock: Storing audio", "recording_id", recordingID, "format", format)
 	
 	if m.store == nil {
 		m.store = make(map[string]string)
+		m.formats = make(map[string]string)
 	}
 	
 	// Read the audio data
@@ -63,22 +88,33 @@ func (m *mockObjectStore) StoreAudio(ctx context.Context, recordingID string, au
 	
 	// Store in mock storage
 	m.store[recordingID] = string(data)
+	m.formats[recordingID] = format
 	
-	filePath := fmt.Sprintf("/mock/storage/%s.wav", recordingID)
+	filePath := fmt.Sprintf("/mock/storage/%s.%s", recordingID, format)
 	return filePath, nil
 }
 
-func (m *mockObjectStore) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, error) {
+func (m *mockObjectStore) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, string, error) {
 	m.logger.Info("Mock: Retrieving audio", "recording_id", recordingID)
 	
 	if m.store == nil {
-		return nil, fmt.Errorf("audio file not found: %s", recordingID)
+		return nil, "", fmt.Errorf("audio file not found: %s", recordingID)
 	}
 	
 	data, exists := m.store[recordingID]
 	if !exists {
-		return nil, fmt.Errorf("audio file not found: %s", recordingID)
+		return nil, "", fmt.Errorf("audio file not found: %s", recordingID)
+	}
+	
+	return strings.NewReader(data), m.formats[recordingID], nil
+}
+
+func (m *mockObjectStore) StoreSubtitle(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error) {
+	m.logger.Info("Mock: Storing subtitle", "recording_id", recordingID, "format", format)
+	
+	if _, err := io.Copy(io.Discard, subtitleData); err != nil {
+		return "", fmt.Errorf("failed to read subtitle data: %w", err)
 	}
 	
-	return strings.NewReader(data), nil
+	return fmt.Sprintf("/mock/storage/%s.%s", recordingID, format), nil
 }
\ No newline at end of file
@@ -39,7 +39,7 @@ func TestIntegration_RecordingAndStorage(t *testing.T) {
 	mockAudioData := strings.NewReader("mock audio data for integration test")
 
 	// Test storing audio
-	filePath, err := storage.StoreAudio(ctx, recordingID, mockAudioData)
+	filePath, err := storage.StoreAudio(ctx, recordingID, mockAudioData, "wav")
 	if err != nil {
 		t.Fatalf("Failed to store audio: %v", err)
 	}
@@ -47,7 +47,7 @@ func TestIntegration_RecordingAndStorage(t *testing.T) {
 	t.Logf("Audio stored successfully at: %s", filePath)
 
 	// Test retrieving audio
-	reader, err := storage.RetrieveAudio(ctx, recordingID)
+	reader, _, err := storage.RetrieveAudio(ctx, recordingID)
 	if err != nil {
 		t.Fatalf("Failed to retrieve audio: %v", err)
 	}
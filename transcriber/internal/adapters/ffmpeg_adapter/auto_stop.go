@@ -0,0 +1,54 @@
+package ffmpeg_adapter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// silenceWatcher receives the stderr of a recording's ffmpeg process and calls
+// onSilence once, when silencedetect reports a silence as long as its minimum duration
+type silenceWatcher struct {
+	onSilence func()
+	pending   []byte // start of a line not yet terminated
+	fired     bool
+}
+
+// Write scans complete stderr lines for the start of a silence. ffmpeg only reports
+// silence_start once the silence has lasted the filter's minimum duration.
+func (w *silenceWatcher) Write(p []byte) (int, error) {
+	if w.fired {
+		return len(p), nil
+	}
+
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			break
+		}
+
+		line := string(w.pending[:end])
+		w.pending = w.pending[end+1:]
+
+		if strings.Contains(line, "silence_start:") {
+			w.fired = true
+			w.pending = nil
+			w.onSilence()
+			break
+		}
+	}
+
+	return len(p), nil
+}
+
+// buildSilenceDetectArgs builds the ffmpeg output arguments that run silencedetect
+// over the input, discarding the audio, so silences are reported on stderr
+func buildSilenceDetectArgs(threshold string, minSilence time.Duration) []string {
+	return []string{
+		"-af", fmt.Sprintf("silencedetect=noise=%s:d=%s", threshold, formatSeconds(minSilence)),
+		"-f", "null",
+		"-",
+	}
+}
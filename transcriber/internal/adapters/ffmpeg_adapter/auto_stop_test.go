@@ -0,0 +1,51 @@
+package ffmpeg_adapter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSilenceWatcher_FiresOnceOnSilenceStart(t *testing.T) {
+	calls := 0
+	watcher := &silenceWatcher{onSilence: func() { calls++ }}
+
+	chunks := []string{
+		"size=     256kB time=00:00:03.00 bitrate= 705.6kbits/s speed=   1x\r",
+		"[silencedetect @ 0x55d5c8a3c0] silence_st",
+		"art: 4.02\n",
+		"[silencedetect @ 0x55d5c8a3c0] silence_end: 9.5 | silence_duration: 5.48\n",
+		"[silencedetect @ 0x55d5c8a3c0] silence_start: 12.1\n",
+	}
+
+	for i, chunk := range chunks {
+		if _, err := watcher.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if i < 2 && calls != 0 {
+			t.Fatalf("Expected no call before the silence_start line is complete, got %d after chunk %d", calls, i)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected onSilence to be called once, got %d", calls)
+	}
+}
+
+func TestSilenceWatcher_IgnoresOtherOutput(t *testing.T) {
+	watcher := &silenceWatcher{onSilence: func() { t.Error("Unexpected call to onSilence") }}
+
+	output := "Input #0, pulse, from 'default':\n  Duration: N/A, start: 0.000000, bitrate: 1411 kb/s\n"
+	if _, err := watcher.Write([]byte(output)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBuildSilenceDetectArgs(t *testing.T) {
+	args := buildSilenceDetectArgs("-35dB", 2500*time.Millisecond)
+
+	expected := "-af silencedetect=noise=-35dB:d=2.500 -f null -"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
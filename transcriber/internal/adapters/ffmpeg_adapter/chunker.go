@@ -0,0 +1,576 @@
+package ffmpeg_adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// chunkBytesPerSecond is the size of one second of a chunk, which is
+// extracted as 16 kHz mono 16-bit PCM
+const chunkBytesPerSecond = 16000 * 2
+
+// maxOverlapWords bounds how far back the stitcher looks for words repeated in an overlap
+const maxOverlapWords = 50
+
+// ChunkerConfig holds configuration for the chunking transcriber
+type ChunkerConfig struct {
+	TempDir          string
+	MaxChunkBytes    int64
+	ChunkDuration    time.Duration
+	Overlap          time.Duration
+	SplitOnSilence   bool
+	SilenceThreshold string
+	MinSilence       time.Duration
+	Concurrency      int
+}
+
+// ChunkerOption is a functional option for configuring the chunking transcriber
+type ChunkerOption func(*ChunkerConfig)
+
+// WithChunkTempDir sets the directory used for intermediate chunk files
+func WithChunkTempDir(dir string) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.TempDir = dir
+	}
+}
+
+// WithMaxChunkBytes sets the largest audio passed to the provider in one request
+func WithMaxChunkBytes(maxBytes int64) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.MaxChunkBytes = maxBytes
+	}
+}
+
+// WithChunkDuration sets the target length of each chunk
+func WithChunkDuration(duration time.Duration) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.ChunkDuration = duration
+	}
+}
+
+// WithChunkOverlap sets how much consecutive fixed-window chunks overlap
+func WithChunkOverlap(overlap time.Duration) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.Overlap = overlap
+	}
+}
+
+// WithSilenceSplitting enables or disables splitting at detected silences
+func WithSilenceSplitting(enabled bool) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.SplitOnSilence = enabled
+	}
+}
+
+// WithSilenceDetection sets the noise threshold (e.g. "-30dB") and minimum length of a silence
+func WithSilenceDetection(threshold string, minSilence time.Duration) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.SilenceThreshold = threshold
+		c.MinSilence = minSilence
+	}
+}
+
+// WithChunkConcurrency sets how many chunks are transcribed at once
+func WithChunkConcurrency(concurrency int) ChunkerOption {
+	return func(c *ChunkerConfig) {
+		c.Concurrency = concurrency
+	}
+}
+
+// ChunkingTranscriber implements the TranscriptionService port by splitting audio that is
+// too large for a single provider request into chunks, transcribing them concurrently with
+// the wrapped service and stitching the texts back together
+type ChunkingTranscriber struct {
+	next   ports.TranscriptionService
+	config ChunkerConfig
+	logger *slog.Logger
+}
+
+// audioChunk is a slice of the source audio to transcribe
+type audioChunk struct {
+	start            time.Duration
+	end              time.Duration
+	overlapsPrevious bool
+}
+
+// silence is a quiet stretch reported by ffmpeg's silencedetect filter
+type silence struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// NewChunkingTranscriber creates a chunking transcriber wrapping the given service
+func NewChunkingTranscriber(next ports.TranscriptionService, logger *slog.Logger, opts ...ChunkerOption) (*ChunkingTranscriber, error) {
+	config := ChunkerConfig{
+		TempDir:          "/tmp/speakr",
+		MaxChunkBytes:    24 * 1024 * 1024,
+		ChunkDuration:    10 * time.Minute,
+		Overlap:          2 * time.Second,
+		SplitOnSilence:   true,
+		SilenceThreshold: "-30dB",
+		MinSilence:       500 * time.Millisecond,
+		Concurrency:      4,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.Concurrency < 1 {
+		return nil, fmt.Errorf("chunk concurrency must be at least 1, got %d", config.Concurrency)
+	}
+
+	if config.Overlap < 0 || config.Overlap >= config.ChunkDuration {
+		return nil, fmt.Errorf("chunk overlap %v must be shorter than the chunk duration %v", config.Overlap, config.ChunkDuration)
+	}
+
+	// Chunks must fit the provider limit once extracted
+	if maxDuration := time.Duration(config.MaxChunkBytes/chunkBytesPerSecond) * time.Second; config.ChunkDuration > maxDuration {
+		logger.Warn("Chunk duration exceeds the chunk size limit, shortening it",
+			"chunk_duration", config.ChunkDuration,
+			"max_duration", maxDuration)
+		config.ChunkDuration = maxDuration
+	}
+
+	if err := os.MkdirAll(config.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, ErrFFmpegNotFound
+	}
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return nil, ErrFFmpegNotFound
+	}
+
+	return &ChunkingTranscriber{
+		next:   next,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// TranscribeAudio transcribes audio, splitting it first if it exceeds the chunk size limit
+func (c *ChunkingTranscriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	logger := c.logger.With("format", format)
+
+	// Audio within the limit goes straight to the wrapped service
+	head, err := io.ReadAll(io.LimitReader(audioData, c.config.MaxChunkBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio data: %w", err)
+	}
+	if int64(len(head)) <= c.config.MaxChunkBytes {
+		return c.next.TranscribeAudio(ctx, bytes.NewReader(head), format, opts)
+	}
+
+	workDir, err := os.MkdirTemp(c.config.TempDir, "chunks-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chunk directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	sourcePath := filepath.Join(workDir, "source."+format)
+	size, err := spoolAudio(sourcePath, io.MultiReader(bytes.NewReader(head), audioData))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := probeDuration(ctx, sourcePath)
+	if err != nil {
+		logger.Error("Failed to determine audio duration", "error", err)
+		return nil, err
+	}
+
+	var silences []silence
+	if c.config.SplitOnSilence {
+		silences, err = c.detectSilences(ctx, sourcePath)
+		if err != nil {
+			// Fixed windows still work without silence information
+			logger.Warn("Silence detection failed, splitting at fixed windows", "error", err)
+		}
+	}
+
+	chunks := planChunks(duration, c.config.ChunkDuration, c.config.Overlap, silences)
+	logger.Info("Transcribing audio in chunks",
+		"size", size,
+		"duration", duration,
+		"chunks", len(chunks),
+		"silences", len(silences),
+		"concurrency", c.config.Concurrency)
+
+	results, err := c.transcribeChunks(ctx, logger, workDir, sourcePath, chunks, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := stitchResults(chunks, results)
+	logger.Info("Chunked transcription completed",
+		"chunks", len(chunks),
+		"text_length", len(result.Text),
+		"segments", len(result.Segments))
+	return result, nil
+}
+
+// transcribeChunks transcribes every chunk with bounded parallelism and returns the results in order.
+// Every chunk gets the same options. A chunk without speech counts as empty, and only
+// audio without speech in any chunk fails with ports.ErrNoSpeech. Any other failure
+// cancels the remaining chunks.
+func (c *ChunkingTranscriber) transcribeChunks(ctx context.Context, logger *slog.Logger, workDir, sourcePath string, chunks []audioChunk, opts ports.TranscriptionOptions) ([]*ports.TranscriptionResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*ports.TranscriptionResult, len(chunks))
+	semaphore := make(chan struct{}, c.config.Concurrency)
+
+	var wg sync.WaitGroup
+	var once, noSpeechOnce sync.Once
+	var firstErr, noSpeechErr error
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk audioChunk) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			result, err := c.transcribeChunk(ctx, workDir, sourcePath, i, chunk, opts)
+			if errors.Is(err, ports.ErrNoSpeech) {
+				logger.Debug("Chunk has no speech", "chunk", i, "start", chunk.start, "end", chunk.end)
+				noSpeechOnce.Do(func() { noSpeechErr = err })
+				results[i] = &ports.TranscriptionResult{}
+				return
+			}
+			if err != nil {
+				logger.Error("Chunk transcription failed", "chunk", i, "start", chunk.start, "error", err)
+				once.Do(func() {
+					firstErr = fmt.Errorf("failed to transcribe chunk %d of %d: %w", i+1, len(chunks), err)
+					cancel()
+				})
+				return
+			}
+
+			logger.Debug("Chunk transcribed", "chunk", i, "start", chunk.start, "end", chunk.end)
+			results[i] = result
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if noSpeechErr != nil && !hasSpeech(results) {
+		return nil, noSpeechErr
+	}
+
+	return results, nil
+}
+
+// hasSpeech reports whether any chunk result holds a transcript
+func hasSpeech(results []*ports.TranscriptionResult) bool {
+	for _, result := range results {
+		if strings.TrimSpace(result.Text) != "" || len(result.Segments) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// transcribeChunk extracts one chunk from the source audio and transcribes it
+func (c *ChunkingTranscriber) transcribeChunk(ctx context.Context, workDir, sourcePath string, index int, chunk audioChunk, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	chunkPath := filepath.Join(workDir, fmt.Sprintf("chunk-%04d.wav", index))
+	defer os.Remove(chunkPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", buildChunkArgs(sourcePath, chunkPath, chunk)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to extract audio chunk: %w: %s", err, output)
+	}
+
+	file, err := os.Open(chunkPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio chunk: %w", err)
+	}
+	defer file.Close()
+
+	return c.next.TranscribeAudio(ctx, file, "wav", opts)
+}
+
+// detectSilences runs ffmpeg's silencedetect filter over the audio
+func (c *ChunkingTranscriber) detectSilences(ctx context.Context, sourcePath string) ([]silence, error) {
+	filter := fmt.Sprintf("silencedetect=noise=%s:d=%.3f", c.config.SilenceThreshold, c.config.MinSilence.Seconds())
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-nostats", "-i", sourcePath, "-af", filter, "-f", "null", "-")
+
+	// silencedetect reports on stderr
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect silences: %w", err)
+	}
+
+	return parseSilences(string(output)), nil
+}
+
+// spoolAudio writes the audio to a file so ffmpeg can seek in it
+func spoolAudio(path string, audioData io.Reader) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create audio file: %w", err)
+	}
+	defer file.Close()
+
+	size, err := io.Copy(file, audioData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	return size, nil
+}
+
+// probeDuration returns the length of an audio file using ffprobe
+func probeDuration(ctx context.Context, path string) (time.Duration, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe audio duration: %w", err)
+	}
+
+	return parseProbeDuration(string(output))
+}
+
+// parseProbeDuration parses the duration in seconds printed by ffprobe
+func parseProbeDuration(output string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid audio duration %q", strings.TrimSpace(output))
+	}
+
+	return secondsToDuration(seconds), nil
+}
+
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start: (-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end: ([0-9.]+)`)
+)
+
+// parseSilences extracts the silences reported by silencedetect. A silence still
+// open at the end of the audio has no end and is ignored.
+func parseSilences(output string) []silence {
+	var silences []silence
+	var start time.Duration
+	open := false
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := silenceStartPattern.FindStringSubmatch(line); match != nil {
+			seconds, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			start = secondsToDuration(max(seconds, 0))
+			open = true
+			continue
+		}
+
+		if match := silenceEndPattern.FindStringSubmatch(line); match != nil && open {
+			seconds, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			silences = append(silences, silence{start: start, end: secondsToDuration(seconds)})
+			open = false
+		}
+	}
+
+	return silences
+}
+
+// planChunks splits audio of the given length into chunks of at most one window.
+// Each chunk ends in the middle of the last silence in the second half of its
+// window; without one, it is cut at the window and the next chunk overlaps it.
+func planChunks(total, window, overlap time.Duration, silences []silence) []audioChunk {
+	var chunks []audioChunk
+	start := time.Duration(0)
+	overlapsPrevious := false
+
+	for start < total {
+		end := start + window
+		if end >= total {
+			chunks = append(chunks, audioChunk{start: start, end: total, overlapsPrevious: overlapsPrevious})
+			break
+		}
+
+		if cut, ok := silenceCut(silences, start+window/2, end); ok {
+			chunks = append(chunks, audioChunk{start: start, end: cut, overlapsPrevious: overlapsPrevious})
+			start = cut
+			overlapsPrevious = false
+			continue
+		}
+
+		chunks = append(chunks, audioChunk{start: start, end: end, overlapsPrevious: overlapsPrevious})
+		start = end - overlap
+		overlapsPrevious = overlap > 0
+	}
+
+	return chunks
+}
+
+// silenceCut returns the midpoint of the latest silence whose midpoint lies within [from, to]
+func silenceCut(silences []silence, from, to time.Duration) (time.Duration, bool) {
+	for i := len(silences) - 1; i >= 0; i-- {
+		midpoint := silences[i].start + (silences[i].end-silences[i].start)/2
+		if midpoint >= from && midpoint <= to {
+			return midpoint, true
+		}
+	}
+	return 0, false
+}
+
+// buildChunkArgs builds the FFmpeg arguments that extract a chunk as 16 kHz mono WAV
+func buildChunkArgs(sourcePath, chunkPath string, chunk audioChunk) []string {
+	return []string{
+		"-v", "error",
+		"-ss", formatSeconds(chunk.start),
+		"-t", formatSeconds(chunk.end - chunk.start),
+		"-i", sourcePath,
+		"-ac", "1",
+		"-ar", "16000",
+		"-acodec", "pcm_s16le",
+		"-y",
+		chunkPath,
+	}
+}
+
+// stitchResults combines chunk results into one. Timings are shifted to the position of
+// each chunk in the source audio, and segments and words that an overlapping chunk
+// repeats from the previous one are dropped. Segments are matched by time; words are
+// matched by text, the same way as in the stitched transcript.
+func stitchResults(chunks []audioChunk, results []*ports.TranscriptionResult) *ports.TranscriptionResult {
+	texts := make([]string, len(results))
+	stitched := &ports.TranscriptionResult{}
+
+	for i, result := range results {
+		texts[i] = result.Text
+		if stitched.Language == "" {
+			stitched.Language = result.Language
+		}
+
+		offset := chunks[i].start.Seconds()
+		cutoff := 0.0
+		if i > 0 && chunks[i].overlapsPrevious {
+			cutoff = chunks[i-1].end.Seconds()
+		}
+
+		for _, segment := range result.Segments {
+			segment.Start += offset
+			segment.End += offset
+			if segment.End <= cutoff {
+				continue
+			}
+			segment.ID = len(stitched.Segments)
+			stitched.Segments = append(stitched.Segments, segment)
+		}
+
+		words := result.Words
+		if i > 0 && chunks[i].overlapsPrevious {
+			words = words[overlapLength(wordTexts(stitched.Words), wordTexts(words)):]
+		}
+		for _, word := range words {
+			word.Start += offset
+			word.End += offset
+			stitched.Words = append(stitched.Words, word)
+		}
+	}
+
+	stitched.Text = stitchTranscripts(chunks, texts)
+	stitched.Provider = ports.JoinProviders(results)
+	if len(chunks) > 0 {
+		stitched.Duration = chunks[len(chunks)-1].end.Seconds()
+	}
+
+	return stitched
+}
+
+// stitchTranscripts joins chunk texts in order, dropping words that an overlapping
+// chunk repeats from the end of the previous one
+func stitchTranscripts(chunks []audioChunk, texts []string) string {
+	var words []string
+	for i, text := range texts {
+		next := strings.Fields(text)
+		if i > 0 && chunks[i].overlapsPrevious {
+			next = next[overlapLength(words, next):]
+		}
+		words = append(words, next...)
+	}
+	return strings.Join(words, " ")
+}
+
+// overlapLength returns how many leading words of next repeat the trailing words of previous
+func overlapLength(previous, next []string) int {
+	longest := min(len(previous), len(next), maxOverlapWords)
+	for n := longest; n > 0; n-- {
+		if wordsMatch(previous[len(previous)-n:], next[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// wordTexts returns the text of each timed word
+func wordTexts(words []ports.TranscriptWord) []string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.Word
+	}
+	return texts
+}
+
+// wordsMatch compares words ignoring case and surrounding punctuation
+func wordsMatch(a, b []string) bool {
+	for i := range a {
+		if normalizeWord(a[i]) != normalizeWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
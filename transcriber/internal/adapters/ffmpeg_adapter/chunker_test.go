@@ -0,0 +1,395 @@
+package ffmpeg_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+type stubTranscriber struct {
+	calls  int
+	format string
+	data   string
+	opts   ports.TranscriptionOptions
+}
+
+func (s *stubTranscriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	data, _ := io.ReadAll(audioData)
+	s.calls++
+	s.format = format
+	s.data = string(data)
+	s.opts = opts
+	return &ports.TranscriptionResult{Text: "transcript"}, nil
+}
+
+// silentChunkTranscriber reports no speech for the chunks whose call number is in silent
+type silentChunkTranscriber struct {
+	mu     sync.Mutex
+	calls  int
+	silent map[int]bool
+}
+
+func (s *silentChunkTranscriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.silent[s.calls] {
+		return nil, fmt.Errorf("%w: empty transcript", ports.ErrNoSpeech)
+	}
+	return &ports.TranscriptionResult{Text: "speech"}, nil
+}
+
+// writeSilentAudio creates a few seconds of audio for the chunk extraction tests
+func writeSilentAudio(t *testing.T) (string, []audioChunk) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("FFmpeg not found, skipping test")
+	}
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source.wav")
+	cmd := exec.Command("ffmpeg", "-f", "lavfi", "-i", "anullsrc=r=16000:cl=mono", "-t", "3", sourcePath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to create test audio: %v: %s", err, output)
+	}
+
+	chunks := []audioChunk{
+		{start: 0, end: time.Second},
+		{start: time.Second, end: 2 * time.Second},
+		{start: 2 * time.Second, end: 3 * time.Second},
+	}
+	return sourcePath, chunks
+}
+
+func TestChunkingTranscriber_SilentChunkIsEmpty(t *testing.T) {
+	sourcePath, chunks := writeSilentAudio(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	next := &silentChunkTranscriber{silent: map[int]bool{2: true}}
+	chunker := &ChunkingTranscriber{next: next, config: ChunkerConfig{Concurrency: 1}, logger: logger}
+
+	results, err := chunker.transcribeChunks(context.Background(), logger, filepath.Dir(sourcePath), sourcePath, chunks, ports.TranscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Expected a silent chunk not to fail the transcription, got %v", err)
+	}
+
+	if result := stitchResults(chunks, results); result.Text != "speech speech" {
+		t.Errorf("Expected the other chunks' text, got %q", result.Text)
+	}
+}
+
+func TestChunkingTranscriber_AllChunksSilent(t *testing.T) {
+	sourcePath, chunks := writeSilentAudio(t)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	next := &silentChunkTranscriber{silent: map[int]bool{1: true, 2: true, 3: true}}
+	chunker := &ChunkingTranscriber{next: next, config: ChunkerConfig{Concurrency: 2}, logger: logger}
+
+	_, err := chunker.transcribeChunks(context.Background(), logger, filepath.Dir(sourcePath), sourcePath, chunks, ports.TranscriptionOptions{})
+	if !errors.Is(err, ports.ErrNoSpeech) {
+		t.Errorf("Expected ErrNoSpeech when no chunk has speech, got %v", err)
+	}
+}
+
+func TestChunkingTranscriber_SmallAudioPassesThrough(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	next := &stubTranscriber{}
+	chunker := &ChunkingTranscriber{
+		next:   next,
+		config: ChunkerConfig{MaxChunkBytes: 16},
+		logger: logger,
+	}
+
+	opts := ports.TranscriptionOptions{Language: "pl", Task: ports.TaskTranslate}
+	result, err := chunker.TranscribeAudio(context.Background(), strings.NewReader("short audio"), "mp3", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Text != "transcript" || next.calls != 1 {
+		t.Errorf("Expected one pass-through call, got %d calls and text %q", next.calls, result.Text)
+	}
+
+	if next.data != "short audio" || next.format != "mp3" || next.opts != opts {
+		t.Errorf("Expected the original audio, format and options, got %q as %s with %+v", next.data, next.format, next.opts)
+	}
+}
+
+func TestNewChunkingTranscriber_InvalidConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	if _, err := NewChunkingTranscriber(&stubTranscriber{}, logger, WithChunkConcurrency(0)); err == nil {
+		t.Error("Expected error for zero concurrency")
+	}
+
+	if _, err := NewChunkingTranscriber(&stubTranscriber{}, logger,
+		WithChunkDuration(time.Minute),
+		WithChunkOverlap(time.Minute),
+	); err == nil {
+		t.Error("Expected error for an overlap as long as the chunk")
+	}
+}
+
+func TestPlanChunks_FixedWindows(t *testing.T) {
+	chunks := planChunks(25*time.Minute, 10*time.Minute, 2*time.Second, nil)
+
+	expected := []audioChunk{
+		{start: 0, end: 10 * time.Minute},
+		{start: 10*time.Minute - 2*time.Second, end: 20*time.Minute - 2*time.Second, overlapsPrevious: true},
+		{start: 20*time.Minute - 4*time.Second, end: 25 * time.Minute, overlapsPrevious: true},
+	}
+
+	if len(chunks) != len(expected) {
+		t.Fatalf("Expected %d chunks, got %d: %+v", len(expected), len(chunks), chunks)
+	}
+
+	for i := range expected {
+		if chunks[i] != expected[i] {
+			t.Errorf("Chunk %d: expected %+v, got %+v", i, expected[i], chunks[i])
+		}
+	}
+}
+
+func TestPlanChunks_SplitsAtSilence(t *testing.T) {
+	silences := []silence{
+		{start: 2 * time.Minute, end: 2*time.Minute + time.Second},     // too early in the window
+		{start: 8 * time.Minute, end: 8*time.Minute + 2*time.Second},   // cut at 8:01
+		{start: 30 * time.Minute, end: 30*time.Minute + 2*time.Second}, // outside the second window
+	}
+
+	chunks := planChunks(15*time.Minute, 10*time.Minute, 2*time.Second, silences)
+
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d: %+v", len(chunks), chunks)
+	}
+
+	cut := 8*time.Minute + time.Second
+	if chunks[0].end != cut || chunks[1].start != cut {
+		t.Errorf("Expected a cut at %v, got %+v", cut, chunks)
+	}
+
+	if chunks[1].overlapsPrevious {
+		t.Error("Expected chunks split at silence not to overlap")
+	}
+}
+
+func TestPlanChunks_ShortAudio(t *testing.T) {
+	chunks := planChunks(30*time.Second, 10*time.Minute, 2*time.Second, nil)
+
+	if len(chunks) != 1 || chunks[0].start != 0 || chunks[0].end != 30*time.Second {
+		t.Errorf("Expected a single chunk, got %+v", chunks)
+	}
+}
+
+func TestParseSilences(t *testing.T) {
+	output := `Input #0, wav, from 'source.wav':
+  Duration: 00:20:00.00, bitrate: 705 kb/s
+[silencedetect @ 0x55d5c8a3c0] silence_start: -0.0213
+[silencedetect @ 0x55d5c8a3c0] silence_end: 1.504 | silence_duration: 1.525
+[silencedetect @ 0x55d5c8a3c0] silence_start: 601.25
+[silencedetect @ 0x55d5c8a3c0] silence_end: 602.75 | silence_duration: 1.5
+[silencedetect @ 0x55d5c8a3c0] silence_start: 1199.5
+size=N/A time=00:20:00.00 bitrate=N/A speed= 812x`
+
+	silences := parseSilences(output)
+
+	if len(silences) != 2 {
+		t.Fatalf("Expected 2 closed silences, got %d: %+v", len(silences), silences)
+	}
+
+	if silences[0].start != 0 || silences[0].end != 1504*time.Millisecond {
+		t.Errorf("Unexpected first silence: %+v", silences[0])
+	}
+
+	if silences[1].start != 601250*time.Millisecond || silences[1].end != 602750*time.Millisecond {
+		t.Errorf("Unexpected second silence: %+v", silences[1])
+	}
+}
+
+func TestParseProbeDuration(t *testing.T) {
+	duration, err := parseProbeDuration("3600.512000\n")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if duration != time.Hour+512*time.Millisecond {
+		t.Errorf("Expected 1h0m0.512s, got %v", duration)
+	}
+
+	if _, err := parseProbeDuration("N/A\n"); err == nil {
+		t.Error("Expected error for unknown duration")
+	}
+}
+
+func TestStitchTranscripts(t *testing.T) {
+	chunks := []audioChunk{
+		{start: 0},
+		{start: 10 * time.Minute, overlapsPrevious: true},
+		{start: 20 * time.Minute},
+	}
+	texts := []string{
+		"Let's review the roadmap for next quarter.",
+		"next quarter. We ship the recorder first",
+		"and then the search service.",
+	}
+
+	expected := "Let's review the roadmap for next quarter. We ship the recorder first and then the search service."
+	if got := stitchTranscripts(chunks, texts); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestStitchTranscripts_NoRepeatedWords(t *testing.T) {
+	chunks := []audioChunk{{start: 0}, {start: time.Minute, overlapsPrevious: true}}
+	texts := []string{"first part", "second part"}
+
+	if got := stitchTranscripts(chunks, texts); got != "first part second part" {
+		t.Errorf("Expected texts to be joined unchanged, got %q", got)
+	}
+}
+
+func TestBuildChunkArgs(t *testing.T) {
+	chunk := audioChunk{start: 90 * time.Second, end: 150*time.Second + 500*time.Millisecond}
+	args := buildChunkArgs("/tmp/source.wav", "/tmp/chunk-0001.wav", chunk)
+
+	expected := []string{
+		"-v", "error",
+		"-ss", "90.000",
+		"-t", "60.500",
+		"-i", "/tmp/source.wav",
+		"-ac", "1",
+		"-ar", "16000",
+		"-acodec", "pcm_s16le",
+		"-y",
+		"/tmp/chunk-0001.wav",
+	}
+
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+}
+
+func TestStitchResults_OffsetsAndDropsOverlap(t *testing.T) {
+	chunks := []audioChunk{
+		{start: 0, end: 10 * time.Second},
+		{start: 8 * time.Second, end: 18 * time.Second, overlapsPrevious: true},
+	}
+	results := []*ports.TranscriptionResult{
+		{
+			Text:     "one two three",
+			Language: "english",
+			Segments: []ports.TranscriptSegment{{ID: 0, Start: 0, End: 9, Text: "one two three"}},
+			Words:    []ports.TranscriptWord{{Word: "three", Start: 8.5, End: 9}},
+			Provider: "openai",
+		},
+		{
+			Text:     "three four",
+			Provider: "groq",
+			Segments: []ports.TranscriptSegment{
+				{ID: 0, Start: 0, End: 1, Text: "three"},
+				{ID: 1, Start: 1, End: 4, Text: "four"},
+			},
+			Words: []ports.TranscriptWord{
+				{Word: "three", Start: 0.5, End: 1},
+				{Word: "four", Start: 2.5, End: 3},
+			},
+		},
+	}
+
+	result := stitchResults(chunks, results)
+
+	if result.Text != "one two three four" {
+		t.Errorf("Expected stitched text, got %q", result.Text)
+	}
+
+	if result.Language != "english" || result.Duration != 18 {
+		t.Errorf("Unexpected language or duration: %q, %v", result.Language, result.Duration)
+	}
+
+	if result.Provider != "openai,groq" {
+		t.Errorf("Expected both providers named, got %q", result.Provider)
+	}
+
+	if len(result.Segments) != 2 {
+		t.Fatalf("Expected the repeated segment to be dropped, got %+v", result.Segments)
+	}
+
+	if result.Segments[1].ID != 1 || result.Segments[1].Start != 9 || result.Segments[1].End != 12 {
+		t.Errorf("Expected the second segment shifted to 9s-12s with ID 1, got %+v", result.Segments[1])
+	}
+
+	if len(result.Words) != 2 || result.Words[1].Word != "four" || result.Words[1].Start != 10.5 {
+		t.Errorf("Expected the repeated word to be dropped and timings shifted, got %+v", result.Words)
+	}
+}
+
+func TestStitchResults_WordsMatchText(t *testing.T) {
+	chunks := []audioChunk{
+		{start: 0, end: 10 * time.Second},
+		{start: 8 * time.Second, end: 18 * time.Second, overlapsPrevious: true},
+	}
+	// The second chunk hears a word in the overlap that the first one missed
+	results := []*ports.TranscriptionResult{
+		{
+			Text:  "one two",
+			Words: []ports.TranscriptWord{{Word: "one", Start: 1, End: 2}, {Word: "two", Start: 7, End: 8}},
+		},
+		{
+			Text:  "two three four",
+			Words: []ports.TranscriptWord{{Word: "two", Start: 0, End: 0.4}, {Word: "three", Start: 1, End: 1.5}, {Word: "four", Start: 3, End: 3.5}},
+		},
+	}
+
+	result := stitchResults(chunks, results)
+
+	if result.Text != "one two three four" {
+		t.Errorf("Expected stitched text, got %q", result.Text)
+	}
+
+	var words []string
+	for _, word := range result.Words {
+		words = append(words, word.Word)
+	}
+	if strings.Join(words, " ") != result.Text {
+		t.Errorf("Expected the words to match the text %q, got %v", result.Text, words)
+	}
+}
+
+func TestStitchResults_SilentMiddleChunk(t *testing.T) {
+	chunks := []audioChunk{
+		{start: 0, end: 10 * time.Second},
+		{start: 8 * time.Second, end: 18 * time.Second, overlapsPrevious: true},
+		{start: 16 * time.Second, end: 26 * time.Second, overlapsPrevious: true},
+	}
+	results := []*ports.TranscriptionResult{
+		{
+			Text:     "one two",
+			Segments: []ports.TranscriptSegment{{ID: 0, Start: 0, End: 5, Text: "one two"}},
+			Provider: "openai",
+		},
+		{},
+		{
+			Text:     "three",
+			Segments: []ports.TranscriptSegment{{ID: 0, Start: 3, End: 4, Text: "three"}},
+			Provider: "openai",
+		},
+	}
+
+	result := stitchResults(chunks, results)
+
+	if result.Text != "one two three" || result.Provider != "openai" {
+		t.Errorf("Expected the silent chunk to add nothing, got %q from %q", result.Text, result.Provider)
+	}
+
+	if len(result.Segments) != 2 || result.Segments[1].ID != 1 || result.Segments[1].Start != 19 {
+		t.Errorf("Expected the last segment shifted to 19s with ID 1, got %+v", result.Segments)
+	}
+}
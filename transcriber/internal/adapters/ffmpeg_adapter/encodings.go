@@ -0,0 +1,83 @@
+package ffmpeg_adapter
+
+import (
+	"strconv"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// speechSampleRate is the sample rate of the speech preset, which is all speech
+// models use, so anything more is wasted space
+const speechSampleRate = 16000
+
+// opusSampleRate is the rate Opus is encoded at under the standard preset; libopus
+// does not accept 44.1 kHz
+const opusSampleRate = 48000
+
+// audioEncoding is how one output format is encoded under each preset
+type audioEncoding struct {
+	codec        string
+	bitrate      string   // standard preset; empty for lossless codecs
+	speechRate   string   // speech preset bitrate
+	fixedRate    int      // sample rate the codec requires under the standard preset, 0 for any
+	speechExtras []string // further codec options for the speech preset
+}
+
+// audioEncodings lists the formats the recorder and transcoder can write
+var audioEncodings = map[string]audioEncoding{
+	"wav":  {codec: "pcm_s16le"},
+	"mp3":  {codec: "mp3", bitrate: "128k", speechRate: "32k"},
+	"flac": {codec: "flac"},
+	"opus": {codec: "libopus", bitrate: "64k", speechRate: "24k", fixedRate: opusSampleRate, speechExtras: []string{"-application", "voip"}},
+	"m4a":  {codec: "aac", bitrate: "128k", speechRate: "32k"},
+}
+
+// ValidateEncoding checks that a format and preset can be written. An empty
+// preset is the standard one.
+func ValidateEncoding(format, preset string) error {
+	if _, ok := audioEncodings[format]; !ok {
+		return ErrInvalidFormat
+	}
+	if preset != "" && preset != ports.RecordingPresetStandard && preset != ports.RecordingPresetSpeech {
+		return ErrInvalidPreset
+	}
+	return nil
+}
+
+// buildSampleArgs builds the ffmpeg sample rate and channel arguments for a format and
+// preset. A zero rate or channel count keeps that of the input unless the codec needs otherwise.
+func buildSampleArgs(format, preset string, sampleRate, channels int) []string {
+	if preset == ports.RecordingPresetSpeech {
+		sampleRate, channels = speechSampleRate, 1
+	} else if fixedRate := audioEncodings[format].fixedRate; fixedRate > 0 {
+		sampleRate = fixedRate
+	}
+
+	var args []string
+	if sampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(sampleRate))
+	}
+	if channels > 0 {
+		args = append(args, "-ac", strconv.Itoa(channels))
+	}
+	return args
+}
+
+// buildCodecArgs builds the ffmpeg codec arguments for a format and preset.
+// The format must have passed ValidateEncoding.
+func buildCodecArgs(format, preset string) []string {
+	encoding := audioEncodings[format]
+
+	args := []string{"-acodec", encoding.codec}
+	if preset == ports.RecordingPresetSpeech {
+		if encoding.speechRate != "" {
+			args = append(args, "-b:a", encoding.speechRate)
+		}
+		return append(args, encoding.speechExtras...)
+	}
+
+	if encoding.bitrate != "" {
+		args = append(args, "-b:a", encoding.bitrate)
+	}
+	return args
+}
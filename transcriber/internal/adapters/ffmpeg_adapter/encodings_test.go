@@ -0,0 +1,57 @@
+package ffmpeg_adapter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestValidateEncoding(t *testing.T) {
+	for _, format := range []string{"wav", "mp3", "flac", "opus", "m4a"} {
+		if err := ValidateEncoding(format, ports.RecordingPresetSpeech); err != nil {
+			t.Errorf("Expected %s to be supported, got %v", format, err)
+		}
+	}
+
+	if err := ValidateEncoding("aiff", ""); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Expected ErrInvalidFormat, got %v", err)
+	}
+	if err := ValidateEncoding("opus", "podcast"); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("Expected ErrInvalidPreset, got %v", err)
+	}
+}
+
+func TestBuildEncodingArgs(t *testing.T) {
+	tests := []struct {
+		format   string
+		preset   string
+		expected string
+	}{
+		{"wav", "", "-ar 44100 -ac 2 -acodec pcm_s16le"},
+		{"mp3", ports.RecordingPresetStandard, "-ar 44100 -ac 2 -acodec mp3 -b:a 128k"},
+		{"flac", ports.RecordingPresetSpeech, "-ar 16000 -ac 1 -acodec flac"},
+		{"opus", "", "-ar 48000 -ac 2 -acodec libopus -b:a 64k"},
+		{"opus", ports.RecordingPresetSpeech, "-ar 16000 -ac 1 -acodec libopus -b:a 24k -application voip"},
+		{"m4a", ports.RecordingPresetSpeech, "-ar 16000 -ac 1 -acodec aac -b:a 32k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.preset, func(t *testing.T) {
+			args := append(buildSampleArgs(tt.format, tt.preset, 44100, 2), buildCodecArgs(tt.format, tt.preset)...)
+			if got := strings.Join(args, " "); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildTranscodeArgs(t *testing.T) {
+	args := buildTranscodeArgs("/tmp/source.wav", "/tmp/transcoded.m4a", "m4a", ports.RecordingPresetStandard)
+
+	expected := "-v error -i /tmp/source.wav -vn -acodec aac -b:a 128k -y /tmp/transcoded.m4a"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
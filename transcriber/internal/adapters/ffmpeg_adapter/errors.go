@@ -1,26 +1,34 @@
 package ffmpeg_adapter
 
-import "errors"
+import (
+	"fmt"
+
+	"speakr/transcriber/internal/ports"
+)
 
 // Custom error types for predictable failures
 var (
-	ErrFFmpegNotFound           = errors.New("ffmpeg executable not found in PATH")
-	ErrRecordingAlreadyExists   = errors.New("recording with this ID already exists")
-	ErrRecordingNotFound        = errors.New("recording with this ID not found")
-	ErrRecordingFileNotFound    = errors.New("recording file not found after stopping")
-	ErrInvalidFormat            = errors.New("invalid audio format specified")
-	ErrInsufficientDiskSpace    = errors.New("insufficient disk space for recording")
-	ErrPermissionDenied         = errors.New("permission denied accessing audio device or file system")
+	ErrFFmpegNotFound           = fmt.Errorf("ffmpeg executable not found in PATH: %w", ports.ErrRecorderUnavailable)
+	ErrRecordingAlreadyExists   = fmt.Errorf("recording with this ID already exists: %w", ports.ErrRecordingExists)
+	ErrRecordingNotFound        = fmt.Errorf("recording with this ID not found: %w", ports.ErrRecordingNotFound)
+	ErrRecordingFileNotFound    = fmt.Errorf("recording file not found after stopping: %w", ports.ErrRecordingFileNotFound)
+	ErrInvalidFormat            = fmt.Errorf("invalid audio format specified: %w", ports.ErrInvalidRecordingFormat)
+	ErrInvalidPreset            = fmt.Errorf("invalid encoding preset specified: %w", ports.ErrInvalidPreset)
+	ErrInsufficientDiskSpace    = fmt.Errorf("insufficient disk space for recording: %w", ports.ErrInsufficientDiskSpace)
+	ErrPermissionDenied         = fmt.Errorf("permission denied accessing audio device or file system: %w", ports.ErrPermissionDenied)
+	ErrRecordingPaused          = fmt.Errorf("recording with this ID is already paused: %w", ports.ErrRecordingPaused)
+	ErrRecordingNotPaused       = fmt.Errorf("recording with this ID is not paused: %w", ports.ErrRecordingNotPaused)
+	ErrRecordingTimeExhausted   = fmt.Errorf("recording with this ID has no recording time left: %w", ports.ErrRecordingTimeExhausted)
 
 	// ErrDeviceNotFound indicates that the specified audio device was not found
-	ErrDeviceNotFound = errors.New("audio device not found")
+	ErrDeviceNotFound = fmt.Errorf("audio device not found: %w", ports.ErrDeviceNotFound)
 
 	// ErrDevicePermissionDenied indicates permission issues accessing the audio device
-	ErrDevicePermissionDenied = errors.New("permission denied accessing audio device")
+	ErrDevicePermissionDenied = fmt.Errorf("permission denied accessing audio device: %w", ports.ErrPermissionDenied)
 
 	// ErrDeviceBusy indicates that the audio device is currently in use
-	ErrDeviceBusy = errors.New("audio device is busy")
+	ErrDeviceBusy = fmt.Errorf("audio device is busy: %w", ports.ErrDeviceBusy)
 
 	// ErrUnsupportedPlatform indicates that the current platform is not supported
-	ErrUnsupportedPlatform = errors.New("unsupported platform for audio device detection")
+	ErrUnsupportedPlatform = fmt.Errorf("unsupported platform for audio device detection: %w", ports.ErrUnsupportedPlatform)
 )
\ No newline at end of file
@@ -0,0 +1,37 @@
+package ffmpeg_adapter
+
+import (
+	"errors"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestErrors_WrapPortSentinels(t *testing.T) {
+	tests := []struct {
+		err  error
+		port error
+	}{
+		{ErrFFmpegNotFound, ports.ErrRecorderUnavailable},
+		{ErrRecordingAlreadyExists, ports.ErrRecordingExists},
+		{ErrRecordingNotFound, ports.ErrRecordingNotFound},
+		{ErrRecordingFileNotFound, ports.ErrRecordingFileNotFound},
+		{ErrInvalidFormat, ports.ErrInvalidRecordingFormat},
+		{ErrInvalidPreset, ports.ErrInvalidPreset},
+		{ErrInsufficientDiskSpace, ports.ErrInsufficientDiskSpace},
+		{ErrPermissionDenied, ports.ErrPermissionDenied},
+		{ErrRecordingPaused, ports.ErrRecordingPaused},
+		{ErrRecordingNotPaused, ports.ErrRecordingNotPaused},
+		{ErrRecordingTimeExhausted, ports.ErrRecordingTimeExhausted},
+		{ErrDeviceNotFound, ports.ErrDeviceNotFound},
+		{ErrDevicePermissionDenied, ports.ErrPermissionDenied},
+		{ErrDeviceBusy, ports.ErrDeviceBusy},
+		{ErrUnsupportedPlatform, ports.ErrUnsupportedPlatform},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.port) {
+			t.Errorf("Expected %q to wrap %q", tt.err, tt.port)
+		}
+	}
+}
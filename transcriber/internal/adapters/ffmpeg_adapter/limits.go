@@ -0,0 +1,91 @@
+package ffmpeg_adapter
+
+import (
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// limitPollInterval is how often a recording is checked against its limits
+const limitPollInterval = 250 * time.Millisecond
+
+// limitWatcher calls onLimit once when one ffmpeg run takes its recording past the
+// maximum duration or size. It only reads the file being written, so it never
+// needs the recorder lock.
+type limitWatcher struct {
+	maxDuration    time.Duration
+	maxBytes       int64
+	recordedBefore time.Duration // length of the recording before this ffmpeg run
+	bytesBefore    int64         // size of the completed segment files
+	segmentPath    string
+	startedAt      time.Time
+	onLimit        func()
+	reached        *atomic.Bool // the session's flag, so a limit is only reported once
+	logger         *slog.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// run checks the limits until one is reached or the watcher is stopped
+func (w *limitWatcher) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(limitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case now := <-ticker.C:
+			if limit := w.exceeded(now); limit != "" {
+				if w.reached.CompareAndSwap(false, true) {
+					w.logger.Info("Recording limit reached", "limit", limit)
+					w.onLimit()
+				}
+				return
+			}
+		}
+	}
+}
+
+// exceeded returns the limit the recording has reached, or "" if none
+func (w *limitWatcher) exceeded(now time.Time) string {
+	if w.maxDuration > 0 && w.recordedBefore+now.Sub(w.startedAt) >= w.maxDuration {
+		return "duration"
+	}
+
+	if w.maxBytes > 0 {
+		size := w.bytesBefore
+		if info, err := os.Stat(w.segmentPath); err == nil {
+			size += info.Size()
+		}
+		if size >= w.maxBytes {
+			return "bytes"
+		}
+	}
+
+	return ""
+}
+
+// effectiveMaxDuration caps a recording's maximum duration at the recorder's timeout,
+// after which ffmpeg is stopped regardless
+func effectiveMaxDuration(maxDuration, recordTimeout time.Duration) time.Duration {
+	if maxDuration <= 0 || maxDuration > recordTimeout {
+		return recordTimeout
+	}
+	return maxDuration
+}
+
+// segmentBytes sums the sizes of the segment files written so far
+func segmentBytes(segments []string) int64 {
+	var total int64
+	for _, segment := range segments {
+		if info, err := os.Stat(segment); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
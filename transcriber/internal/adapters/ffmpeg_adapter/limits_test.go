@@ -0,0 +1,91 @@
+package ffmpeg_adapter
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLimitWatcher(t *testing.T, onLimit func()) *limitWatcher {
+	t.Helper()
+
+	return &limitWatcher{
+		segmentPath: filepath.Join(t.TempDir(), "rec.part0.wav"),
+		startedAt:   time.Now(),
+		onLimit:     onLimit,
+		reached:     &atomic.Bool{},
+		logger:      slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+func TestLimitWatcher_Exceeded(t *testing.T) {
+	w := newTestLimitWatcher(t, nil)
+	w.maxDuration = time.Minute
+	w.recordedBefore = 40 * time.Second
+	w.maxBytes = 1000
+	w.bytesBefore = 600
+
+	if limit := w.exceeded(w.startedAt.Add(10 * time.Second)); limit != "" {
+		t.Errorf("Expected no limit after 50s and 600 bytes, got %q", limit)
+	}
+
+	if limit := w.exceeded(w.startedAt.Add(20 * time.Second)); limit != "duration" {
+		t.Errorf("Expected the duration limit after 60s, got %q", limit)
+	}
+
+	if err := os.WriteFile(w.segmentPath, make([]byte, 400), 0644); err != nil {
+		t.Fatalf("Failed to write segment: %v", err)
+	}
+	w.maxDuration = 0
+
+	if limit := w.exceeded(w.startedAt); limit != "bytes" {
+		t.Errorf("Expected the size limit at 1000 bytes, got %q", limit)
+	}
+}
+
+func TestLimitWatcher_ReportsOnce(t *testing.T) {
+	var calls atomic.Int32
+	w := newTestLimitWatcher(t, func() { calls.Add(1) })
+	w.maxDuration = time.Millisecond
+
+	go w.run()
+	select {
+	case <-w.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the watcher to stop once the limit was reached")
+	}
+
+	// A resumed recording gets a new watcher sharing the session's flag
+	next := newTestLimitWatcher(t, func() { calls.Add(1) })
+	next.maxDuration = time.Millisecond
+	next.reached = w.reached
+
+	go next.run()
+	<-next.done
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected onLimit to be called once, got %d", calls.Load())
+	}
+}
+
+func TestEffectiveMaxDuration(t *testing.T) {
+	tests := []struct {
+		maxDuration time.Duration
+		expected    time.Duration
+	}{
+		{0, 30 * time.Minute},
+		{10 * time.Minute, 10 * time.Minute},
+		{time.Hour, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := effectiveMaxDuration(tt.maxDuration, 30*time.Minute); got != tt.expected {
+			t.Errorf("effectiveMaxDuration(%v) = %v, expected %v", tt.maxDuration, got, tt.expected)
+		}
+	}
+}
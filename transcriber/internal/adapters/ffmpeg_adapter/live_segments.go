@@ -0,0 +1,168 @@
+package ffmpeg_adapter
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// liveSegmentPollInterval is how often the segment list is checked for closed segments
+const liveSegmentPollInterval = 500 * time.Millisecond
+
+// liveSegmentSampleRate is the sample rate of live segments, which are written as
+// 16 kHz mono PCM, the input speech models expect, whatever the recording format
+const liveSegmentSampleRate = 16000
+
+// liveSegmentEntry is a closed segment listed by ffmpeg's segment muxer
+type liveSegmentEntry struct {
+	fileName string
+	start    time.Duration
+	end      time.Duration
+}
+
+// liveSegmentWatcher hands the live segments of one ffmpeg run to the recording's
+// callback as ffmpeg closes them. It runs until stop is closed, then picks up
+// the segments written on shutdown and closes done.
+type liveSegmentWatcher struct {
+	recordingID string
+	dir         string
+	listPath    string
+	offset      time.Duration // length of the recording before this ffmpeg run
+	nextSeq     *int          // the session's next sequence number
+	onSegment   func(ports.LiveSegment)
+	discard     *atomic.Bool // set when the recording is cancelled
+	logger      *slog.Logger
+
+	processed int // list entries already handled
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// run polls the segment list until stopped
+func (w *liveSegmentWatcher) run() {
+	defer close(w.done)
+	defer os.Remove(w.listPath)
+
+	ticker := time.NewTicker(liveSegmentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			w.scan()
+			return
+		case <-ticker.C:
+			w.scan()
+		}
+	}
+}
+
+// scan delivers segments listed since the previous scan
+func (w *liveSegmentWatcher) scan() {
+	data, err := os.ReadFile(w.listPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			w.logger.Warn("Failed to read live segment list", "error", err, "file_path", w.listPath)
+		}
+		return
+	}
+
+	entries := parseSegmentList(string(data))
+	for _, entry := range entries[w.processed:] {
+		w.processed++
+		w.deliver(entry)
+	}
+}
+
+// deliver reads a closed segment, removes its file and passes it to the callback
+func (w *liveSegmentWatcher) deliver(entry liveSegmentEntry) {
+	path := filepath.Join(w.dir, entry.fileName)
+	defer os.Remove(path)
+
+	if w.discard.Load() {
+		return
+	}
+
+	audio, err := os.ReadFile(path)
+	if err != nil {
+		w.logger.Warn("Failed to read live segment", "error", err, "file_path", path)
+		return
+	}
+
+	segment := ports.LiveSegment{
+		RecordingID: w.recordingID,
+		Sequence:    *w.nextSeq,
+		Offset:      w.offset + entry.start,
+		Duration:    entry.end - entry.start,
+		Format:      "wav",
+		Audio:       audio,
+	}
+	*w.nextSeq++
+
+	w.logger.Debug("Live segment closed", "sequence", segment.Sequence, "offset", segment.Offset, "duration", segment.Duration)
+	w.onSegment(segment)
+}
+
+// parseSegmentList parses the complete lines of a csv segment list
+// ("file,start,end" per closed segment, times in seconds)
+func parseSegmentList(data string) []liveSegmentEntry {
+	var entries []liveSegmentEntry
+
+	// The last line may still be being written
+	complete := data[:strings.LastIndex(data, "\n")+1]
+
+	for _, line := range strings.Split(complete, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) != 3 {
+			continue
+		}
+
+		start, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		end, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, liveSegmentEntry{
+			fileName: fields[0],
+			start:    secondsToDuration(start),
+			end:      secondsToDuration(end),
+		})
+	}
+
+	return entries
+}
+
+// buildLiveSegmentArgs builds the ffmpeg output arguments that also write the
+// recording as numbered segments of the given length, listing each closed one
+func buildLiveSegmentArgs(pattern, listPath string, segmentDuration time.Duration, startNumber int) []string {
+	return []string{
+		"-f", "segment",
+		"-segment_time", formatSeconds(segmentDuration),
+		"-segment_start_number", strconv.Itoa(startNumber),
+		"-segment_list", listPath,
+		"-segment_list_type", "csv",
+		"-reset_timestamps", "1",
+		"-ar", strconv.Itoa(liveSegmentSampleRate),
+		"-ac", "1",
+		"-acodec", "pcm_s16le",
+		pattern,
+	}
+}
+
+// livePaths returns the segment file pattern and list path for one ffmpeg run
+func livePaths(dir, recordingID string, part int) (string, string) {
+	pattern := filepath.Join(dir, fmt.Sprintf("%s.live%%05d.wav", recordingID))
+	listPath := filepath.Join(dir, fmt.Sprintf("%s.part%d.live.csv", recordingID, part))
+	return pattern, listPath
+}
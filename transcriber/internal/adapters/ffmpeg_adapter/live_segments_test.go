@@ -0,0 +1,129 @@
+package ffmpeg_adapter
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestParseSegmentList(t *testing.T) {
+	data := "rec.live00003.wav,0.000000,15.000000\n" +
+		"rec.live00004.wav,15.000000,29.520000\n" +
+		"rec.live00005.wav,29.52"
+
+	entries := parseSegmentList(data)
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 complete entries, got %d: %+v", len(entries), entries)
+	}
+
+	if entries[1].fileName != "rec.live00004.wav" || entries[1].start != 15*time.Second || entries[1].end != 29520*time.Millisecond {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+}
+
+func TestBuildLiveSegmentArgs(t *testing.T) {
+	args := buildLiveSegmentArgs("/tmp/rec.live%05d.wav", "/tmp/rec.part1.live.csv", 15*time.Second, 4)
+
+	expected := []string{
+		"-f", "segment",
+		"-segment_time", "15.000",
+		"-segment_start_number", "4",
+		"-segment_list", "/tmp/rec.part1.live.csv",
+		"-segment_list_type", "csv",
+		"-reset_timestamps", "1",
+		"-ar", "16000",
+		"-ac", "1",
+		"-acodec", "pcm_s16le",
+		"/tmp/rec.live%05d.wav",
+	}
+
+	if strings.Join(args, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+}
+
+func TestLiveSegmentWatcher_DeliversClosedSegments(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	var delivered []ports.LiveSegment
+	var discard atomic.Bool
+	nextSeq := 2
+	watcher := &liveSegmentWatcher{
+		recordingID: "rec",
+		dir:         dir,
+		listPath:    filepath.Join(dir, "rec.part1.live.csv"),
+		offset:      30 * time.Second,
+		nextSeq:     &nextSeq,
+		onSegment:   func(segment ports.LiveSegment) { delivered = append(delivered, segment) },
+		discard:     &discard,
+		logger:      logger,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	os.WriteFile(filepath.Join(dir, "rec.live00002.wav"), []byte("first"), 0644)
+	os.WriteFile(filepath.Join(dir, "rec.live00003.wav"), []byte("second"), 0644)
+	os.WriteFile(watcher.listPath, []byte("rec.live00002.wav,0.000000,15.000000\n"), 0644)
+
+	watcher.scan()
+
+	// A later scan only picks up the newly listed segment
+	os.WriteFile(watcher.listPath, []byte("rec.live00002.wav,0.000000,15.000000\nrec.live00003.wav,15.000000,20.000000\n"), 0644)
+	close(watcher.stop)
+	watcher.run()
+
+	if len(delivered) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(delivered))
+	}
+
+	second := delivered[1]
+	if second.Sequence != 3 || second.Offset != 45*time.Second || second.Duration != 5*time.Second || string(second.Audio) != "second" {
+		t.Errorf("Unexpected second segment: %+v", second)
+	}
+
+	if nextSeq != 4 {
+		t.Errorf("Expected the next sequence number to be 4, got %d", nextSeq)
+	}
+
+	for _, name := range []string{"rec.live00002.wav", "rec.live00003.wav", "rec.part1.live.csv"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+}
+
+func TestLiveSegmentWatcher_DiscardsAfterCancel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	dir := t.TempDir()
+
+	var discard atomic.Bool
+	discard.Store(true)
+	nextSeq := 0
+	watcher := &liveSegmentWatcher{
+		recordingID: "rec",
+		dir:         dir,
+		listPath:    filepath.Join(dir, "rec.part0.live.csv"),
+		nextSeq:     &nextSeq,
+		onSegment:   func(segment ports.LiveSegment) { t.Error("Expected no segment after cancel") },
+		discard:     &discard,
+		logger:      logger,
+	}
+
+	segmentPath := filepath.Join(dir, "rec.live00000.wav")
+	os.WriteFile(segmentPath, []byte("audio"), 0644)
+	os.WriteFile(watcher.listPath, []byte("rec.live00000.wav,0.000000,15.000000\n"), 0644)
+
+	watcher.scan()
+
+	if _, err := os.Stat(segmentPath); !os.IsNotExist(err) {
+		t.Error("Expected the discarded segment file to be removed")
+	}
+}
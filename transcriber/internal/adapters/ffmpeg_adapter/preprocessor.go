@@ -0,0 +1,248 @@
+package ffmpeg_adapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// trimMargin is how much silence is kept around the speech when trimming,
+// so the first and last words are not clipped
+const trimMargin = 250 * time.Millisecond
+
+// loudnormSampleRate is the output rate after loudness normalization, which
+// otherwise upsamples to 192 kHz
+const loudnormSampleRate = 48000
+
+// PreprocessorConfig holds configuration for the audio pre-processor
+type PreprocessorConfig struct {
+	TempDir           string
+	HighpassFrequency int
+	TrimThreshold     string
+	TrimMinSilence    time.Duration
+}
+
+// PreprocessorOption is a functional option for configuring the pre-processor
+type PreprocessorOption func(*PreprocessorConfig)
+
+// WithPreprocessTempDir sets the directory used for intermediate audio files
+func WithPreprocessTempDir(dir string) PreprocessorOption {
+	return func(c *PreprocessorConfig) {
+		c.TempDir = dir
+	}
+}
+
+// WithHighpassFrequency sets the cutoff of the high-pass filter in Hz
+func WithHighpassFrequency(frequency int) PreprocessorOption {
+	return func(c *PreprocessorConfig) {
+		c.HighpassFrequency = frequency
+	}
+}
+
+// WithTrimThreshold sets the noise level (e.g. "-50dB") and minimum length of the
+// silences cut from the start and end of the audio
+func WithTrimThreshold(threshold string, minSilence time.Duration) PreprocessorOption {
+	return func(c *PreprocessorConfig) {
+		c.TrimThreshold = threshold
+		c.TrimMinSilence = minSilence
+	}
+}
+
+// Preprocessor implements the AudioPreprocessor port with ffmpeg audio filters
+type Preprocessor struct {
+	config PreprocessorConfig
+	logger *slog.Logger
+}
+
+// NewPreprocessor creates a new ffmpeg audio pre-processor
+func NewPreprocessor(logger *slog.Logger, opts ...PreprocessorOption) (*Preprocessor, error) {
+	config := PreprocessorConfig{
+		TempDir:           "/tmp/speakr",
+		HighpassFrequency: 100,
+		TrimThreshold:     "-50dB",
+		TrimMinSilence:    500 * time.Millisecond,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.HighpassFrequency <= 0 {
+		return nil, fmt.Errorf("high-pass frequency must be positive, got %d", config.HighpassFrequency)
+	}
+
+	if err := os.MkdirAll(config.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, ErrFFmpegNotFound
+	}
+
+	return &Preprocessor{
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// audioTrim is the part of the audio kept by trim_silence; a zero end keeps the rest
+type audioTrim struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// Preprocess runs the steps over the audio and returns it as WAV
+func (p *Preprocessor) Preprocess(ctx context.Context, audioData io.Reader, format string, steps []string) (*ports.PreprocessedAudio, error) {
+	logger := p.logger.With("format", format, "steps", steps)
+
+	workDir, err := os.MkdirTemp(p.config.TempDir, "preprocess-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-processing directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	sourcePath := filepath.Join(workDir, "source."+format)
+	if _, err := spoolAudio(sourcePath, audioData); err != nil {
+		return nil, err
+	}
+
+	var trim audioTrim
+	for _, step := range steps {
+		if step == ports.PreprocessTrimSilence {
+			trim, err = p.detectTrim(ctx, sourcePath)
+			if err != nil {
+				logger.Error("Failed to detect silence to trim", "error", err)
+				return nil, err
+			}
+			break
+		}
+	}
+
+	outputPath := filepath.Join(workDir, "processed.wav")
+	args := buildPreprocessArgs(sourcePath, outputPath, steps, p.config, trim)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg pre-processing failed", "error", err, "args", args)
+		return nil, fmt.Errorf("failed to pre-process audio: %w: %s", err, output)
+	}
+
+	processed, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pre-processed audio: %w", err)
+	}
+
+	logger.Info("Audio pre-processed", "size", len(processed), "trimmed_start", trim.start)
+	return &ports.PreprocessedAudio{
+		Audio:   bytes.NewReader(processed),
+		Format:  "wav",
+		Trimmed: trim.start,
+	}, nil
+}
+
+// detectTrim finds the silence at the start and end of the audio
+func (p *Preprocessor) detectTrim(ctx context.Context, sourcePath string) (audioTrim, error) {
+	filter := fmt.Sprintf("silencedetect=noise=%s:d=%s", p.config.TrimThreshold, formatSeconds(p.config.TrimMinSilence))
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-nostats", "-i", sourcePath, "-af", filter, "-f", "null", "-")
+
+	// silencedetect reports on stderr
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return audioTrim{}, fmt.Errorf("failed to detect silences: %w", err)
+	}
+
+	return parseTrim(string(output)), nil
+}
+
+// parseTrim works out the audio to keep from silencedetect output: a silence starting
+// at the beginning is cut up to its end, and a silence still open when the audio ends
+// is cut from its start, each leaving trimMargin of silence next to the speech
+func parseTrim(output string) audioTrim {
+	var trim audioTrim
+	var openSilence time.Duration
+	open := false
+	first := true
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := silenceStartPattern.FindStringSubmatch(line); match != nil {
+			start, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			openSilence = secondsToDuration(start)
+			open = true
+			continue
+		}
+
+		if match := silenceEndPattern.FindStringSubmatch(line); match != nil && open {
+			end, err := strconv.ParseFloat(match[1], 64)
+			if err != nil {
+				continue
+			}
+			if first && openSilence <= 0 {
+				trim.start = max(secondsToDuration(end)-trimMargin, 0)
+			}
+			open = false
+			first = false
+		}
+	}
+
+	if open && openSilence > 0 {
+		trim.end = openSilence + trimMargin
+	}
+
+	return trim
+}
+
+// buildPreprocessArgs builds the ffmpeg arguments that apply the steps in order
+// and write 16-bit PCM WAV
+func buildPreprocessArgs(sourcePath, outputPath string, steps []string, config PreprocessorConfig, trim audioTrim) []string {
+	var filters []string
+	var resample, loudnorm bool
+
+	for _, step := range steps {
+		switch step {
+		case ports.PreprocessHighpass:
+			filters = append(filters, fmt.Sprintf("highpass=f=%d", config.HighpassFrequency))
+		case ports.PreprocessDenoise:
+			filters = append(filters, "afftdn")
+		case ports.PreprocessLoudnorm:
+			filters = append(filters, "loudnorm=I=-16:TP=-1.5:LRA=11")
+			loudnorm = true
+		case ports.PreprocessTrimSilence:
+			if trim.start > 0 || trim.end > 0 {
+				atrim := "atrim=start=" + formatSeconds(trim.start)
+				if trim.end > 0 {
+					atrim += ":end=" + formatSeconds(trim.end)
+				}
+				filters = append(filters, atrim, "asetpts=PTS-STARTPTS")
+			}
+		case ports.PreprocessResample:
+			resample = true
+		}
+	}
+
+	args := []string{"-v", "error", "-i", sourcePath}
+	if len(filters) > 0 {
+		args = append(args, "-af", strings.Join(filters, ","))
+	}
+
+	switch {
+	case resample:
+		args = append(args, "-ar", "16000", "-ac", "1")
+	case loudnorm:
+		args = append(args, "-ar", strconv.Itoa(loudnormSampleRate))
+	}
+
+	return append(args, "-acodec", "pcm_s16le", "-y", outputPath)
+}
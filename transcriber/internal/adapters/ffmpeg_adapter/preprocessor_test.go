@@ -0,0 +1,65 @@
+package ffmpeg_adapter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestParseTrim(t *testing.T) {
+	output := `[silencedetect @ 0x55d5c8a3c0] silence_start: -0.0213
+[silencedetect @ 0x55d5c8a3c0] silence_end: 2.5 | silence_duration: 2.52
+[silencedetect @ 0x55d5c8a3c0] silence_start: 10.2
+[silencedetect @ 0x55d5c8a3c0] silence_end: 11.4 | silence_duration: 1.2
+[silencedetect @ 0x55d5c8a3c0] silence_start: 30.75
+size=N/A time=00:00:34.00 bitrate=N/A speed= 812x`
+
+	trim := parseTrim(output)
+
+	if trim.start != 2250*time.Millisecond {
+		t.Errorf("Expected the start trimmed to 2.25s, got %v", trim.start)
+	}
+	if trim.end != 31*time.Second {
+		t.Errorf("Expected the end trimmed at 31s, got %v", trim.end)
+	}
+}
+
+func TestParseTrim_NoEdgeSilence(t *testing.T) {
+	output := `[silencedetect @ 0x55d5c8a3c0] silence_start: 10.2
+[silencedetect @ 0x55d5c8a3c0] silence_end: 11.4 | silence_duration: 1.2`
+
+	if trim := parseTrim(output); trim != (audioTrim{}) {
+		t.Errorf("Expected nothing to trim, got %+v", trim)
+	}
+}
+
+func TestBuildPreprocessArgs(t *testing.T) {
+	config := PreprocessorConfig{HighpassFrequency: 120}
+	steps := []string{
+		ports.PreprocessHighpass,
+		ports.PreprocessDenoise,
+		ports.PreprocessLoudnorm,
+		ports.PreprocessTrimSilence,
+		ports.PreprocessResample,
+	}
+	trim := audioTrim{start: 2 * time.Second, end: 31 * time.Second}
+
+	args := buildPreprocessArgs("/tmp/source.mp3", "/tmp/processed.wav", steps, config, trim)
+
+	expected := "-v error -i /tmp/source.mp3 " +
+		"-af highpass=f=120,afftdn,loudnorm=I=-16:TP=-1.5:LRA=11,atrim=start=2.000:end=31.000,asetpts=PTS-STARTPTS " +
+		"-ar 16000 -ac 1 -acodec pcm_s16le -y /tmp/processed.wav"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestBuildPreprocessArgs_LoudnormKeepsSampleRateDown(t *testing.T) {
+	args := buildPreprocessArgs("in.wav", "out.wav", []string{ports.PreprocessLoudnorm}, PreprocessorConfig{}, audioTrim{})
+
+	if got := strings.Join(args, " "); !strings.Contains(got, "-ar 48000") {
+		t.Errorf("Expected loudnorm output at 48 kHz, got %q", got)
+	}
+}
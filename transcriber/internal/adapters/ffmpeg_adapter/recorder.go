@@ -8,18 +8,24 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 // RecorderConfig holds configuration for the FFmpeg recorder
 type RecorderConfig struct {
-	TempDir       string
-	InputDevice   string
-	OutputDevice  string
-	SampleRate    int
-	Channels      int
-	RecordTimeout time.Duration
+	TempDir          string
+	InputDevice      string
+	OutputDevice     string
+	SampleRate       int
+	Channels         int
+	RecordTimeout    time.Duration
+	SilenceThreshold string
 }
 
 // RecorderOption is a functional option for configuring the recorder
@@ -67,6 +73,14 @@ func WithRecordTimeout(timeout time.Duration) RecorderOption {
 	}
 }
 
+// WithSilenceThreshold sets the noise level (e.g. "-30dB") below which input counts
+// as silence for recordings that stop on silence
+func WithSilenceThreshold(threshold string) RecorderOption {
+	return func(c *RecorderConfig) {
+		c.SilenceThreshold = threshold
+	}
+}
+
 // Recorder implements the AudioRecorder port using FFmpeg
 type Recorder struct {
 	config         RecorderConfig
@@ -76,20 +90,38 @@ type Recorder struct {
 	mu             sync.RWMutex
 }
 
+// segmentStopTimeout is how long ffmpeg gets to finish a segment file after being
+// interrupted before it is killed
+const segmentStopTimeout = 5 * time.Second
+
 type recordingSession struct {
-	cmd      *exec.Cmd
-	filePath string
-	cancel   context.CancelFunc
+	cmd              *exec.Cmd // nil while paused
+	cancel           context.CancelFunc
+	filePath         string
+	segments         []string // segment files in recording order; the last one is being written unless paused
+	format           string
+	inputDevice      string
+	startedAt        time.Time
+	segmentStartedAt time.Time
+	recorded         time.Duration // length of the completed segments
+	paused           bool
+	options          ports.RecordingOptions
+	liveNext         int                 // sequence number of the next live segment
+	liveWatcher      *liveSegmentWatcher // nil unless live segments are being written
+	liveDiscard      atomic.Bool         // set on cancel so pending live segments are dropped
+	limitWatcher     *limitWatcher       // nil unless the recording has a limit callback
+	limitReached     atomic.Bool
 }
 
 // NewRecorder creates a new FFmpeg recorder with functional options
 func NewRecorder(logger *slog.Logger, opts ...RecorderOption) (*Recorder, error) {
 	config := RecorderConfig{
-		TempDir:       "/tmp/speakr",
-		InputDevice:   "default",
-		SampleRate:    44100,
-		Channels:      1,
-		RecordTimeout: 30 * time.Minute,
+		TempDir:          "/tmp/speakr",
+		InputDevice:      "default",
+		SampleRate:       44100,
+		Channels:         1,
+		RecordTimeout:    30 * time.Minute,
+		SilenceThreshold: "-30dB",
 	}
 
 	for _, opt := range opts {
@@ -115,7 +147,7 @@ func NewRecorder(logger *slog.Logger, opts ...RecorderOption) (*Recorder, error)
 }
 
 // StartRecording starts a new audio recording session
-func (r *Recorder) StartRecording(ctx context.Context, recordingID string, format string) error {
+func (r *Recorder) StartRecording(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -136,34 +168,106 @@ func (r *Recorder) StartRecording(ctx context.Context, recordingID string, forma
 		logger.Info("Input device validated successfully")
 	}
 
+	if format == "" {
+		format = "wav"
+	}
+	if err := ValidateEncoding(format, opts.Preset); err != nil {
+		logger.Error("Unsupported recording encoding", "error", err, "preset", opts.Preset)
+		return err
+	}
+
 	// Create file path
 	fileName := fmt.Sprintf("%s.%s", recordingID, format)
-	filePath := filepath.Join(r.config.TempDir, fileName)
+	session := &recordingSession{
+		filePath:    filepath.Join(r.config.TempDir, fileName),
+		format:      format,
+		inputDevice: r.config.InputDevice,
+		startedAt:   time.Now(),
+	}
+	session.options.Preset = opts.Preset
+	if opts.LiveSegmentDuration > 0 && opts.OnLiveSegment != nil {
+		session.options.LiveSegmentDuration = opts.LiveSegmentDuration
+		session.options.OnLiveSegment = opts.OnLiveSegment
+		logger = logger.With("live_segment_duration", opts.LiveSegmentDuration)
+	}
+	if opts.SilenceTimeout > 0 && opts.OnSilence != nil {
+		session.options.SilenceTimeout = opts.SilenceTimeout
+		session.options.OnSilence = opts.OnSilence
+		logger = logger.With("silence_timeout", opts.SilenceTimeout)
+	}
+	if opts.OnLimit != nil {
+		session.options.MaxDuration = effectiveMaxDuration(opts.MaxDuration, r.config.RecordTimeout)
+		session.options.MaxBytes = opts.MaxBytes
+		session.options.OnLimit = opts.OnLimit
+		logger = logger.With("max_duration", session.options.MaxDuration, "max_bytes", opts.MaxBytes)
+	}
+
+	if err := r.startSegment(ctx, logger, recordingID, session); err != nil {
+		return err
+	}
 
-	// Create context with timeout
-	recordCtx, cancel := context.WithTimeout(ctx, r.config.RecordTimeout)
+	// Store the recording session
+	r.recordings[recordingID] = session
 
-	// Build ffmpeg command
-	args := r.buildFFmpegArgs(filePath, format)
-	cmd := exec.CommandContext(recordCtx, "ffmpeg", args...)
+	logger.Info("Recording started successfully")
+	return nil
+}
 
-	logger.Info("Starting FFmpeg recording", "file_path", filePath, "args", args)
+// PauseRecording stops capturing audio for a recording, keeping what has been recorded so far
+func (r *Recorder) PauseRecording(ctx context.Context, recordingID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	// Start the recording
-	if err := cmd.Start(); err != nil {
-		cancel()
-		logger.Error("Failed to start FFmpeg", "error", err)
-		return fmt.Errorf("failed to start ffmpeg recording: %w", err)
+	logger := r.logger.With("recording_id", recordingID)
+
+	session, exists := r.recordings[recordingID]
+	if !exists {
+		logger.Error("Recording not found")
+		return ErrRecordingNotFound
 	}
 
-	// Store the recording session
-	r.recordings[recordingID] = &recordingSession{
-		cmd:      cmd,
-		filePath: filePath,
-		cancel:   cancel,
+	if session.paused {
+		logger.Error("Recording already paused")
+		return ErrRecordingPaused
 	}
 
-	logger.Info("Recording started successfully")
+	r.stopSegment(logger, session)
+	session.paused = true
+
+	logger.Info("Recording paused successfully", "segments", len(session.segments))
+	return nil
+}
+
+// ResumeRecording continues a paused recording in a new segment file
+func (r *Recorder) ResumeRecording(ctx context.Context, recordingID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logger := r.logger.With("recording_id", recordingID)
+
+	session, exists := r.recordings[recordingID]
+	if !exists {
+		logger.Error("Recording not found")
+		return ErrRecordingNotFound
+	}
+
+	if !session.paused {
+		logger.Error("Recording is not paused")
+		return ErrRecordingNotPaused
+	}
+
+	// A segment without time left would be cut off as soon as ffmpeg starts; the
+	// recording can only be stopped now
+	if r.config.RecordTimeout-session.recorded <= 0 {
+		logger.Error("Recording has no recording time left", "recorded", session.recorded, "max_duration", r.config.RecordTimeout)
+		return ErrRecordingTimeExhausted
+	}
+
+	if err := r.startSegment(ctx, logger, recordingID, session); err != nil {
+		return err
+	}
+
+	logger.Info("Recording resumed successfully", "segments", len(session.segments))
 	return nil
 }
 
@@ -180,22 +284,15 @@ func (r *Recorder) StopRecording(ctx context.Context, recordingID string) (io.Re
 		return nil, ErrRecordingNotFound
 	}
 
-	// Cancel the recording context to stop ffmpeg gracefully
-	session.cancel()
-
-	// Wait for the process to finish
-	if err := session.cmd.Wait(); err != nil {
-		// FFmpeg might exit with error when interrupted, which is expected
-		logger.Warn("FFmpeg process ended with error", "error", err)
+	if !session.paused {
+		r.stopSegment(logger, session)
 	}
 
 	// Clean up the session
 	delete(r.recordings, recordingID)
 
-	// Check if file was created
-	if _, err := os.Stat(session.filePath); os.IsNotExist(err) {
-		logger.Error("Recording file not found", "file_path", session.filePath)
-		return nil, ErrRecordingFileNotFound
+	if err := r.joinSegments(ctx, logger, session); err != nil {
+		return nil, err
 	}
 
 	// Open and return the file
@@ -222,49 +319,271 @@ func (r *Recorder) CancelRecording(ctx context.Context, recordingID string) erro
 		return ErrRecordingNotFound
 	}
 
-	// Cancel the recording
-	session.cancel()
-
-	// Wait for process to finish
-	if err := session.cmd.Wait(); err != nil {
-		logger.Warn("FFmpeg process ended with error during cancellation", "error", err)
+	session.liveDiscard.Store(true)
+	if !session.paused {
+		r.stopSegment(logger, session)
 	}
 
 	// Clean up the session
 	delete(r.recordings, recordingID)
 
-	// Remove the file if it exists
-	if err := os.Remove(session.filePath); err != nil && !os.IsNotExist(err) {
-		logger.Warn("Failed to remove recording file", "error", err, "file_path", session.filePath)
+	// Remove the files if they exist
+	for _, path := range append(session.segments, session.filePath) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove recording file", "error", err, "file_path", path)
+		}
 	}
 
 	logger.Info("Recording cancelled successfully")
 	return nil
 }
 
-// buildFFmpegArgs builds the FFmpeg command arguments
-func (r *Recorder) buildFFmpegArgs(outputPath, format string) []string {
+// ListRecordings returns a snapshot of every recording in progress, oldest first
+func (r *Recorder) ListRecordings(ctx context.Context) ([]ports.RecordingStatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]ports.RecordingStatus, 0, len(r.recordings))
+	for recordingID, session := range r.recordings {
+		statuses = append(statuses, session.status(recordingID, now))
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].StartedAt.Before(statuses[j].StartedAt)
+	})
+
+	return statuses, nil
+}
+
+// status reports the session's progress. The duration excludes pauses, and the
+// size is read from disk as ffmpeg writes the segments.
+func (s *recordingSession) status(recordingID string, now time.Time) ports.RecordingStatus {
+	status := ports.RecordingStatus{
+		RecordingID: recordingID,
+		Format:      s.format,
+		InputDevice: s.inputDevice,
+		State:       ports.RecordingStateRecording,
+		StartedAt:   s.startedAt,
+		Duration:    s.recorded,
+	}
+
+	if s.paused {
+		status.State = ports.RecordingStatePaused
+	} else {
+		status.Duration += now.Sub(s.segmentStartedAt)
+	}
+
+	status.BytesWritten = segmentBytes(s.segments)
+
+	return status
+}
+
+// startSegment launches ffmpeg writing the next segment file of a session
+func (r *Recorder) startSegment(ctx context.Context, logger *slog.Logger, recordingID string, session *recordingSession) error {
+	segmentPath := filepath.Join(r.config.TempDir, fmt.Sprintf("%s.part%d.%s", recordingID, len(session.segments), session.format))
+
+	// Create context with the recording time that is left
+	recordCtx, cancel := context.WithTimeout(ctx, r.config.RecordTimeout-session.recorded)
+
+	// Build ffmpeg command
+	args := r.buildFFmpegArgs(segmentPath, session.format, session.options.Preset)
+
+	var watcher *liveSegmentWatcher
+	if session.options.LiveSegmentDuration > 0 {
+		pattern, listPath := livePaths(r.config.TempDir, recordingID, len(session.segments))
+		args = append(args, buildLiveSegmentArgs(pattern, listPath, session.options.LiveSegmentDuration, session.liveNext)...)
+		watcher = &liveSegmentWatcher{
+			recordingID: recordingID,
+			dir:         r.config.TempDir,
+			listPath:    listPath,
+			offset:      session.recorded,
+			nextSeq:     &session.liveNext,
+			onSegment:   session.options.OnLiveSegment,
+			discard:     &session.liveDiscard,
+			logger:      logger,
+			stop:        make(chan struct{}),
+			done:        make(chan struct{}),
+		}
+	}
+
+	// Silences are timed per ffmpeg run, so a pause ends the current one
+	if session.options.SilenceTimeout > 0 {
+		args = append(args, buildSilenceDetectArgs(r.config.SilenceThreshold, session.options.SilenceTimeout)...)
+	}
+
+	cmd := exec.CommandContext(recordCtx, "ffmpeg", args...)
+	if session.options.SilenceTimeout > 0 {
+		cmd.Stderr = &silenceWatcher{onSilence: session.options.OnSilence}
+	}
+
+	// Interrupt rather than kill ffmpeg so it finalizes the file headers,
+	// which keeps every segment playable and joinable
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = segmentStopTimeout
+
+	logger.Info("Starting FFmpeg recording", "file_path", segmentPath, "args", args)
+
+	// Start the recording
+	if err := cmd.Start(); err != nil {
+		cancel()
+		logger.Error("Failed to start FFmpeg", "error", err)
+		return fmt.Errorf("failed to start ffmpeg recording: %w", err)
+	}
+
+	// The watcher owns the session's live sequence number until it is stopped
+	if watcher != nil {
+		go watcher.run()
+	}
+
+	var limits *limitWatcher
+	if session.options.OnLimit != nil {
+		limits = &limitWatcher{
+			maxDuration:    session.options.MaxDuration,
+			maxBytes:       session.options.MaxBytes,
+			recordedBefore: session.recorded,
+			bytesBefore:    segmentBytes(session.segments),
+			segmentPath:    segmentPath,
+			startedAt:      time.Now(),
+			onLimit:        session.options.OnLimit,
+			reached:        &session.limitReached,
+			logger:         logger,
+			stop:           make(chan struct{}),
+			done:           make(chan struct{}),
+		}
+		go limits.run()
+	}
+
+	session.cmd = cmd
+	session.cancel = cancel
+	session.liveWatcher = watcher
+	session.limitWatcher = limits
+	session.segments = append(session.segments, segmentPath)
+	session.segmentStartedAt = time.Now()
+	session.paused = false
+	return nil
+}
+
+// stopSegment stops ffmpeg and waits for the current segment file, and any
+// live segments it closed on shutdown, to be written and delivered
+func (r *Recorder) stopSegment(logger *slog.Logger, session *recordingSession) {
+	if session.limitWatcher != nil {
+		close(session.limitWatcher.stop)
+		<-session.limitWatcher.done
+		session.limitWatcher = nil
+	}
+
+	session.cancel()
+
+	// Wait for the process to finish
+	if err := session.cmd.Wait(); err != nil {
+		// FFmpeg might exit with error when interrupted, which is expected
+		logger.Warn("FFmpeg process ended with error", "error", err)
+	}
+
+	if session.liveWatcher != nil {
+		close(session.liveWatcher.stop)
+		<-session.liveWatcher.done
+		session.liveWatcher = nil
+	}
+
+	session.recorded += time.Since(session.segmentStartedAt)
+	session.cmd = nil
+	session.cancel = nil
+}
+
+// joinSegments produces the session's output file from its segments and removes them
+func (r *Recorder) joinSegments(ctx context.Context, logger *slog.Logger, session *recordingSession) error {
+	var segments []string
+	for _, segment := range session.segments {
+		if _, err := os.Stat(segment); err == nil {
+			segments = append(segments, segment)
+		} else {
+			logger.Warn("Recording segment not found", "file_path", segment)
+		}
+	}
+
+	if len(segments) == 0 {
+		logger.Error("Recording file not found", "file_path", session.filePath)
+		return ErrRecordingFileNotFound
+	}
+
+	if len(segments) == 1 {
+		if err := os.Rename(segments[0], session.filePath); err != nil {
+			logger.Error("Failed to move recording file", "error", err, "file_path", segments[0])
+			return fmt.Errorf("failed to move recording file: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.concatSegments(ctx, segments, session.filePath); err != nil {
+		logger.Error("Failed to join recording segments", "error", err, "segments", len(segments))
+		return err
+	}
+
+	for _, segment := range segments {
+		if err := os.Remove(segment); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove recording segment", "error", err, "file_path", segment)
+		}
+	}
+
+	logger.Info("Recording segments joined", "segments", len(segments), "file_path", session.filePath)
+	return nil
+}
+
+// concatSegments joins segment files without re-encoding using ffmpeg's concat demuxer
+func (r *Recorder) concatSegments(ctx context.Context, segments []string, outputPath string) error {
+	listPath := outputPath + ".segments.txt"
+	if err := os.WriteFile(listPath, []byte(buildConcatList(segments)), 0644); err != nil {
+		return fmt.Errorf("failed to write segment list: %w", err)
+	}
+	defer os.Remove(listPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", buildConcatArgs(listPath, outputPath)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to concatenate recording segments: %w: %s", err, output)
+	}
+
+	return nil
+}
+
+// buildConcatList builds a concat demuxer file list, quoting each path
+func buildConcatList(segments []string) string {
+	var list strings.Builder
+	for _, segment := range segments {
+		list.WriteString("file '")
+		list.WriteString(strings.ReplaceAll(segment, "'", `'\''`))
+		list.WriteString("'\n")
+	}
+	return list.String()
+}
+
+// buildConcatArgs builds the FFmpeg arguments that join the listed segments
+func buildConcatArgs(listPath, outputPath string) []string {
+	return []string{
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-c", "copy",
+		"-y",
+		outputPath,
+	}
+}
+
+// buildFFmpegArgs builds the FFmpeg command arguments for a format and encoding preset
+func (r *Recorder) buildFFmpegArgs(outputPath, format, preset string) []string {
 	audioSubsystem := r.deviceDetector.GetAudioSubsystem()
 	
 	args := []string{
 		"-f", audioSubsystem,
 		"-i", r.config.InputDevice,
-		"-ar", fmt.Sprintf("%d", r.config.SampleRate),
-		"-ac", fmt.Sprintf("%d", r.config.Channels),
-		"-y", // Overwrite output file
-	}
-
-	// Add format-specific options
-	switch format {
-	case "wav":
-		args = append(args, "-acodec", "pcm_s16le")
-	case "mp3":
-		args = append(args, "-acodec", "mp3", "-ab", "128k")
-	default:
-		// Default to WAV
-		args = append(args, "-acodec", "pcm_s16le")
 	}
+	args = append(args, buildSampleArgs(format, preset, r.config.SampleRate, r.config.Channels)...)
+	args = append(args, "-y") // Overwrite output file
 
+	args = append(args, buildCodecArgs(format, preset)...)
 	args = append(args, outputPath)
 	return args
 }
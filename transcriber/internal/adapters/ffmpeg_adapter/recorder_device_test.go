@@ -6,6 +6,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 func TestRecorder_WithDeviceConfiguration(t *testing.T) {
@@ -56,7 +58,7 @@ func TestRecorder_DeviceValidation(t *testing.T) {
 	defer cancel()
 	
 	// This should fail device validation
-	err = recorder.StartRecording(ctx, "test-recording", "wav")
+	err = recorder.StartRecording(ctx, "test-recording", "wav", ports.RecordingOptions{})
 	if err == nil {
 		t.Error("Expected device validation to fail for non-existent device")
 		// Clean up if recording somehow started
@@ -80,7 +82,7 @@ func TestRecorder_DefaultDeviceValidation(t *testing.T) {
 	defer cancel()
 	
 	// This should not perform device validation for "default"
-	err = recorder.StartRecording(ctx, "test-recording", "wav")
+	err = recorder.StartRecording(ctx, "test-recording", "wav", ports.RecordingOptions{})
 	if err != nil && err != ErrFFmpegNotFound {
 		t.Errorf("Unexpected error for default device: %v", err)
 	}
@@ -104,7 +106,7 @@ func TestRecorder_BuildFFmpegArgs_CrossPlatform(t *testing.T) {
 		t.Fatalf("Failed to create recorder: %v", err)
 	}
 	
-	args := recorder.buildFFmpegArgs("/tmp/test.wav", "wav")
+	args := recorder.buildFFmpegArgs("/tmp/test.wav", "wav", "")
 	
 	// Check that args contain expected elements
 	expectedElements := []string{"-f", "test-device", "-ar", "44100", "-ac", "1", "-y"}
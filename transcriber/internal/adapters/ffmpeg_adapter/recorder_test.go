@@ -2,10 +2,13 @@ package ffmpeg_adapter
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 	"testing"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 func TestNewRecorder(t *testing.T) {
@@ -74,7 +77,7 @@ func TestBuildFFmpegArgs(t *testing.T) {
 		t.Fatalf("Failed to create recorder: %v", err)
 	}
 	
-	args := recorder.buildFFmpegArgs("/tmp/test.wav", "wav")
+	args := recorder.buildFFmpegArgs("/tmp/test.wav", "wav", "")
 	
 	// Check that essential arguments are present
 	expectedArgs := []string{"-f", "pulse", "-i", "default", "-ar", "44100", "-ac", "1", "-y", "-acodec", "pcm_s16le", "/tmp/test.wav"}
@@ -114,4 +117,202 @@ func TestRecordingErrors(t *testing.T) {
 	if err != ErrRecordingNotFound {
 		t.Errorf("Expected ErrRecordingNotFound, got %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestListRecordings(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	tempDir := t.TempDir()
+
+	filePath := tempDir + "/rec-2.wav"
+	if err := os.WriteFile(filePath, make([]byte, 1024), 0644); err != nil {
+		t.Fatalf("Failed to write recording file: %v", err)
+	}
+
+	now := time.Now()
+	recorder := &Recorder{
+		config: RecorderConfig{TempDir: tempDir},
+		logger: logger,
+		recordings: map[string]*recordingSession{
+			"rec-2": {segments: []string{filePath}, format: "wav", inputDevice: "hw:1", startedAt: now.Add(-time.Minute), segmentStartedAt: now.Add(-time.Minute)},
+			"rec-1": {segments: []string{tempDir + "/rec-1.wav"}, format: "mp3", inputDevice: "default", startedAt: now.Add(-time.Hour), segmentStartedAt: now.Add(-time.Hour)},
+		},
+	}
+
+	statuses, err := recorder.ListRecordings(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list recordings: %v", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 recordings, got %d", len(statuses))
+	}
+
+	// Oldest recording first
+	if statuses[0].RecordingID != "rec-1" || statuses[1].RecordingID != "rec-2" {
+		t.Errorf("Expected recordings ordered by start time, got %s, %s", statuses[0].RecordingID, statuses[1].RecordingID)
+	}
+
+	// ffmpeg has not written rec-1 yet
+	if statuses[0].BytesWritten != 0 {
+		t.Errorf("Expected 0 bytes for missing file, got %d", statuses[0].BytesWritten)
+	}
+
+	if statuses[1].BytesWritten != 1024 {
+		t.Errorf("Expected 1024 bytes, got %d", statuses[1].BytesWritten)
+	}
+
+	if statuses[1].InputDevice != "hw:1" || statuses[1].Format != "wav" {
+		t.Errorf("Unexpected status: %+v", statuses[1])
+	}
+
+	if statuses[1].Duration < time.Minute {
+		t.Errorf("Expected duration of at least 1m, got %v", statuses[1].Duration)
+	}
+}
+
+func TestListRecordings_PausedExcludesPauseTime(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	tempDir := t.TempDir()
+
+	segments := []string{tempDir + "/rec-1.part0.wav", tempDir + "/rec-1.part1.wav"}
+	for _, segment := range segments {
+		if err := os.WriteFile(segment, make([]byte, 512), 0644); err != nil {
+			t.Fatalf("Failed to write segment: %v", err)
+		}
+	}
+
+	recorder := &Recorder{
+		logger: logger,
+		recordings: map[string]*recordingSession{
+			"rec-1": {segments: segments, startedAt: time.Now().Add(-time.Hour), recorded: 10 * time.Minute, paused: true},
+		},
+	}
+
+	statuses, _ := recorder.ListRecordings(context.Background())
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 recording, got %d", len(statuses))
+	}
+
+	if statuses[0].State != ports.RecordingStatePaused {
+		t.Errorf("Expected state paused, got %s", statuses[0].State)
+	}
+
+	if statuses[0].Duration != 10*time.Minute {
+		t.Errorf("Expected duration 10m, got %v", statuses[0].Duration)
+	}
+
+	if statuses[0].BytesWritten != 1024 {
+		t.Errorf("Expected 1024 bytes across segments, got %d", statuses[0].BytesWritten)
+	}
+}
+
+func TestPauseResumeErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	recorder := &Recorder{
+		logger: logger,
+		recordings: map[string]*recordingSession{
+			"paused": {paused: true},
+		},
+	}
+
+	ctx := context.Background()
+
+	if err := recorder.PauseRecording(ctx, "non-existent"); err != ErrRecordingNotFound {
+		t.Errorf("Expected ErrRecordingNotFound, got %v", err)
+	}
+
+	if err := recorder.ResumeRecording(ctx, "non-existent"); err != ErrRecordingNotFound {
+		t.Errorf("Expected ErrRecordingNotFound, got %v", err)
+	}
+
+	if err := recorder.PauseRecording(ctx, "paused"); err != ErrRecordingPaused {
+		t.Errorf("Expected ErrRecordingPaused, got %v", err)
+	}
+
+	// The recording has used up the recorder's maximum duration
+	if err := recorder.ResumeRecording(ctx, "paused"); err != ErrRecordingTimeExhausted {
+		t.Errorf("Expected ErrRecordingTimeExhausted, got %v", err)
+	}
+}
+
+func TestStopRecording_PausedSingleSegment(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	tempDir := t.TempDir()
+
+	segment := tempDir + "/rec-1.part0.wav"
+	if err := os.WriteFile(segment, []byte("segment audio"), 0644); err != nil {
+		t.Fatalf("Failed to write segment: %v", err)
+	}
+
+	recorder := &Recorder{
+		logger: logger,
+		recordings: map[string]*recordingSession{
+			"rec-1": {filePath: tempDir + "/rec-1.wav", segments: []string{segment}, paused: true},
+		},
+	}
+
+	reader, err := recorder.StopRecording(context.Background(), "rec-1")
+	if err != nil {
+		t.Fatalf("Failed to stop recording: %v", err)
+	}
+
+	data, _ := io.ReadAll(reader)
+	if string(data) != "segment audio" {
+		t.Errorf("Expected segment audio, got %q", string(data))
+	}
+
+	if _, err := os.Stat(segment); !os.IsNotExist(err) {
+		t.Error("Expected segment to be moved to the recording file")
+	}
+}
+
+func TestCancelRecording_PausedRemovesSegments(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	tempDir := t.TempDir()
+
+	segments := []string{tempDir + "/rec-1.part0.wav", tempDir + "/rec-1.part1.wav"}
+	for _, segment := range segments {
+		os.WriteFile(segment, []byte("audio"), 0644)
+	}
+
+	recorder := &Recorder{
+		logger: logger,
+		recordings: map[string]*recordingSession{
+			"rec-1": {filePath: tempDir + "/rec-1.wav", segments: segments, paused: true},
+		},
+	}
+
+	if err := recorder.CancelRecording(context.Background(), "rec-1"); err != nil {
+		t.Fatalf("Failed to cancel recording: %v", err)
+	}
+
+	for _, segment := range segments {
+		if _, err := os.Stat(segment); !os.IsNotExist(err) {
+			t.Errorf("Expected segment %s to be removed", segment)
+		}
+	}
+}
+
+func TestBuildConcatList(t *testing.T) {
+	list := buildConcatList([]string{"/tmp/speakr/a.part0.wav", "/tmp/it's/a.part1.wav"})
+
+	expected := "file '/tmp/speakr/a.part0.wav'\nfile '/tmp/it'\\''s/a.part1.wav'\n"
+	if list != expected {
+		t.Errorf("Expected %q, got %q", expected, list)
+	}
+}
+
+func TestBuildConcatArgs(t *testing.T) {
+	args := buildConcatArgs("/tmp/list.txt", "/tmp/out.wav")
+
+	expected := []string{"-f", "concat", "-safe", "0", "-i", "/tmp/list.txt", "-c", "copy", "-y", "/tmp/out.wav"}
+	if len(args) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Expected arg %d to be %s, got %s", i, expected[i], args[i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ffmpeg_adapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// TranscoderConfig holds configuration for the audio transcoder
+type TranscoderConfig struct {
+	TempDir string
+}
+
+// TranscoderOption is a functional option for configuring the transcoder
+type TranscoderOption func(*TranscoderConfig)
+
+// WithTranscodeTempDir sets the directory used for intermediate audio files
+func WithTranscodeTempDir(dir string) TranscoderOption {
+	return func(c *TranscoderConfig) {
+		c.TempDir = dir
+	}
+}
+
+// Transcoder implements the AudioTranscoder port with ffmpeg
+type Transcoder struct {
+	config TranscoderConfig
+	logger *slog.Logger
+}
+
+// NewTranscoder creates a new ffmpeg audio transcoder
+func NewTranscoder(logger *slog.Logger, opts ...TranscoderOption) (*Transcoder, error) {
+	config := TranscoderConfig{
+		TempDir: "/tmp/speakr",
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if err := os.MkdirAll(config.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, ErrFFmpegNotFound
+	}
+
+	return &Transcoder{
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// Transcode re-encodes the audio into the target format with the given preset
+func (t *Transcoder) Transcode(ctx context.Context, audioData io.Reader, format, targetFormat, preset string) (io.Reader, error) {
+	logger := t.logger.With("format", format, "target_format", targetFormat, "preset", preset)
+
+	if err := ValidateEncoding(targetFormat, preset); err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp(t.config.TempDir, "transcode-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcoding directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	sourcePath := filepath.Join(workDir, "source."+format)
+	size, err := spoolAudio(sourcePath, audioData)
+	if err != nil {
+		return nil, err
+	}
+
+	outputPath := filepath.Join(workDir, "transcoded."+targetFormat)
+	args := buildTranscodeArgs(sourcePath, outputPath, targetFormat, preset)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg transcoding failed", "error", err, "args", args)
+		return nil, fmt.Errorf("failed to transcode audio: %w: %s", err, output)
+	}
+
+	transcoded, err := os.ReadFile(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcoded audio: %w", err)
+	}
+
+	logger.Info("Audio transcoded", "size", size, "transcoded_size", len(transcoded))
+	return bytes.NewReader(transcoded), nil
+}
+
+// buildTranscodeArgs builds the ffmpeg arguments that re-encode a file, keeping the
+// source's sample rate and channels unless the preset or codec sets them
+func buildTranscodeArgs(sourcePath, outputPath, format, preset string) []string {
+	args := []string{"-v", "error", "-i", sourcePath, "-vn"}
+	args = append(args, buildSampleArgs(format, preset, 0, 0)...)
+	args = append(args, buildCodecArgs(format, preset)...)
+	return append(args, "-y", outputPath)
+}
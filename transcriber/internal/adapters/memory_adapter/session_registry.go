@@ -0,0 +1,73 @@
+package memory_adapter
+
+import (
+	"context"
+	"sync"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// SessionRegistry implements the SessionRegistry port in process memory.
+// Sessions are lost on restart; use the NATS registry when that matters.
+type SessionRegistry struct {
+	sessions map[string]ports.RecordingSession
+	mu       sync.RWMutex
+}
+
+// NewSessionRegistry creates a new in-memory session registry
+func NewSessionRegistry() *SessionRegistry {
+	return &SessionRegistry{
+		sessions: make(map[string]ports.RecordingSession),
+	}
+}
+
+// SaveSession creates or replaces a recording session
+func (r *SessionRegistry) SaveSession(ctx context.Context, session ports.RecordingSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.RecordingID] = copySession(session)
+	return nil
+}
+
+// GetSession returns a copy of the recording session, or nil if it is unknown
+func (r *SessionRegistry) GetSession(ctx context.Context, recordingID string) (*ports.RecordingSession, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[recordingID]
+	if !exists {
+		return nil, nil
+	}
+
+	session = copySession(session)
+	return &session, nil
+}
+
+// DeleteSession removes a recording session
+func (r *SessionRegistry) DeleteSession(ctx context.Context, recordingID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, recordingID)
+	return nil
+}
+
+// copySession detaches the tags and metadata so callers cannot mutate stored state
+func copySession(session ports.RecordingSession) ports.RecordingSession {
+	if session.Tags != nil {
+		session.Tags = append([]string(nil), session.Tags...)
+	}
+	if session.Metadata != nil {
+		metadata := make(map[string]interface{}, len(session.Metadata))
+		for k, v := range session.Metadata {
+			metadata[k] = v
+		}
+		session.Metadata = metadata
+	}
+	if session.StoppedAt != nil {
+		stoppedAt := *session.StoppedAt
+		session.StoppedAt = &stoppedAt
+	}
+	return session
+}
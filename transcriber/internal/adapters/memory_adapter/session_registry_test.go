@@ -0,0 +1,78 @@
+package memory_adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestSessionRegistry_SaveAndGet(t *testing.T) {
+	registry := NewSessionRegistry()
+	ctx := context.Background()
+
+	session := ports.RecordingSession{
+		RecordingID: "rec-1",
+		Format:      "wav",
+		Tags:        []string{"project-x"},
+		Metadata:    map[string]interface{}{"triggered_by": "cli-adapter"},
+		Caller:      "cli-adapter",
+		StartedAt:   time.Now(),
+	}
+
+	if err := registry.SaveSession(ctx, session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	got, err := registry.GetSession(ctx, "rec-1")
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	if got == nil {
+		t.Fatal("Expected session, got nil")
+	}
+
+	if got.Format != "wav" || got.Caller != "cli-adapter" {
+		t.Errorf("Unexpected session: %+v", got)
+	}
+
+	// Mutating the returned copy must not change stored state
+	got.Tags[0] = "mutated"
+	got.Metadata["triggered_by"] = "mutated"
+
+	again, _ := registry.GetSession(ctx, "rec-1")
+	if again.Tags[0] != "project-x" || again.Metadata["triggered_by"] != "cli-adapter" {
+		t.Errorf("Stored session was mutated through a returned copy: %+v", again)
+	}
+}
+
+func TestSessionRegistry_UnknownSession(t *testing.T) {
+	registry := NewSessionRegistry()
+
+	got, err := registry.GetSession(context.Background(), "unknown")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got != nil {
+		t.Errorf("Expected nil session, got %+v", got)
+	}
+}
+
+func TestSessionRegistry_Delete(t *testing.T) {
+	registry := NewSessionRegistry()
+	ctx := context.Background()
+
+	registry.SaveSession(ctx, ports.RecordingSession{RecordingID: "rec-1"})
+
+	if err := registry.DeleteSession(ctx, "rec-1"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+
+	got, _ := registry.GetSession(ctx, "rec-1")
+	if got != nil {
+		t.Errorf("Expected session to be deleted, got %+v", got)
+	}
+}
@@ -0,0 +1,106 @@
+package memory_adapter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// TranscriptionCacheConfig holds configuration for the in-memory transcription cache
+type TranscriptionCacheConfig struct {
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// TranscriptionCacheOption is a functional option for configuring the transcription cache
+type TranscriptionCacheOption func(*TranscriptionCacheConfig)
+
+// WithCacheTTL sets how long a transcript is reused
+func WithCacheTTL(ttl time.Duration) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.TTL = ttl
+	}
+}
+
+// WithMaxEntries bounds the number of cached transcripts; the oldest is evicted first
+func WithMaxEntries(maxEntries int) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.MaxEntries = maxEntries
+	}
+}
+
+// cachedTranscription is a transcript and when it was cached
+type cachedTranscription struct {
+	result   ports.TranscriptionResult
+	cachedAt time.Time
+}
+
+// TranscriptionCache implements the TranscriptionCache port in process memory.
+// Transcripts are lost on restart; use the NATS or Postgres cache when that matters.
+type TranscriptionCache struct {
+	config  TranscriptionCacheConfig
+	now     func() time.Time
+	entries map[string]cachedTranscription
+	order   []string // keys, oldest first
+	mu      sync.Mutex
+}
+
+// NewTranscriptionCache creates a new in-memory transcription cache
+func NewTranscriptionCache(opts ...TranscriptionCacheOption) *TranscriptionCache {
+	config := TranscriptionCacheConfig{
+		TTL:        30 * 24 * time.Hour,
+		MaxEntries: 1000,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &TranscriptionCache{
+		config:  config,
+		now:     time.Now,
+		entries: make(map[string]cachedTranscription),
+	}
+}
+
+// GetTranscription returns a copy of the cached transcript, or nil if there is none
+// or it has expired
+func (c *TranscriptionCache) GetTranscription(ctx context.Context, key string) (*ports.TranscriptionResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists || c.now().Sub(entry.cachedAt) > c.config.TTL {
+		return nil, nil
+	}
+
+	result := copyResult(entry.result)
+	return &result, nil
+}
+
+// SaveTranscription caches a copy of the transcript, evicting the oldest when full
+func (c *TranscriptionCache) SaveTranscription(ctx context.Context, key string, result *ports.TranscriptionResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.entries[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = cachedTranscription{result: copyResult(*result), cachedAt: c.now()}
+
+	for c.config.MaxEntries > 0 && len(c.order) > c.config.MaxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	return nil
+}
+
+// copyResult detaches the segments and words so callers cannot mutate stored state
+func copyResult(result ports.TranscriptionResult) ports.TranscriptionResult {
+	result.Segments = append([]ports.TranscriptSegment(nil), result.Segments...)
+	result.Words = append([]ports.TranscriptWord(nil), result.Words...)
+	return result
+}
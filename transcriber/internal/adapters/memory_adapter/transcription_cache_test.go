@@ -0,0 +1,73 @@
+package memory_adapter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestTranscriptionCache_SaveAndGet(t *testing.T) {
+	cache := NewTranscriptionCache()
+	ctx := context.Background()
+
+	result := &ports.TranscriptionResult{
+		Text:     "hello",
+		Segments: []ports.TranscriptSegment{{Start: 0, End: 1, Text: "hello"}},
+	}
+	if err := cache.SaveTranscription(ctx, "key-1", result); err != nil {
+		t.Fatalf("Failed to save transcription: %v", err)
+	}
+
+	// Changes to the saved or returned result must not reach the cache
+	result.Segments[0].Text = "changed"
+
+	got, err := cache.GetTranscription(ctx, "key-1")
+	if err != nil {
+		t.Fatalf("Failed to get transcription: %v", err)
+	}
+	if got == nil || got.Text != "hello" || got.Segments[0].Text != "hello" {
+		t.Fatalf("Unexpected transcription: %+v", got)
+	}
+
+	got.Segments[0].Text = "changed"
+	if again, _ := cache.GetTranscription(ctx, "key-1"); again.Segments[0].Text != "hello" {
+		t.Errorf("Expected the cached segments to be detached, got %+v", again.Segments)
+	}
+
+	if got, err := cache.GetTranscription(ctx, "unknown"); err != nil || got != nil {
+		t.Errorf("Expected a miss for an unknown key, got %+v, %v", got, err)
+	}
+}
+
+func TestTranscriptionCache_Expires(t *testing.T) {
+	cache := NewTranscriptionCache(WithCacheTTL(time.Hour))
+	ctx := context.Background()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.SaveTranscription(ctx, "key-1", &ports.TranscriptionResult{Text: "hello"})
+
+	now = now.Add(time.Hour + time.Second)
+	if got, _ := cache.GetTranscription(ctx, "key-1"); got != nil {
+		t.Errorf("Expected an expired transcription to miss, got %+v", got)
+	}
+}
+
+func TestTranscriptionCache_EvictsOldest(t *testing.T) {
+	cache := NewTranscriptionCache(WithMaxEntries(2))
+	ctx := context.Background()
+
+	for _, key := range []string{"key-1", "key-2", "key-3"} {
+		cache.SaveTranscription(ctx, key, &ports.TranscriptionResult{Text: key})
+	}
+
+	if got, _ := cache.GetTranscription(ctx, "key-1"); got != nil {
+		t.Errorf("Expected the oldest transcription to be evicted, got %+v", got)
+	}
+	if got, _ := cache.GetTranscription(ctx, "key-3"); got == nil {
+		t.Error("Expected the newest transcription to be kept")
+	}
+}
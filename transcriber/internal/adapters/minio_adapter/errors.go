@@ -1,14 +1,18 @@
 package minio_adapter
 
-import "errors"
+import (
+	"fmt"
+
+	"speakr/transcriber/internal/ports"
+)
 
 // Custom error types for predictable failures
 var (
-	ErrBucketNotFound        = errors.New("specified bucket does not exist")
-	ErrBucketCreationFailed  = errors.New("failed to create bucket")
-	ErrObjectNotFound        = errors.New("specified object does not exist")
-	ErrAccessDenied          = errors.New("access denied to MinIO resource")
-	ErrInsufficientStorage   = errors.New("insufficient storage space available")
-	ErrConnectionFailed      = errors.New("failed to connect to MinIO server")
-	ErrInvalidCredentials    = errors.New("invalid MinIO credentials")
+	ErrBucketNotFound        = fmt.Errorf("specified bucket does not exist: %w", ports.ErrStorageUnavailable)
+	ErrBucketCreationFailed  = fmt.Errorf("failed to create bucket: %w", ports.ErrStorageUnavailable)
+	ErrObjectNotFound        = fmt.Errorf("specified object does not exist: %w", ports.ErrObjectNotFound)
+	ErrAccessDenied          = fmt.Errorf("access denied to MinIO resource: %w", ports.ErrStorageAccessDenied)
+	ErrInsufficientStorage   = fmt.Errorf("insufficient storage space available: %w", ports.ErrInsufficientStorage)
+	ErrConnectionFailed      = fmt.Errorf("failed to connect to MinIO server: %w", ports.ErrStorageUnavailable)
+	ErrInvalidCredentials    = fmt.Errorf("invalid MinIO credentials: %w", ports.ErrStorageAccessDenied)
 )
\ No newline at end of file
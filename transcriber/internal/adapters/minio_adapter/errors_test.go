@@ -0,0 +1,29 @@
+package minio_adapter
+
+import (
+	"errors"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestErrors_WrapPortSentinels(t *testing.T) {
+	tests := []struct {
+		err  error
+		port error
+	}{
+		{ErrBucketNotFound, ports.ErrStorageUnavailable},
+		{ErrBucketCreationFailed, ports.ErrStorageUnavailable},
+		{ErrConnectionFailed, ports.ErrStorageUnavailable},
+		{ErrObjectNotFound, ports.ErrObjectNotFound},
+		{ErrAccessDenied, ports.ErrStorageAccessDenied},
+		{ErrInvalidCredentials, ports.ErrStorageAccessDenied},
+		{ErrInsufficientStorage, ports.ErrInsufficientStorage},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.port) {
+			t.Errorf("Expected %q to wrap %q", tt.err, tt.port)
+		}
+	}
+}
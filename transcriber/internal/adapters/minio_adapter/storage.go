@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"path"
 	"strings"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // StorageConfig holds configuration for MinIO storage
@@ -82,10 +84,15 @@ func NewStorage(logger *slog.Logger, opts ...StorageOption) (*Storage, error) {
 		opt(&config)
 	}
 
-	// Initialize MinIO client
+	// Initialize MinIO client, tracing each request to the server
+	transport, err := minio.DefaultTransport(config.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO transport: %w", err)
+	}
 	client, err := minio.New(config.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
-		Secure: config.UseSSL,
+		Creds:     credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure:    config.UseSSL,
+		Transport: otelhttp.NewTransport(transport),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
@@ -105,17 +112,59 @@ func NewStorage(logger *slog.Logger, opts ...StorageOption) (*Storage, error) {
 	return storage, nil
 }
 
+// formatMetadataKey is the user metadata key holding an object's audio format
+const formatMetadataKey = "Format"
+
+// contentTypes maps audio formats to the content type stored with the object
+var contentTypes = map[string]string{
+	"wav":  "audio/wav",
+	"mp3":  "audio/mpeg",
+	"flac": "audio/flac",
+	"ogg":  "audio/ogg",
+	"m4a":  "audio/mp4",
+	"webm": "audio/webm",
+}
+
+// subtitleContentTypes maps subtitle formats to the content type stored with the object
+var subtitleContentTypes = map[string]string{
+	"srt": "application/x-subrip",
+	"vtt": "text/vtt",
+}
+
+// contentTypeFor returns the content type for an audio or subtitle format
+func contentTypeFor(format string) string {
+	if contentType, ok := contentTypes[format]; ok {
+		return contentType
+	}
+	if contentType, ok := subtitleContentTypes[format]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// isSubtitleObject reports whether an object key holds a subtitle file rather than audio
+func isSubtitleObject(objectName string) bool {
+	_, ok := subtitleContentTypes[strings.TrimPrefix(path.Ext(objectName), ".")]
+	return ok
+}
+
+// audioObjectName returns the object key for a recording in the given format
+func audioObjectName(recordingID, format string) string {
+	return fmt.Sprintf("recordings/%s.%s", recordingID, format)
+}
+
 // StoreAudio stores audio data in MinIO and returns the object path
-func (s *Storage) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader) (string, error) {
-	logger := s.logger.With("recording_id", recordingID, "bucket", s.config.BucketName)
+func (s *Storage) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+	logger := s.logger.With("recording_id", recordingID, "bucket", s.config.BucketName, "format", format)
 
-	objectName := fmt.Sprintf("recordings/%s.wav", recordingID)
+	objectName := audioObjectName(recordingID, format)
 
 	logger.Info("Storing audio file", "object_name", objectName)
 
 	// Upload the audio file
 	info, err := s.client.PutObject(ctx, s.config.BucketName, objectName, audioData, -1, minio.PutObjectOptions{
-		ContentType: "audio/wav",
+		ContentType:  contentTypeFor(format),
+		UserMetadata: map[string]string{formatMetadataKey: format},
 	})
 	if err != nil {
 		logger.Error("Failed to upload audio file", "error", err)
@@ -143,11 +192,48 @@ func (s *Storage) StoreAudio(ctx context.Context, recordingID string, audioData
 	return filePath, nil
 }
 
-// RetrieveAudio retrieves audio data from MinIO
-func (s *Storage) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, error) {
+// StoreSubtitle stores a subtitle file next to the recording's audio and returns the object path
+func (s *Storage) StoreSubtitle(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error) {
+	logger := s.logger.With("recording_id", recordingID, "bucket", s.config.BucketName, "subtitle_format", format)
+
+	objectName := audioObjectName(recordingID, format)
+
+	logger.Info("Storing subtitle file", "object_name", objectName)
+
+	info, err := s.client.PutObject(ctx, s.config.BucketName, objectName, subtitleData, -1, minio.PutObjectOptions{
+		ContentType: contentTypeFor(format),
+	})
+	if err != nil {
+		logger.Error("Failed to upload subtitle file", "error", err)
+
+		if strings.Contains(err.Error(), "NoSuchBucket") {
+			return "", ErrBucketNotFound
+		}
+		if strings.Contains(err.Error(), "AccessDenied") {
+			return "", ErrAccessDenied
+		}
+		if strings.Contains(err.Error(), "InsufficientStorage") {
+			return "", ErrInsufficientStorage
+		}
+
+		return "", fmt.Errorf("failed to store subtitle file: %w", err)
+	}
+
+	filePath := fmt.Sprintf("s3://%s/%s", s.config.BucketName, objectName)
+	logger.Info("Subtitle file stored successfully", "file_path", filePath, "size", info.Size)
+
+	return filePath, nil
+}
+
+// RetrieveAudio retrieves audio data and its format from MinIO
+func (s *Storage) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, string, error) {
 	logger := s.logger.With("recording_id", recordingID, "bucket", s.config.BucketName)
 
-	objectName := fmt.Sprintf("recordings/%s.wav", recordingID)
+	objectName, err := s.findAudioObject(ctx, recordingID)
+	if err != nil {
+		logger.Error("Failed to look up audio file", "error", err)
+		return nil, "", err
+	}
 
 	logger.Info("Retrieving audio file", "object_name", objectName)
 
@@ -158,27 +244,84 @@ func (s *Storage) RetrieveAudio(ctx context.Context, recordingID string) (io.Rea
 		
 		// Check for specific error types
 		if strings.Contains(err.Error(), "NoSuchKey") {
-			return nil, ErrObjectNotFound
+			return nil, "", ErrObjectNotFound
 		}
 		if strings.Contains(err.Error(), "NoSuchBucket") {
-			return nil, ErrBucketNotFound
+			return nil, "", ErrBucketNotFound
 		}
 		if strings.Contains(err.Error(), "AccessDenied") {
-			return nil, ErrAccessDenied
+			return nil, "", ErrAccessDenied
 		}
 		
-		return nil, fmt.Errorf("failed to retrieve audio file: %w", err)
+		return nil, "", fmt.Errorf("failed to retrieve audio file: %w", err)
 	}
 
 	// Verify the object exists by getting its info
-	_, err = object.Stat()
+	info, err := object.Stat()
 	if err != nil {
 		logger.Error("Audio file not found", "error", err)
-		return nil, ErrObjectNotFound
+		return nil, "", ErrObjectNotFound
 	}
 
-	logger.Info("Audio file retrieved successfully")
-	return object, nil
+	format := formatFromObject(objectName, info.UserMetadata)
+
+	logger.Info("Audio file retrieved successfully", "format", format)
+	return object, format, nil
+}
+
+// findAudioObject returns the key of a recording's audio object, whatever its format.
+// Subtitle files share the prefix and are skipped. When several objects match, the
+// one stored with its format in metadata wins. Objects stored before formats were
+// tracked are always .wav, which is the fallback.
+func (s *Storage) findAudioObject(ctx context.Context, recordingID string) (string, error) {
+	prefix := fmt.Sprintf("recordings/%s.", recordingID)
+
+	// Cancelling stops the listing goroutine if the listing is not read to the end
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var keys []string
+	for object := range s.client.ListObjects(listCtx, s.config.BucketName, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			if strings.Contains(object.Err.Error(), "NoSuchBucket") {
+				return "", ErrBucketNotFound
+			}
+			if strings.Contains(object.Err.Error(), "AccessDenied") {
+				return "", ErrAccessDenied
+			}
+			return "", fmt.Errorf("failed to list audio files: %w", object.Err)
+		}
+		if isSubtitleObject(object.Key) {
+			continue
+		}
+		keys = append(keys, object.Key)
+	}
+
+	switch len(keys) {
+	case 0:
+		return audioObjectName(recordingID, "wav"), nil
+	case 1:
+		return keys[0], nil
+	}
+
+	for _, key := range keys {
+		info, err := s.client.StatObject(ctx, s.config.BucketName, key, minio.StatObjectOptions{})
+		if err != nil {
+			continue
+		}
+		if info.UserMetadata[formatMetadataKey] != "" {
+			return key, nil
+		}
+	}
+	return keys[0], nil
+}
+
+// formatFromObject reads an object's audio format from its metadata, falling back to the key's extension
+func formatFromObject(objectName string, userMetadata map[string]string) string {
+	if format := userMetadata[formatMetadataKey]; format != "" {
+		return format
+	}
+	return strings.TrimPrefix(path.Ext(objectName), ".")
 }
 
 // ensureBucketExists checks if the bucket exists and creates it if necessary
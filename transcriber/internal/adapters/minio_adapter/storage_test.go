@@ -80,28 +80,72 @@ func TestStorageOperations(t *testing.T) {
 	audioData := strings.NewReader("mock audio data for testing")
 	
 	// Test storing audio
-	filePath, err := storage.StoreAudio(ctx, recordingID, audioData)
+	filePath, err := storage.StoreAudio(ctx, recordingID, audioData, "mp3")
 	if err != nil {
 		t.Fatalf("Failed to store audio: %v", err)
 	}
 	
-	if !strings.Contains(filePath, recordingID) {
-		t.Errorf("File path should contain recording ID, got: %s", filePath)
+	if !strings.HasSuffix(filePath, recordingID+".mp3") {
+		t.Errorf("File path should end with the recording ID and format, got: %s", filePath)
 	}
 	
 	// Test retrieving audio
-	reader, err := storage.RetrieveAudio(ctx, recordingID)
+	reader, format, err := storage.RetrieveAudio(ctx, recordingID)
 	if err != nil {
 		t.Fatalf("Failed to retrieve audio: %v", err)
 	}
 	
+	if format != "mp3" {
+		t.Errorf("Expected format 'mp3', got %s", format)
+	}
+	
 	if reader == nil {
 		t.Error("Retrieved reader should not be nil")
 	}
 	
 	// Test retrieving non-existent audio
-	_, err = storage.RetrieveAudio(ctx, "non-existent-recording")
+	_, _, err = storage.RetrieveAudio(ctx, "non-existent-recording")
 	if err != ErrObjectNotFound {
 		t.Errorf("Expected ErrObjectNotFound, got %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestContentTypeFor(t *testing.T) {
+	tests := map[string]string{
+		"wav":     "audio/wav",
+		"mp3":     "audio/mpeg",
+		"flac":    "audio/flac",
+		"ogg":     "audio/ogg",
+		"m4a":     "audio/mp4",
+		"webm":    "audio/webm",
+		"srt":     "application/x-subrip",
+		"vtt":     "text/vtt",
+		"unknown": "application/octet-stream",
+	}
+
+	for format, expected := range tests {
+		if got := contentTypeFor(format); got != expected {
+			t.Errorf("contentTypeFor(%q) = %s, want %s", format, got, expected)
+		}
+	}
+}
+
+func TestFormatFromObject(t *testing.T) {
+	if got := formatFromObject("recordings/abc.mp3", map[string]string{"Format": "flac"}); got != "flac" {
+		t.Errorf("Expected metadata format 'flac', got %s", got)
+	}
+
+	if got := formatFromObject("recordings/abc.wav", nil); got != "wav" {
+		t.Errorf("Expected extension format 'wav', got %s", got)
+	}
+}
+
+func TestIsSubtitleObject(t *testing.T) {
+	if !isSubtitleObject("recordings/abc.srt") || !isSubtitleObject("recordings/abc.vtt") {
+		t.Error("Expected .srt and .vtt objects to be subtitles")
+	}
+
+	if isSubtitleObject("recordings/abc.wav") {
+		t.Error("Expected .wav object not to be a subtitle")
+	}
+}
@@ -0,0 +1,361 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+// DeadLetterStream keeps the messages published to the dead-letter subjects until
+// they are replayed
+const DeadLetterStream = "SPEAKR_DLQ"
+
+// deadLetterPrefix is prepended to the subject of a failed message
+const deadLetterPrefix = "speakr.dlq."
+
+// Admin commands for the dead-letter queue, answered by one transcriber of the
+// deadLetterQueueGroup. They are kept out of speakr.command.> so the command stream
+// does not store them.
+const (
+	deadLetterListSubject   = "speakr.admin.dlq.list"
+	deadLetterGetSubject    = "speakr.admin.dlq.get"
+	deadLetterReplaySubject = "speakr.admin.dlq.replay"
+	deadLetterQueueGroup    = "speakr-dlq-admin"
+)
+
+// deadLetterSubjects are the admin commands of the dead-letter queue
+var deadLetterSubjects = []string{deadLetterListSubject, deadLetterGetSubject, deadLetterReplaySubject}
+
+// defaultDeadLetterLimit is how many entries dlq.list returns unless asked otherwise
+const defaultDeadLetterLimit = 50
+
+// deadLetterReadTimeout bounds the wait for each entry dlq.list reads from the stream
+const deadLetterReadTimeout = 5 * time.Second
+
+// deadLetterHeaderAllowance is left of the server's max payload for the trace headers
+// of a dead letter
+const deadLetterHeaderAllowance = 4096
+
+// Errors returned by the dead-letter admin commands
+var (
+	ErrDeadLetterUnavailable = errors.New("dead-letter stream unavailable")
+	ErrDeadLetterNotFound    = errors.New("dead-letter entry not found")
+	ErrDeadLetterTruncated   = errors.New("dead-letter payload was truncated")
+)
+
+// DeadLetter is a message that could not be handled, published on
+// speakr.dlq.<original subject>. Truncated is set when the payload had to be cut
+// down to fit the server's max payload.
+type DeadLetter struct {
+	Subject       string    `json:"subject"`
+	Payload       string    `json:"payload,omitempty"`
+	Truncated     bool      `json:"truncated,omitempty"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	Service       string    `json:"service"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+// DeadLetterEntry is a dead letter as stored in the dead-letter stream
+type DeadLetterEntry struct {
+	Sequence uint64 `json:"sequence"`
+	DeadLetter
+}
+
+// DeadLetterListReply answers dlq.list; dead_letters is always present, even when empty
+type DeadLetterListReply struct {
+	CommandReply
+	DeadLetters []DeadLetterEntry `json:"dead_letters"`
+}
+
+// DeadLetterReply answers dlq.get and dlq.replay
+type DeadLetterReply struct {
+	CommandReply
+	DeadLetter *DeadLetterEntry `json:"dead_letter,omitempty"`
+}
+
+// DeadLetterQueue publishes failed messages to their dead-letter subject and runs
+// the admin commands that inspect and replay them
+type DeadLetterQueue struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	service string
+	logger  *slog.Logger
+}
+
+// NewDeadLetterQueue creates a dead-letter queue for a service. Without a JetStream
+// context dead letters are still published, but cannot be listed or replayed.
+func NewDeadLetterQueue(conn *nats.Conn, js nats.JetStreamContext, service string, logger *slog.Logger) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		conn:    conn,
+		js:      js,
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Publish sends a failed message to speakr.dlq.<subject>. Failures are logged, as
+// there is nowhere left to send the message.
+func (q *DeadLetterQueue) Publish(ctx context.Context, subject string, payload []byte, cause error, attempts int) {
+	correlationID := ports.CorrelationID(ctx)
+	logger := q.logger.With(
+		"correlation_id", correlationID,
+		"subject", subject,
+		"attempts", attempts,
+	)
+
+	data, err := marshalDeadLetter(DeadLetter{
+		Subject:       subject,
+		Payload:       string(payload),
+		Error:         cause.Error(),
+		Attempts:      attempts,
+		Service:       q.service,
+		CorrelationID: correlationID,
+		FailedAt:      time.Now().UTC(),
+	}, int(q.conn.MaxPayload())-deadLetterHeaderAllowance)
+	if err != nil {
+		logger.Error("Failed to marshal dead letter", "error", err)
+		return
+	}
+
+	ctx, span := startProducerSpan(ctx, deadLetterPrefix+subject)
+	err = q.conn.PublishMsg(newMsg(ctx, deadLetterPrefix+subject, data))
+	endSpan(span, err)
+	if err != nil {
+		logger.Error("Failed to publish dead letter", "error", err)
+		return
+	}
+
+	logger.Warn("Message sent to the dead-letter queue", "dlq_subject", deadLetterPrefix+subject, "error", cause)
+}
+
+// marshalDeadLetter encodes a dead letter in at most limit bytes. A payload too large
+// to fit loses its audio_data first, then the payload is dropped entirely.
+func marshalDeadLetter(letter DeadLetter, limit int) ([]byte, error) {
+	data, err := json.Marshal(letter)
+	if err != nil || len(data) <= limit {
+		return data, err
+	}
+
+	letter.Truncated = true
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(letter.Payload), &fields) == nil && fields["audio_data"] != nil {
+		delete(fields, "audio_data")
+		payload, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		letter.Payload = string(payload)
+		if data, err = json.Marshal(letter); err != nil || len(data) <= limit {
+			return data, err
+		}
+	}
+
+	letter.Payload = ""
+	return json.Marshal(letter)
+}
+
+// handleCommand runs a dlq.list, dlq.get or dlq.replay admin command and returns its reply
+func (q *DeadLetterQueue) handleCommand(subject string, data []byte) (interface{}, error) {
+	switch subject {
+	case deadLetterListSubject:
+		return q.handleList(data)
+	case deadLetterGetSubject:
+		return q.handleGet(data)
+	case deadLetterReplaySubject:
+		return q.handleReplay(data)
+	default:
+		return nil, errUnknownCommand
+	}
+}
+
+// DeadLetterListCommand filters dlq.list by original subject and caps its length
+type DeadLetterListCommand struct {
+	Subject string `json:"subject,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+}
+
+// DeadLetterCommand names a dead-letter entry by its stream sequence
+type DeadLetterCommand struct {
+	Sequence uint64 `json:"sequence"`
+}
+
+// handleList returns the oldest dead letters, without their payloads. It reads them
+// through an ordered consumer filtered on the original subject, so replayed entries
+// and other subjects are skipped by the server.
+func (q *DeadLetterQueue) handleList(data []byte) (interface{}, error) {
+	var cmd DeadLetterListCommand
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal dead-letter list command: %w", err)
+		}
+	}
+	if cmd.Limit <= 0 {
+		cmd.Limit = defaultDeadLetterLimit
+	}
+
+	info, err := q.streamInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []DeadLetterEntry{}
+	if info.State.Msgs == 0 {
+		return DeadLetterListReply{
+			CommandReply: CommandReply{Status: ReplyStatusOK},
+			DeadLetters:  entries,
+		}, nil
+	}
+
+	filter := deadLetterPrefix + ">"
+	if cmd.Subject != "" {
+		filter = deadLetterPrefix + cmd.Subject
+	}
+	sub, err := q.js.SubscribeSync(filter, nats.OrderedConsumer(), nats.BindStream(DeadLetterStream), nats.DeliverAll())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterUnavailable, err)
+	}
+	defer sub.Unsubscribe()
+
+	consumer, err := sub.ConsumerInfo()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterUnavailable, err)
+	}
+
+	matching := consumer.NumPending + consumer.Delivered.Consumer
+	for i := uint64(0); i < matching && len(entries) < cmd.Limit; i++ {
+		msg, err := sub.NextMsg(deadLetterReadTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead letters: %w", err)
+		}
+		entry, err := decodeDeadLetter(msg)
+		if err != nil {
+			return nil, err
+		}
+		entry.Payload = ""
+		entries = append(entries, *entry)
+
+		if meta, err := msg.Metadata(); err == nil && meta.NumPending == 0 {
+			break
+		}
+	}
+
+	return DeadLetterListReply{
+		CommandReply: CommandReply{Status: ReplyStatusOK},
+		DeadLetters:  entries,
+	}, nil
+}
+
+// handleGet returns a dead letter with its payload
+func (q *DeadLetterQueue) handleGet(data []byte) (interface{}, error) {
+	var cmd DeadLetterCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead-letter command: %w", err)
+	}
+
+	if _, err := q.streamInfo(); err != nil {
+		return nil, err
+	}
+
+	entry, err := q.entry(cmd.Sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeadLetterReply{
+		CommandReply: CommandReply{Status: ReplyStatusOK},
+		DeadLetter:   entry,
+	}, nil
+}
+
+// handleReplay publishes a dead letter's payload back onto its original subject and
+// removes it from the dead-letter stream
+func (q *DeadLetterQueue) handleReplay(data []byte) (interface{}, error) {
+	var cmd DeadLetterCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead-letter command: %w", err)
+	}
+
+	if _, err := q.streamInfo(); err != nil {
+		return nil, err
+	}
+
+	entry, err := q.entry(cmd.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Truncated {
+		return nil, fmt.Errorf("%w: %d", ErrDeadLetterTruncated, cmd.Sequence)
+	}
+
+	// The replay starts from the request that failed, keeping its correlation ID
+	ctx := ports.WithCorrelationID(context.Background(), entry.CorrelationID)
+	if err := q.conn.PublishMsg(newMsg(ctx, entry.Subject, []byte(entry.Payload))); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter %d: %w", cmd.Sequence, err)
+	}
+	if err := q.js.DeleteMsg(DeadLetterStream, cmd.Sequence); err != nil {
+		q.logger.Warn("Failed to remove replayed dead letter", "sequence", cmd.Sequence, "error", err)
+	}
+
+	q.logger.Info("Dead letter replayed", "sequence", cmd.Sequence, "subject", entry.Subject, "service", entry.Service)
+	return DeadLetterReply{
+		CommandReply: CommandReply{Status: ReplyStatusOK},
+		DeadLetter:   entry,
+	}, nil
+}
+
+// streamInfo returns the dead-letter stream, or ErrDeadLetterUnavailable without one
+func (q *DeadLetterQueue) streamInfo() (*nats.StreamInfo, error) {
+	if q.js == nil {
+		return nil, ErrDeadLetterUnavailable
+	}
+
+	info, err := q.js.StreamInfo(DeadLetterStream)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeadLetterUnavailable, err)
+	}
+	return info, nil
+}
+
+// entry reads a dead letter from the stream by its sequence
+func (q *DeadLetterQueue) entry(seq uint64) (*DeadLetterEntry, error) {
+	msg, err := q.js.GetMsg(DeadLetterStream, seq)
+	if errors.Is(err, nats.ErrMsgNotFound) {
+		return nil, fmt.Errorf("%w: %d", ErrDeadLetterNotFound, seq)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter %d: %w", seq, err)
+	}
+
+	return parseDeadLetter(msg.Sequence, msg.Subject, msg.Data)
+}
+
+// decodeDeadLetter reads a dead letter delivered by a consumer of the stream
+func decodeDeadLetter(msg *nats.Msg) (*DeadLetterEntry, error) {
+	meta, err := msg.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter metadata: %w", err)
+	}
+	return parseDeadLetter(meta.Sequence.Stream, msg.Subject, msg.Data)
+}
+
+// parseDeadLetter decodes the dead letter stored at a stream sequence
+func parseDeadLetter(seq uint64, subject string, data []byte) (*DeadLetterEntry, error) {
+	entry := &DeadLetterEntry{Sequence: seq}
+	if err := json.Unmarshal(data, &entry.DeadLetter); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter %d: %w", seq, err)
+	}
+	if entry.Subject == "" {
+		entry.Subject = strings.TrimPrefix(subject, deadLetterPrefix)
+	}
+	return entry, nil
+}
@@ -0,0 +1,156 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestDeadLetterQueue_HandleCommand(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	queue := NewDeadLetterQueue(nil, nil, "transcriber", logger)
+
+	tests := []struct {
+		name    string
+		subject string
+		data    string
+		want    ErrorCode
+	}{
+		{"list without stream", deadLetterListSubject, "", ErrorCodeDeadLetterUnavailable},
+		{"get without stream", deadLetterGetSubject, `{"sequence":1}`, ErrorCodeDeadLetterUnavailable},
+		{"replay without stream", deadLetterReplaySubject, `{"sequence":1}`, ErrorCodeDeadLetterUnavailable},
+		{"invalid list payload", deadLetterListSubject, `{"limit":"ten"}`, ErrorCodeInvalidPayload},
+		{"invalid replay payload", deadLetterReplaySubject, `not json`, ErrorCodeInvalidPayload},
+		{"unknown command", "speakr.admin.dlq.purge", "", ErrorCodeUnknownCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queue.handleCommand(tt.subject, []byte(tt.data))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if got := errorCodeFor(err); got != tt.want {
+				t.Errorf("Expected code %s, got %s (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestDeadLetterListReply_EmptyList(t *testing.T) {
+	data, err := json.Marshal(DeadLetterListReply{
+		CommandReply: CommandReply{Status: ReplyStatusOK},
+		DeadLetters:  []DeadLetterEntry{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal reply: %v", err)
+	}
+
+	if !strings.Contains(string(data), `"dead_letters":[]`) {
+		t.Errorf("Expected an empty dead_letters list, got %s", data)
+	}
+}
+
+func TestDeadLetterCodes_NotRetryable(t *testing.T) {
+	for _, err := range []error{ErrDeadLetterUnavailable, ErrDeadLetterNotFound, ErrDeadLetterTruncated} {
+		if code := errorCodeFor(err); code == ErrorCodeInternal || retryableCodes[code] {
+			t.Errorf("Expected a dedicated, permanent code for %v, got %s", err, code)
+		}
+	}
+}
+
+func TestMarshalDeadLetter_FitsLimit(t *testing.T) {
+	audio := strings.Repeat("A", 4096)
+	letter := DeadLetter{
+		Subject: "speakr.command.transcription.run",
+		Payload: `{"audio_data":"` + audio + `","tags":["standup"]}`,
+		Error:   "transcription failed",
+	}
+
+	data, err := marshalDeadLetter(letter, 8192)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var small DeadLetter
+	json.Unmarshal(data, &small)
+	if small.Truncated || small.Payload != letter.Payload {
+		t.Errorf("Expected a dead letter within the limit to be unchanged, got %+v", small)
+	}
+
+	data, err = marshalDeadLetter(letter, 1024)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var cut DeadLetter
+	json.Unmarshal(data, &cut)
+	if len(data) > 1024 || !cut.Truncated || cut.Payload != `{"tags":["standup"]}` {
+		t.Errorf("Expected audio_data dropped and the entry marked truncated, got %d bytes: %+v", len(data), cut)
+	}
+
+	letter.Payload = strings.Repeat("x", 4096)
+	data, err = marshalDeadLetter(letter, 1024)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var dropped DeadLetter
+	json.Unmarshal(data, &dropped)
+	if !dropped.Truncated || dropped.Payload != "" || dropped.Error != letter.Error {
+		t.Errorf("Expected the payload dropped and the entry kept, got %+v", dropped)
+	}
+}
+
+func TestDeadLetterQueue_ListIntegration(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	conn, err := nats.Connect("nats://localhost:4222", nats.Timeout(time.Second))
+	if err != nil {
+		t.Skipf("NATS not available, skipping integration test: %v", err)
+	}
+	defer conn.Close()
+
+	js, err := conn.JetStream()
+	if err != nil {
+		t.Skipf("JetStream not available, skipping integration test: %v", err)
+	}
+	if err := EnsureStreams(js, logger, 0); err != nil {
+		t.Skipf("JetStream not available, skipping integration test: %v", err)
+	}
+
+	queue := NewDeadLetterQueue(conn, js, "transcriber", logger)
+	subject := fmt.Sprintf("speakr.test.dlq.%d", time.Now().UnixNano())
+	for i := 0; i < 3; i++ {
+		queue.Publish(context.Background(), subject, []byte(`{"n":1}`), errors.New("failed"), 1)
+	}
+	conn.Flush()
+
+	reply, err := queue.handleList([]byte(fmt.Sprintf(`{"subject":%q,"limit":2}`, subject)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	entries := reply.(DeadLetterListReply).DeadLetters
+	if len(entries) != 2 {
+		t.Fatalf("Expected the limit of 2 entries, got %d", len(entries))
+	}
+	if entries[0].Subject != subject || entries[0].Payload != "" || entries[0].Sequence >= entries[1].Sequence {
+		t.Errorf("Expected the oldest entries of the subject without payloads, got %+v", entries)
+	}
+
+	reply, err = queue.handleList([]byte(fmt.Sprintf(`{"subject":%q}`, subject)))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, entry := range reply.(DeadLetterListReply).DeadLetters {
+		js.DeleteMsg(DeadLetterStream, entry.Sequence)
+	}
+	if got := len(reply.(DeadLetterListReply).DeadLetters); got != 3 {
+		t.Errorf("Expected all 3 entries of the subject, got %d", got)
+	}
+}
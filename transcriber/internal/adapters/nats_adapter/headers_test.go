@@ -0,0 +1,88 @@
+package nats_adapter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewMsg_CarriesTraceHeaders(t *testing.T) {
+	ctx := ports.WithCorrelationID(context.Background(), "corr-1")
+	ctx = ports.WithCausationID(ctx, "msg-0")
+
+	msg := newMsg(ctx, "speakr.event.recording.started", []byte(`{}`))
+
+	if got := msg.Header.Get(headerCorrelationID); got != "corr-1" {
+		t.Errorf("Expected correlation ID corr-1, got %q", got)
+	}
+	if got := msg.Header.Get(headerCausationID); got != "msg-0" {
+		t.Errorf("Expected causation ID msg-0, got %q", got)
+	}
+	if msg.Header.Get(headerMessageID) == "" {
+		t.Error("Expected a message ID")
+	}
+
+	bare := newMsg(context.Background(), "speakr.event.recording.started", nil)
+	if bare.Header.Get(headerCorrelationID) != "" || bare.Header.Get(headerCausationID) != "" {
+		t.Errorf("Expected no trace IDs without them in the context, got %v", bare.Header)
+	}
+}
+
+func TestMessageIDs(t *testing.T) {
+	withHeaders := nats.NewMsg("speakr.command.recording.start")
+	withHeaders.Header.Set(headerMessageID, "msg-1")
+	withHeaders.Header.Set(headerCorrelationID, "corr-header")
+	withHeaders.Data = []byte(`{"correlation_id":"corr-payload"}`)
+
+	messageID, correlationID := messageIDs(withHeaders)
+	if messageID != "msg-1" || correlationID != "corr-header" {
+		t.Errorf("Expected headers to win, got %q and %q", messageID, correlationID)
+	}
+
+	payloadOnly := &nats.Msg{
+		Subject: "speakr.command.recording.start",
+		Data:    []byte(`{"message_id":"msg-2","correlation_id":"corr-payload"}`),
+	}
+	messageID, correlationID = messageIDs(payloadOnly)
+	if messageID != "msg-2" || correlationID != "corr-payload" {
+		t.Errorf("Expected the payload IDs, got %q and %q", messageID, correlationID)
+	}
+
+	bare := &nats.Msg{Subject: "speakr.command.recording.start", Data: []byte(`not json`)}
+	messageID, correlationID = messageIDs(bare)
+	if messageID != "" {
+		t.Errorf("Expected no message ID, got %q", messageID)
+	}
+	if correlationID == "" {
+		t.Error("Expected a new correlation ID")
+	}
+}
+
+func TestNewMsg_PropagatesTraceContext(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "publish")
+	msg := newMsg(ctx, "speakr.command.transcription.run", []byte(`{}`))
+	parent.End()
+
+	if msg.Header.Get("traceparent") == "" {
+		t.Fatalf("Expected a traceparent header, got %v", msg.Header)
+	}
+
+	subscriber := NewSubscriber(nil, nil, slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})))
+	received, _ := subscriber.messageContext(msg)
+	if got := trace.SpanContextFromContext(received).TraceID(); got != parent.SpanContext().TraceID() {
+		t.Errorf("Expected the sender's trace %s, got %s", parent.SpanContext().TraceID(), got)
+	}
+}
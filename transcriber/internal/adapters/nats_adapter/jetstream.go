@@ -0,0 +1,360 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// JetStream streams that keep commands and events while their consumers are down
+const (
+	CommandStream = "SPEAKR_COMMANDS"
+	EventStream   = "SPEAKR_EVENTS"
+)
+
+// retryableCodes are the failures a redelivered command may get past. Any other
+// failure is terminated at once instead of being delivered again.
+var retryableCodes = map[ErrorCode]bool{
+	ErrorCodeProviderQuotaExceeded: true,
+	ErrorCodeProviderTimeout:       true,
+	ErrorCodeProviderUnavailable:   true,
+	ErrorCodeStorageUnavailable:    true,
+	ErrorCodeInsufficientStorage:   true,
+	ErrorCodeInternal:              true,
+}
+
+// EnsureStreams creates the command, event and dead-letter streams if they do not exist yet.
+// Existing streams keep their limits, so they can be tuned on the server, but get
+// their subjects and acknowledgement setting corrected.
+func EnsureStreams(js nats.JetStreamContext, logger *slog.Logger, maxAge time.Duration) error {
+	streams := []*nats.StreamConfig{
+		{
+			Name:        CommandStream,
+			Description: "Speakr commands",
+			Subjects:    []string{"speakr.command.>"},
+			MaxAge:      maxAge,
+			// Requests keep their reply subject for the service, so the stream
+			// must not answer them with its own publish acknowledgement
+			NoAck: true,
+		},
+		{
+			Name:        EventStream,
+			Description: "Speakr events",
+			Subjects:    []string{"speakr.event.>"},
+			MaxAge:      maxAge,
+		},
+		{
+			Name:        DeadLetterStream,
+			Description: "Speakr messages that could not be handled",
+			Subjects:    []string{deadLetterPrefix + ">"},
+			// Kept until replayed, the oldest dropped if they pile up
+			MaxMsgs: 10000,
+		},
+	}
+
+	for _, stream := range streams {
+		info, err := js.StreamInfo(stream.Name)
+		switch {
+		case errors.Is(err, nats.ErrStreamNotFound):
+			logger.Info("Stream does not exist, creating it", "stream", stream.Name, "subjects", stream.Subjects)
+			_, err = js.AddStream(stream)
+		case err == nil && (!slices.Equal(info.Config.Subjects, stream.Subjects) || info.Config.NoAck != stream.NoAck):
+			logger.Warn("Correcting stream subjects", "stream", stream.Name, "subjects", stream.Subjects, "previous", info.Config.Subjects)
+			existing := info.Config
+			existing.Subjects = stream.Subjects
+			existing.NoAck = stream.NoAck
+			_, err = js.UpdateStream(&existing)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set up stream %s: %w", stream.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// JetStreamPublisher publishes events into the event stream and waits for the
+// stream to store them, so consumers that are down receive them later
+type JetStreamPublisher struct {
+	js     nats.JetStreamContext
+	logger *slog.Logger
+}
+
+// NewJetStreamPublisher creates a new JetStream publisher
+func NewJetStreamPublisher(js nats.JetStreamContext, logger *slog.Logger) *JetStreamPublisher {
+	return &JetStreamPublisher{
+		js:     js,
+		logger: logger,
+	}
+}
+
+// PublishEvent publishes an event to the event stream
+func (p *JetStreamPublisher) PublishEvent(ctx context.Context, event ports.Event) error {
+	logger := p.logger.With(
+		"correlation_id", ports.CorrelationID(ctx),
+		"subject", event.Subject,
+	)
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		logger.Error("Failed to marshal event data", "error", err)
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	ctx, span := startProducerSpan(ctx, event.Subject)
+	ack, err := p.js.PublishMsg(newMsg(ctx, event.Subject, data))
+	endSpan(span, err)
+	if err != nil {
+		logger.Error("Failed to publish event", "error", err)
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	logger.Info("Event published successfully", "stream", ack.Stream, "sequence", ack.Sequence, "data", string(data))
+	return nil
+}
+
+// consumeTranscriptions pulls transcription.run from the command stream through a
+// durable consumer shared by every replica, one command per free worker
+func (s *Subscriber) consumeTranscriptions(ctx context.Context) error {
+	consumer := &nats.ConsumerConfig{
+		Durable:       s.config.TranscriptionQueue,
+		Description:   "Speakr transcription workers",
+		FilterSubject: transcriptionSubject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       s.config.AckWait,
+		MaxDeliver:    s.config.MaxDeliver,
+		DeliverPolicy: nats.DeliverAllPolicy,
+	}
+
+	// The consumer is created here rather than by the subscription, which would
+	// delete it again on unsubscribe
+	_, err := s.config.JetStream.ConsumerInfo(CommandStream, consumer.Durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = s.config.JetStream.AddConsumer(CommandStream, consumer)
+	} else if err == nil {
+		_, err = s.config.JetStream.UpdateConsumer(CommandStream, consumer)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up consumer %s: %w", consumer.Durable, err)
+	}
+
+	sub, err := s.config.JetStream.PullSubscribe(transcriptionSubject, consumer.Durable, nats.Bind(CommandStream, consumer.Durable))
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", transcriptionSubject, err)
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+
+	// Requesters are told the command is queued, as the stream does not answer them
+	accept, err := s.conn.QueueSubscribe(transcriptionSubject, s.config.TranscriptionQueue, s.acceptTranscription)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", transcriptionSubject, err)
+	}
+	s.subscriptions = append(s.subscriptions, accept)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	s.stopFetching = cancel
+	for i := 0; i < s.config.TranscriptionWorkers; i++ {
+		s.inFlight.Add(1)
+		go func() {
+			defer s.inFlight.Done()
+			s.fetchTranscriptions(fetchCtx, sub)
+		}()
+	}
+
+	s.logger.Info("Consuming stream",
+		"stream", CommandStream,
+		"consumer", consumer.Durable,
+		"subject", transcriptionSubject,
+		"workers", s.config.TranscriptionWorkers,
+		"max_deliver", s.config.MaxDeliver)
+	return nil
+}
+
+// fetchTranscriptions runs one worker until the context is cancelled
+func (s *Subscriber) fetchTranscriptions(ctx context.Context, sub *nats.Subscription) {
+	for ctx.Err() == nil {
+		msgs, err := sub.Fetch(1, nats.Context(ctx))
+		if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("Failed to fetch from stream", "stream", CommandStream, "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, msg := range msgs {
+			s.handleStreamMessage(msg)
+		}
+	}
+}
+
+// handleStreamMessage runs a command from the stream and acknowledges it once it is
+// handled, or schedules its redelivery when it failed for a reason that may pass
+func (s *Subscriber) handleStreamMessage(msg *nats.Msg) {
+	ctx, logger := s.messageContext(msg)
+	ctx, span := startConsumerSpan(ctx, msg.Subject)
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		logger.Error("Failed to read stream message metadata", "error", err)
+		endSpan(span, err)
+		return
+	}
+	logger = logger.With("stream_sequence", meta.Sequence.Stream, "delivery", meta.NumDelivered)
+	span.SetAttributes(attribute.Int64("messaging.nats.delivery", int64(meta.NumDelivered)))
+
+	messageID, _ := messageIDs(msg)
+	ctx = withStreamDelivery(ctx, rawAudioID(messageID, meta.Sequence.Stream))
+
+	// Long transcriptions keep the message from being redelivered to another worker
+	done := make(chan struct{})
+	go s.keepInProgress(msg, done)
+	_, err = s.handleCommand(ctx, logger, msg.Subject, msg.Data)
+	close(done)
+	endSpan(span, err)
+
+	s.acknowledge(ctx, logger, msg, meta.NumDelivered, err)
+}
+
+// keepInProgress extends the ack deadline of a message until done is closed
+func (s *Subscriber) keepInProgress(msg *nats.Msg, done <-chan struct{}) {
+	ticker := time.NewTicker(s.config.AckWait / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				s.logger.Warn("Failed to extend ack deadline", "subject", msg.Subject, "error", err)
+			}
+		}
+	}
+}
+
+// acknowledge settles a handled message: acked on success, redelivered after a backoff
+// on a retryable failure, and otherwise terminated and sent to the dead-letter queue
+func (s *Subscriber) acknowledge(ctx context.Context, logger *slog.Logger, msg *nats.Msg, delivery uint64, err error) {
+	var ackErr error
+	switch {
+	case err == nil:
+		ackErr = msg.Ack()
+	case !retryableCodes[errorCodeFor(err)]:
+		logger.Warn("Command cannot succeed, not redelivering it", "error", err)
+		ackErr = msg.Term()
+		s.transcriptionFailed(ctx, msg, err)
+		s.deadLetter(ctx, msg, err, int(delivery))
+	case int(delivery) >= s.config.MaxDeliver:
+		logger.Error("Command failed on its last delivery", "error", err)
+		ackErr = msg.Term()
+		s.transcriptionFailed(ctx, msg, err)
+		s.deadLetter(ctx, msg, err, int(delivery))
+	default:
+		delay := s.redeliveryDelay(delivery)
+		logger.Warn("Command failed, redelivering it", "error", err, "delay", delay)
+		ackErr = msg.NakWithDelay(delay)
+		commandRetries.WithLabelValues(s.commandSubject(msg.Subject)).Inc()
+	}
+
+	if ackErr != nil {
+		logger.Error("Failed to acknowledge stream message", "error", ackErr)
+	}
+}
+
+// redeliveryDelay returns the backoff after a failed delivery, repeating the last
+// step once the schedule runs out
+func (s *Subscriber) redeliveryDelay(delivery uint64) time.Duration {
+	if len(s.config.Backoff) == 0 {
+		return 0
+	}
+	step := int(delivery) - 1
+	if step >= len(s.config.Backoff) {
+		step = len(s.config.Backoff) - 1
+	}
+	return s.config.Backoff[step]
+}
+
+// transcriptionFailed publishes the transcription.failed event of a transcription.run
+// that is given up on, which its deliveries left to the stream consumer
+func (s *Subscriber) transcriptionFailed(ctx context.Context, msg *nats.Msg, err error) {
+	if s.commandSubject(msg.Subject) != transcriptionSubject {
+		return
+	}
+
+	var cmd core.TranscriptionCommand
+	if json.Unmarshal(msg.Data, &cmd) != nil {
+		return // an unreadable command never reached the service
+	}
+	cmd.AudioID, _ = streamDeliveryFrom(ctx)
+	s.service.PublishTranscriptionFailed(ctx, cmd, err)
+}
+
+// acceptTranscription answers a transcription.run request that the command stream
+// took in, with the recording ID if the command names one. Raw audio is answered
+// with the ID it will be stored under, which needs a message ID.
+func (s *Subscriber) acceptTranscription(msg *nats.Msg) {
+	if msg.Reply == "" {
+		return
+	}
+
+	ctx, logger := s.messageContext(msg)
+
+	var cmd struct {
+		RecordingID string          `json:"recording_id"`
+		AudioData   json.RawMessage `json:"audio_data"`
+	}
+	_ = json.Unmarshal(msg.Data, &cmd)
+
+	recordingID := cmd.RecordingID
+	if messageID, _ := messageIDs(msg); recordingID == "" && len(cmd.AudioData) > 0 && messageID != "" {
+		recordingID = rawAudioID(messageID, 0)
+	}
+
+	s.reply(ctx, logger, msg, CommandReply{
+		RecordingID: recordingID,
+		Status:      ReplyStatusAccepted,
+	})
+}
+
+// rawAudioID returns the recording ID of raw audio sent on the command stream, the
+// same in the accepted reply and on every delivery: derived from the command's
+// message ID, or from its stream sequence when it has none
+func rawAudioID(messageID string, sequence uint64) string {
+	name := "speakr:message:" + messageID
+	if messageID == "" {
+		name = fmt.Sprintf("speakr:%s:%d", CommandStream, sequence)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name)).String()
+}
+
+// streamDeliveryKey marks the context of a command delivered from the stream
+type streamDeliveryKey struct{}
+
+// withStreamDelivery marks a context as handling a stream delivery whose raw audio,
+// if any, is stored under audioID
+func withStreamDelivery(ctx context.Context, audioID string) context.Context {
+	return context.WithValue(ctx, streamDeliveryKey{}, audioID)
+}
+
+// streamDeliveryFrom returns the raw audio ID of a stream delivery, and whether the
+// context handles one
+func streamDeliveryFrom(ctx context.Context) (string, bool) {
+	audioID, ok := ctx.Value(streamDeliveryKey{}).(string)
+	return audioID, ok
+}
@@ -0,0 +1,189 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/adapters/memory_adapter"
+	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestSubscriber_RedeliveryDelay(t *testing.T) {
+	subscriber := NewSubscriber(nil, nil, nil, WithBackoff(time.Second, time.Minute))
+
+	tests := []struct {
+		delivery uint64
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, time.Minute},
+		{5, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := subscriber.redeliveryDelay(tt.delivery); got != tt.want {
+			t.Errorf("Delivery %d: expected delay %s, got %s", tt.delivery, tt.want, got)
+		}
+	}
+
+	if got := NewSubscriber(nil, nil, nil, WithBackoff()).redeliveryDelay(1); got != 0 {
+		t.Errorf("Expected no delay without a backoff, got %s", got)
+	}
+}
+
+func TestRetryableCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"provider unavailable", fmt.Errorf("transcription failed: %w", ports.ErrProviderUnavailable), true},
+		{"quota exceeded", ports.ErrProviderQuotaExceeded, true},
+		{"unexpected failure", errors.New("boom"), true},
+		{"recording not found", fmt.Errorf("failed to stop recording: %w", core.ErrRecordingNotFound), false},
+		{"missing audio source", core.ErrMissingAudioSource, false},
+		{"unknown profile", core.ErrUnknownProfile, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableCodes[errorCodeFor(tt.err)]; got != tt.want {
+				t.Errorf("Expected retryable %v for %v, got %v", tt.want, tt.err, got)
+			}
+		})
+	}
+}
+
+func TestSubscriber_ValidateStreamDelivery(t *testing.T) {
+	var js nats.JetStreamContext = jetStreamStub{}
+
+	if err := NewSubscriber(nil, nil, nil, WithJetStream(js)).validateConfig(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+	if err := NewSubscriber(nil, nil, nil, WithJetStream(js), WithMaxDeliver(0)).validateConfig(); !errors.Is(err, errInvalidDelivery) {
+		t.Errorf("Expected errInvalidDelivery, got %v", err)
+	}
+	if err := NewSubscriber(nil, nil, nil, WithJetStream(js), WithAckWait(0)).validateConfig(); !errors.Is(err, errInvalidDelivery) {
+		t.Errorf("Expected errInvalidDelivery, got %v", err)
+	}
+	if err := NewSubscriber(nil, nil, nil, WithMaxDeliver(0)).validateConfig(); err != nil {
+		t.Errorf("Expected delivery settings to be ignored without JetStream, got %v", err)
+	}
+}
+
+func TestRawAudioID(t *testing.T) {
+	if rawAudioID("msg-1", 7) != rawAudioID("msg-1", 9) {
+		t.Error("Expected the message ID to decide the recording ID")
+	}
+	if rawAudioID("msg-1", 7) == rawAudioID("msg-2", 7) {
+		t.Error("Expected different messages to get different recording IDs")
+	}
+	if rawAudioID("", 7) != rawAudioID("", 7) || rawAudioID("", 7) == rawAudioID("", 8) {
+		t.Error("Expected the stream sequence to decide the recording ID without a message ID")
+	}
+}
+
+func TestSubscriber_AcceptTranscriptionRepliesWithRawAudioID(t *testing.T) {
+	server := newFakeServer(t)
+	conn, err := nats.Connect(server.url())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	sub, err := conn.SubscribeSync(transcriptionSubject)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	subscriber := NewSubscriber(conn, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	msg := &nats.Msg{
+		Subject: transcriptionSubject,
+		Reply:   "_INBOX.test",
+		Header:  nats.Header{headerMessageID: []string{"msg-1"}},
+		Data:    []byte(`{"audio_data":"UklGRg=="}`),
+		Sub:     sub,
+	}
+	subscriber.acceptTranscription(msg)
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	select {
+	case published := <-server.published:
+		var reply CommandReply
+		if err := json.Unmarshal(published.Data, &reply); err != nil {
+			t.Fatalf("Failed to unmarshal reply %q: %v", published.Data, err)
+		}
+		if reply.Status != ReplyStatusAccepted || reply.RecordingID != rawAudioID("msg-1", 0) {
+			t.Errorf("Expected an accepted reply with the raw audio ID, got %+v", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a reply on the inbox")
+	}
+}
+
+func TestSubscriber_StreamTranscriptionDefersFailure(t *testing.T) {
+	server := newFakeServer(t)
+	conn, err := nats.Connect(server.url())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := core.NewService(stubRecorder{}, nil, nil, memory_adapter.NewSessionRegistry(), NewPublisher(conn, logger), logger)
+	subscriber := NewSubscriber(conn, service, logger)
+
+	data := []byte(`{"audio_data":"not base64 at all!","tags":["voicemail"]}`)
+	ctx := withStreamDelivery(context.Background(), rawAudioID("msg-1", 3))
+
+	// A failed delivery publishes nothing, as the command may be delivered again
+	recordingID, err := subscriber.handleTranscription(ctx, data)
+	if !errors.Is(err, core.ErrInvalidAudioData) || recordingID != rawAudioID("msg-1", 3) {
+		t.Fatalf("Expected ErrInvalidAudioData for the raw audio ID, got %q, %v", recordingID, err)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	select {
+	case published := <-server.published:
+		t.Fatalf("Expected no event for a failed delivery, got %s", published.Subject)
+	default:
+	}
+
+	// Giving up on the command publishes its one transcription.failed
+	subscriber.transcriptionFailed(ctx, &nats.Msg{Subject: transcriptionSubject, Data: data}, err)
+	if err := conn.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	select {
+	case published := <-server.published:
+		var event struct {
+			RecordingID string   `json:"recording_id"`
+			Tags        []string `json:"tags"`
+		}
+		if err := json.Unmarshal(published.Data, &event); err != nil {
+			t.Fatalf("Failed to unmarshal event %q: %v", published.Data, err)
+		}
+		if published.Subject != "speakr.event.transcription.failed" || event.RecordingID != rawAudioID("msg-1", 3) || len(event.Tags) != 1 {
+			t.Errorf("Expected transcription.failed for the raw audio ID, got %s %+v", published.Subject, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a transcription.failed event")
+	}
+}
+
+// jetStreamStub stands in for a JetStream context where only its presence matters
+type jetStreamStub struct {
+	nats.JetStreamContext
+}
@@ -0,0 +1,39 @@
+package nats_adapter
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Command metrics. Subjects are the shared command subjects, so the commands of
+// every named recorder are counted together.
+var (
+	commandsHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "commands_handled_total",
+		Help:      "Commands handled, by subject and outcome: ok or the error code of the reply.",
+	}, []string{"subject", "outcome"})
+
+	commandRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "command_retries_total",
+		Help:      "Failed stream commands scheduled for redelivery, by subject.",
+	}, []string{"subject"})
+
+	commandsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "commands_dropped_total",
+		Help:      "Commands core NATS dropped because this replica fell behind, by subject.",
+	}, []string{"subject"})
+)
+
+// commandOutcome returns the outcome label of a handled command
+func commandOutcome(err error) string {
+	if err == nil {
+		return ReplyStatusOK
+	}
+	return string(errorCodeFor(err))
+}
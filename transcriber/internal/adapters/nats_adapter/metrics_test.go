@@ -0,0 +1,26 @@
+package nats_adapter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandleCommand_CountsOutcome(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	subscriber := NewSubscriber(nil, nil, logger, WithRecorderID("metrics-desk"))
+	subscriber.commands = subscriber.recordingSubjects()
+
+	subject := "speakr.command.recording.start"
+	before := testutil.ToFloat64(commandsHandled.WithLabelValues(subject, string(ErrorCodeInvalidPayload)))
+
+	// Commands for a named recorder are counted under the shared subject
+	subscriber.handleCommand(context.Background(), logger, "speakr.recorder.metrics-desk.command.recording.start", []byte(`not json`))
+
+	if got := testutil.ToFloat64(commandsHandled.WithLabelValues(subject, string(ErrorCodeInvalidPayload))); got != before+1 {
+		t.Errorf("Expected 1 more invalid_payload outcome, got %v", got-before)
+	}
+}
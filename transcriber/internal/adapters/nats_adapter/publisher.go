@@ -27,9 +27,8 @@ func NewPublisher(conn *nats.Conn, logger *slog.Logger) *Publisher {
 
 // PublishEvent publishes an event to NATS
 func (p *Publisher) PublishEvent(ctx context.Context, event ports.Event) error {
-	correlationID := ctx.Value("correlation_id")
 	logger := p.logger.With(
-		"correlation_id", correlationID,
+		"correlation_id", ports.CorrelationID(ctx),
 		"subject", event.Subject,
 	)
 
@@ -39,7 +38,10 @@ func (p *Publisher) PublishEvent(ctx context.Context, event ports.Event) error {
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
-	if err := p.conn.Publish(event.Subject, data); err != nil {
+	ctx, span := startProducerSpan(ctx, event.Subject)
+	err = p.conn.PublishMsg(newMsg(ctx, event.Subject, data))
+	endSpan(span, err)
+	if err != nil {
 		logger.Error("Failed to publish event", "error", err)
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
@@ -0,0 +1,173 @@
+package nats_adapter
+
+import (
+	"encoding/json"
+	"errors"
+
+	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
+)
+
+// Reply statuses
+const (
+	ReplyStatusOK    = "ok"
+	ReplyStatusError = "error"
+	// ReplyStatusAccepted answers a transcription.run queued in the command stream;
+	// its outcome arrives as a transcription event
+	ReplyStatusAccepted = "accepted"
+)
+
+// ErrorCode is a stable, machine-readable classification of a command failure
+type ErrorCode string
+
+// Error codes returned in command replies
+const (
+	ErrorCodeInvalidPayload         ErrorCode = "invalid_payload"
+	ErrorCodeUnknownCommand         ErrorCode = "unknown_command"
+	ErrorCodeMissingAudioSource     ErrorCode = "missing_audio_source"
+	ErrorCodeInvalidAudioData       ErrorCode = "invalid_audio_data"
+	ErrorCodeRecorderUnavailable    ErrorCode = "recorder_unavailable"
+	ErrorCodeRecordingExists        ErrorCode = "recording_already_exists"
+	ErrorCodeRecordingNotFound      ErrorCode = "recording_not_found"
+	ErrorCodeRecordingFileNotFound  ErrorCode = "recording_file_not_found"
+	ErrorCodeRecordingPaused        ErrorCode = "recording_already_paused"
+	ErrorCodeRecordingNotPaused     ErrorCode = "recording_not_paused"
+	ErrorCodeRecordingTimeExhausted ErrorCode = "recording_time_exhausted"
+	ErrorCodeInvalidFormat          ErrorCode = "invalid_format"
+	ErrorCodeInvalidPreset          ErrorCode = "invalid_preset"
+	ErrorCodeInsufficientDiskSpace  ErrorCode = "insufficient_disk_space"
+	ErrorCodePermissionDenied       ErrorCode = "permission_denied"
+	ErrorCodeDeviceNotFound         ErrorCode = "device_not_found"
+	ErrorCodeDeviceBusy             ErrorCode = "device_busy"
+	ErrorCodeUnsupportedPlatform    ErrorCode = "unsupported_platform"
+	ErrorCodeStorageUnavailable     ErrorCode = "storage_unavailable"
+	ErrorCodeStorageAccessDenied    ErrorCode = "storage_access_denied"
+	ErrorCodeInsufficientStorage    ErrorCode = "insufficient_storage"
+	ErrorCodeAudioNotFound          ErrorCode = "audio_not_found"
+	ErrorCodeProviderAuthFailed     ErrorCode = "provider_auth_failed"
+	ErrorCodeProviderQuotaExceeded  ErrorCode = "provider_quota_exceeded"
+	ErrorCodeProviderTimeout        ErrorCode = "provider_timeout"
+	ErrorCodeProviderUnavailable    ErrorCode = "provider_unavailable"
+	ErrorCodeAudioTooLarge          ErrorCode = "audio_too_large"
+	ErrorCodeInvalidAudioFormat     ErrorCode = "invalid_audio_format"
+	ErrorCodeInvalidSubtitleFormat  ErrorCode = "invalid_subtitle_format"
+	ErrorCodeInvalidTask            ErrorCode = "invalid_task"
+	ErrorCodeUnknownProfile         ErrorCode = "unknown_profile"
+	ErrorCodeInvalidAutoStop        ErrorCode = "invalid_auto_stop"
+	ErrorCodeInvalidRecordingLimit  ErrorCode = "invalid_recording_limit"
+	ErrorCodeEmptyTranscription     ErrorCode = "empty_transcription"
+	ErrorCodeDeadLetterUnavailable  ErrorCode = "dlq_unavailable"
+	ErrorCodeDeadLetterNotFound     ErrorCode = "dlq_entry_not_found"
+	ErrorCodeDeadLetterTruncated    ErrorCode = "dlq_entry_truncated"
+	ErrorCodeInternal               ErrorCode = "internal_error"
+)
+
+// errorCodes maps sentinel errors to reply error codes, checked in order with errors.Is
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{core.ErrMissingAudioSource, ErrorCodeMissingAudioSource},
+	{core.ErrInvalidAudioData, ErrorCodeInvalidAudioData},
+	{core.ErrEmptyAudioData, ErrorCodeInvalidAudioData},
+	{core.ErrRecordingNotFound, ErrorCodeRecordingNotFound},
+	{core.ErrUnknownAudioFormat, ErrorCodeInvalidAudioFormat},
+	{core.ErrUnsupportedSubtitleFormat, ErrorCodeInvalidSubtitleFormat},
+	{core.ErrInvalidTask, ErrorCodeInvalidTask},
+	{core.ErrUnknownProfile, ErrorCodeUnknownProfile},
+	{core.ErrInvalidAutoStop, ErrorCodeInvalidAutoStop},
+	{core.ErrInvalidRecordingLimit, ErrorCodeInvalidRecordingLimit},
+	{core.ErrLiveTranscriptionUnavailable, ErrorCodeProviderUnavailable},
+
+	{ports.ErrRecorderUnavailable, ErrorCodeRecorderUnavailable},
+	{ports.ErrRecordingExists, ErrorCodeRecordingExists},
+	{ports.ErrRecordingNotFound, ErrorCodeRecordingNotFound},
+	{ports.ErrRecordingFileNotFound, ErrorCodeRecordingFileNotFound},
+	{ports.ErrRecordingPaused, ErrorCodeRecordingPaused},
+	{ports.ErrRecordingNotPaused, ErrorCodeRecordingNotPaused},
+	{ports.ErrRecordingTimeExhausted, ErrorCodeRecordingTimeExhausted},
+	{ports.ErrInvalidRecordingFormat, ErrorCodeInvalidFormat},
+	{ports.ErrInvalidPreset, ErrorCodeInvalidPreset},
+	{ports.ErrInsufficientDiskSpace, ErrorCodeInsufficientDiskSpace},
+	{ports.ErrPermissionDenied, ErrorCodePermissionDenied},
+	{ports.ErrDeviceNotFound, ErrorCodeDeviceNotFound},
+	{ports.ErrDeviceBusy, ErrorCodeDeviceBusy},
+	{ports.ErrUnsupportedPlatform, ErrorCodeUnsupportedPlatform},
+
+	{ports.ErrStorageUnavailable, ErrorCodeStorageUnavailable},
+	{ports.ErrStorageAccessDenied, ErrorCodeStorageAccessDenied},
+	{ports.ErrInsufficientStorage, ErrorCodeInsufficientStorage},
+	{ports.ErrObjectNotFound, ErrorCodeAudioNotFound},
+
+	// Quota and timeout failures are also ErrProviderUnavailable, so they come first
+	{ports.ErrProviderAuthFailed, ErrorCodeProviderAuthFailed},
+	{ports.ErrProviderQuotaExceeded, ErrorCodeProviderQuotaExceeded},
+	{ports.ErrProviderTimeout, ErrorCodeProviderTimeout},
+	{ports.ErrProviderUnavailable, ErrorCodeProviderUnavailable},
+	{ports.ErrAudioTooLarge, ErrorCodeAudioTooLarge},
+	{ports.ErrUnsupportedAudio, ErrorCodeInvalidAudioFormat},
+	{ports.ErrNoSpeech, ErrorCodeEmptyTranscription},
+
+	{ErrDeadLetterUnavailable, ErrorCodeDeadLetterUnavailable},
+	{ErrDeadLetterNotFound, ErrorCodeDeadLetterNotFound},
+	{ErrDeadLetterTruncated, ErrorCodeDeadLetterTruncated},
+}
+
+// CommandReply is sent to the reply subject of a command issued with request-reply
+type CommandReply struct {
+	RecordingID string              `json:"recording_id,omitempty"`
+	Status      string              `json:"status"`
+	Recording   *core.RecordingInfo `json:"recording,omitempty"`
+	Error       *ReplyError         `json:"error,omitempty"`
+}
+
+// RecordingListReply answers recording.list; recordings is always present, even when empty
+type RecordingListReply struct {
+	CommandReply
+	Recordings []core.RecordingInfo `json:"recordings"`
+}
+
+// ReplyError describes why a command failed
+type ReplyError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// newCommandReply builds the reply for a handled command
+func newCommandReply(recordingID string, err error) CommandReply {
+	reply := CommandReply{
+		RecordingID: recordingID,
+		Status:      ReplyStatusOK,
+	}
+
+	if err != nil {
+		reply.Status = ReplyStatusError
+		reply.Error = &ReplyError{
+			Code:    errorCodeFor(err),
+			Message: err.Error(),
+		}
+	}
+
+	return reply
+}
+
+// errorCodeFor classifies an error returned while handling a command
+func errorCodeFor(err error) ErrorCode {
+	if errors.Is(err, errUnknownCommand) {
+		return ErrorCodeUnknownCommand
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return ErrorCodeInvalidPayload
+	}
+
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			return mapping.code
+		}
+	}
+
+	return ErrorCodeInternal
+}
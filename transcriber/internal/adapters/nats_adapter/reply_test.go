@@ -0,0 +1,100 @@
+package nats_adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
+)
+
+func TestErrorCodeFor(t *testing.T) {
+	var startCmd core.StartRecordingCommand
+	syntaxErr := json.Unmarshal([]byte(`{"invalid": json}`), &startCmd)
+	typeErr := json.Unmarshal([]byte(`{"tags": "not-a-list"}`), &startCmd)
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"unknown command", errUnknownCommand, ErrorCodeUnknownCommand},
+		{"json syntax error", fmt.Errorf("failed to unmarshal: %w", syntaxErr), ErrorCodeInvalidPayload},
+		{"json type error", fmt.Errorf("failed to unmarshal: %w", typeErr), ErrorCodeInvalidPayload},
+		{"core sentinel", core.ErrMissingAudioSource, ErrorCodeMissingAudioSource},
+		{"wrapped recorder sentinel", fmt.Errorf("failed to stop recording: %w", ports.ErrRecordingNotFound), ErrorCodeRecordingNotFound},
+		{"wrapped storage sentinel", fmt.Errorf("failed to retrieve audio: %w", ports.ErrObjectNotFound), ErrorCodeAudioNotFound},
+		{"wrapped provider sentinel", fmt.Errorf("transcription failed: %w", ports.ErrProviderQuotaExceeded), ErrorCodeProviderQuotaExceeded},
+		{"provider timeout", ports.ErrProviderTimeout, ErrorCodeProviderTimeout},
+		{"provider unavailable", ports.ErrProviderUnavailable, ErrorCodeProviderUnavailable},
+		{"no speech", fmt.Errorf("provider returned empty transcription: %w", ports.ErrNoSpeech), ErrorCodeEmptyTranscription},
+		{"unclassified error", errors.New("boom"), ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCodeFor(tt.err); got != tt.want {
+				t.Errorf("errorCodeFor(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCommandReply_Success(t *testing.T) {
+	reply := newCommandReply("rec-1", nil)
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		t.Fatalf("Failed to marshal reply: %v", err)
+	}
+
+	expected := `{"recording_id":"rec-1","status":"ok"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(data))
+	}
+}
+
+func TestNewCommandReply_Error(t *testing.T) {
+	err := fmt.Errorf("failed to start recording: %w", ports.ErrDeviceBusy)
+	reply := newCommandReply("", err)
+
+	if reply.Status != ReplyStatusError {
+		t.Errorf("Expected status %s, got %s", ReplyStatusError, reply.Status)
+	}
+
+	if reply.Error == nil {
+		t.Fatal("Expected reply error, got nil")
+	}
+
+	if reply.Error.Code != ErrorCodeDeviceBusy {
+		t.Errorf("Expected code %s, got %s", ErrorCodeDeviceBusy, reply.Error.Code)
+	}
+
+	if reply.Error.Message != err.Error() {
+		t.Errorf("Expected message %q, got %q", err.Error(), reply.Error.Message)
+	}
+
+	data, _ := json.Marshal(reply)
+	if string(data) != `{"status":"error","error":{"code":"device_busy","message":"`+err.Error()+`"}}` {
+		t.Errorf("Unexpected reply JSON: %s", string(data))
+	}
+}
+
+func TestRecordingListReply_EmptyList(t *testing.T) {
+	reply := RecordingListReply{
+		CommandReply: newCommandReply("", nil),
+		Recordings:   []core.RecordingInfo{},
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		t.Fatalf("Failed to marshal reply: %v", err)
+	}
+
+	expected := `{"status":"ok","recordings":[]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, string(data))
+	}
+}
@@ -0,0 +1,125 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+// SessionRegistryConfig holds configuration for the NATS key-value session registry
+type SessionRegistryConfig struct {
+	Bucket string
+	TTL    time.Duration
+}
+
+// SessionRegistryOption is a functional option for configuring the session registry
+type SessionRegistryOption func(*SessionRegistryConfig)
+
+// WithSessionBucket sets the key-value bucket that holds recording sessions
+func WithSessionBucket(bucket string) SessionRegistryOption {
+	return func(c *SessionRegistryConfig) {
+		c.Bucket = bucket
+	}
+}
+
+// WithSessionTTL sets how long a recording session is retained
+func WithSessionTTL(ttl time.Duration) SessionRegistryOption {
+	return func(c *SessionRegistryConfig) {
+		c.TTL = ttl
+	}
+}
+
+// SessionRegistry implements the SessionRegistry port using a JetStream key-value bucket,
+// so sessions survive a transcriber restart
+type SessionRegistry struct {
+	kv     nats.KeyValue
+	config SessionRegistryConfig
+	logger *slog.Logger
+}
+
+// NewSessionRegistry creates a session registry, creating its bucket if necessary
+func NewSessionRegistry(conn *nats.Conn, logger *slog.Logger, opts ...SessionRegistryOption) (*SessionRegistry, error) {
+	config := SessionRegistryConfig{
+		Bucket: "speakr_recording_sessions",
+		TTL:    7 * 24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(config.Bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		logger.Info("Session bucket does not exist, creating it", "bucket", config.Bucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{
+			Bucket:      config.Bucket,
+			Description: "Speakr recording sessions",
+			TTL:         config.TTL,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session bucket %s: %w", config.Bucket, err)
+	}
+
+	return &SessionRegistry{
+		kv:     kv,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// SaveSession creates or replaces a recording session
+func (r *SessionRegistry) SaveSession(ctx context.Context, session ports.RecordingSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording session: %w", err)
+	}
+
+	if _, err := r.kv.Put(session.RecordingID, data); err != nil {
+		r.logger.Error("Failed to save recording session", "recording_id", session.RecordingID, "error", err)
+		return fmt.Errorf("failed to save recording session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession returns the recording session, or nil if it is unknown
+func (r *SessionRegistry) GetSession(ctx context.Context, recordingID string) (*ports.RecordingSession, error) {
+	entry, err := r.kv.Get(recordingID)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get recording session", "recording_id", recordingID, "error", err)
+		return nil, fmt.Errorf("failed to get recording session: %w", err)
+	}
+
+	var session ports.RecordingSession
+	if err := json.Unmarshal(entry.Value(), &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recording session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// DeleteSession removes a recording session
+func (r *SessionRegistry) DeleteSession(ctx context.Context, recordingID string) error {
+	if err := r.kv.Delete(recordingID); err != nil && !errors.Is(err, nats.ErrKeyNotFound) {
+		r.logger.Error("Failed to delete recording session", "recording_id", recordingID, "error", err)
+		return fmt.Errorf("failed to delete recording session: %w", err)
+	}
+
+	return nil
+}
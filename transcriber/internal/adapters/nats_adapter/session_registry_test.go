@@ -0,0 +1,79 @@
+package nats_adapter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestSessionRegistryWithOptions(t *testing.T) {
+	config := SessionRegistryConfig{}
+
+	opts := []SessionRegistryOption{
+		WithSessionBucket("test_sessions"),
+		WithSessionTTL(time.Hour),
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.Bucket != "test_sessions" {
+		t.Errorf("Expected bucket 'test_sessions', got %s", config.Bucket)
+	}
+
+	if config.TTL != time.Hour {
+		t.Errorf("Expected TTL 1h, got %v", config.TTL)
+	}
+}
+
+func TestSessionRegistryOperations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	conn, err := nats.Connect("nats://localhost:4222", nats.Timeout(time.Second))
+	if err != nil {
+		t.Skipf("NATS not available, skipping integration test: %v", err)
+	}
+	defer conn.Close()
+
+	registry, err := NewSessionRegistry(conn, logger, WithSessionBucket("speakr_test_sessions"))
+	if err != nil {
+		t.Skipf("JetStream not available, skipping integration test: %v", err)
+	}
+
+	ctx := context.Background()
+	session := ports.RecordingSession{
+		RecordingID: "test-recording-123",
+		Format:      "wav",
+		Tags:        []string{"project-x"},
+		StartedAt:   time.Now().UTC(),
+	}
+
+	if err := registry.SaveSession(ctx, session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	got, err := registry.GetSession(ctx, session.RecordingID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	if got == nil || len(got.Tags) != 1 || got.Tags[0] != "project-x" {
+		t.Errorf("Unexpected session: %+v", got)
+	}
+
+	if err := registry.DeleteSession(ctx, session.RecordingID); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+
+	got, err = registry.GetSession(ctx, session.RecordingID)
+	if err != nil || got != nil {
+		t.Errorf("Expected deleted session to be unknown, got %+v, %v", got, err)
+	}
+}
@@ -3,116 +3,586 @@ package nats_adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
 
-	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
 )
 
+// Roles a transcriber can take on. Each role subscribes to its own commands, so
+// recording and transcription can be deployed and scaled separately.
+const (
+	// RoleRecording handles the recording commands on the host that owns the microphone
+	RoleRecording = "recording"
+	// RoleTranscription handles transcription.run in a queue group shared by all replicas
+	RoleTranscription = "transcription"
+)
+
+// recordingActions are the commands of the recording role, subscribed as
+// speakr.command.recording.<action>
+var recordingActions = []string{"start", "stop", "cancel", "pause", "resume", "status", "list"}
+
+// transcriptionSubject is the command consumed by the transcription workers
+const transcriptionSubject = "speakr.command.transcription.run"
+
+// SubscriberConfig holds configuration for the NATS subscriber
+type SubscriberConfig struct {
+	Roles                 []string
+	RecorderID            string
+	TranscriptionQueue    string
+	TranscriptionWorkers  int
+	PendingTranscriptions int
+	JetStream             nats.JetStreamContext
+	MaxDeliver            int
+	Backoff               []time.Duration
+	AckWait               time.Duration
+	DeadLetters           *DeadLetterQueue
+}
+
+// SubscriberOption is a functional option for configuring the subscriber
+type SubscriberOption func(*SubscriberConfig)
+
+// WithRoles sets the roles the subscriber handles commands for
+func WithRoles(roles ...string) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.Roles = roles
+	}
+}
+
+// WithRecorderID routes the recording commands of this host to
+// speakr.recorder.<id>.command.recording.<action> instead of the shared subjects,
+// so several hosts with a microphone can run side by side
+func WithRecorderID(recorderID string) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.RecorderID = recorderID
+	}
+}
+
+// WithTranscriptionQueue sets the queue group the transcription replicas share
+func WithTranscriptionQueue(queue string) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.TranscriptionQueue = queue
+	}
+}
+
+// WithTranscriptionWorkers sets how many transcriptions run at once on this replica
+func WithTranscriptionWorkers(workers int) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.TranscriptionWorkers = workers
+	}
+}
+
+// WithPendingTranscriptions sets how many transcription commands core NATS holds for
+// this replica while every worker is busy, counting the one waiting for a worker.
+// Commands beyond it are dropped and logged; jetstream mode keeps them in the stream
+// instead.
+func WithPendingTranscriptions(pending int) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.PendingTranscriptions = pending
+	}
+}
+
+// WithJetStream consumes transcription.run from the command stream through a durable
+// consumer instead of a core NATS queue group
+func WithJetStream(js nats.JetStreamContext) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.JetStream = js
+	}
+}
+
+// WithMaxDeliver sets how often a command from the stream is delivered before it is given up
+func WithMaxDeliver(maxDeliver int) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.MaxDeliver = maxDeliver
+	}
+}
+
+// WithBackoff sets the delays before each redelivery of a failed command; the last
+// one repeats
+func WithBackoff(backoff ...time.Duration) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.Backoff = backoff
+	}
+}
+
+// WithAckWait sets how long a worker may go without reporting progress before its
+// command is delivered to another worker
+func WithAckWait(ackWait time.Duration) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.AckWait = ackWait
+	}
+}
+
+// WithDeadLetterQueue sends commands that fail for good to the dead-letter queue and
+// answers its admin commands
+func WithDeadLetterQueue(deadLetters *DeadLetterQueue) SubscriberOption {
+	return func(c *SubscriberConfig) {
+		c.DeadLetters = deadLetters
+	}
+}
+
 // Subscriber handles NATS message subscriptions
 type Subscriber struct {
 	conn    *nats.Conn
 	service *core.Service
+	config  SubscriberConfig
 	logger  *slog.Logger
+
+	commands      map[string]string // command subject by subscribed subject
+	subscriptions []*nats.Subscription
+	workers       chan struct{} // one slot per running transcription
+	inFlight      sync.WaitGroup
+	inFlightMu    sync.Mutex // orders new transcriptions before Close waits for them
+	closing       bool
+	stopFetching  context.CancelFunc // stops the stream workers
+
+	droppedMu sync.Mutex
+	dropped   map[*nats.Subscription]int // dropped commands already counted, by subscription
 }
 
 // NewSubscriber creates a new NATS subscriber
-func NewSubscriber(conn *nats.Conn, service *core.Service, logger *slog.Logger) *Subscriber {
+func NewSubscriber(conn *nats.Conn, service *core.Service, logger *slog.Logger, opts ...SubscriberOption) *Subscriber {
+	config := SubscriberConfig{
+		Roles:                 []string{RoleRecording, RoleTranscription},
+		TranscriptionQueue:    "speakr-transcribers",
+		TranscriptionWorkers:  2,
+		PendingTranscriptions: 16,
+		MaxDeliver:            5,
+		Backoff:               []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute},
+		AckWait:               30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	return &Subscriber{
 		conn:    conn,
 		service: service,
+		config:  config,
 		logger:  logger,
 	}
 }
 
-// Subscribe sets up subscriptions for all command subjects
+// Subscribe sets up subscriptions for the command subjects of the configured roles
 func (s *Subscriber) Subscribe(ctx context.Context) error {
-	subjects := []string{
-		"speakr.command.recording.start",
-		"speakr.command.recording.stop",
-		"speakr.command.recording.cancel",
-		"speakr.command.transcription.run",
+	if err := s.validateConfig(); err != nil {
+		return err
 	}
 
-	for _, subject := range subjects {
-		if _, err := s.conn.Subscribe(subject, s.handleMessage); err != nil {
+	// Every subject is known before the first message can arrive
+	s.commands = s.recordingSubjects()
+	s.workers = make(chan struct{}, s.config.TranscriptionWorkers)
+
+	for subject := range s.commands {
+		sub, err := s.conn.Subscribe(subject, s.handleMessage)
+		if err != nil {
 			return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
 		}
+		s.subscriptions = append(s.subscriptions, sub)
 		s.logger.Info("Subscribed to subject", "subject", subject)
 	}
 
+	if s.config.DeadLetters != nil {
+		for _, subject := range deadLetterSubjects {
+			sub, err := s.conn.QueueSubscribe(subject, deadLetterQueueGroup, s.handleDeadLetterMessage)
+			if err != nil {
+				return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+			}
+			s.subscriptions = append(s.subscriptions, sub)
+			s.logger.Info("Subscribed to subject", "subject", subject, "queue", deadLetterQueueGroup)
+		}
+	}
+
+	if slices.Contains(s.config.Roles, RoleTranscription) && s.config.JetStream != nil {
+		return s.consumeTranscriptions(ctx)
+	}
+
+	if slices.Contains(s.config.Roles, RoleTranscription) {
+		sub, err := s.conn.QueueSubscribe(transcriptionSubject, s.config.TranscriptionQueue, s.dispatchTranscription)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", transcriptionSubject, err)
+		}
+		// Bound the backlog a busy replica holds; each command may be as large as the
+		// server allows
+		pendingBytes := s.config.PendingTranscriptions * int(s.conn.MaxPayload())
+		if err := sub.SetPendingLimits(s.config.PendingTranscriptions, pendingBytes); err != nil {
+			return fmt.Errorf("failed to limit pending commands on %s: %w", transcriptionSubject, err)
+		}
+		s.conn.SetErrorHandler(s.handleAsyncError)
+		s.subscriptions = append(s.subscriptions, sub)
+		s.logger.Info("Subscribed to subject",
+			"subject", transcriptionSubject,
+			"queue", s.config.TranscriptionQueue,
+			"workers", s.config.TranscriptionWorkers,
+			"pending", s.config.PendingTranscriptions)
+	}
+
 	return nil
 }
 
-// handleMessage processes incoming NATS messages
+// Close stops receiving commands and waits for running transcriptions to finish
+func (s *Subscriber) Close(ctx context.Context) error {
+	if s.stopFetching != nil {
+		s.stopFetching()
+	}
+	for _, sub := range s.subscriptions {
+		if err := sub.Unsubscribe(); err != nil {
+			s.logger.Warn("Failed to unsubscribe", "subject", sub.Subject, "error", err)
+		}
+	}
+
+	s.inFlightMu.Lock()
+	s.closing = true
+	s.inFlightMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("transcriptions still running: %w", ctx.Err())
+	}
+}
+
+// validateConfig checks the roles, recorder ID and worker count
+func (s *Subscriber) validateConfig() error {
+	if len(s.config.Roles) == 0 {
+		return errNoRoles
+	}
+	for _, role := range s.config.Roles {
+		if role != RoleRecording && role != RoleTranscription {
+			return fmt.Errorf("%w: %q", errUnknownRole, role)
+		}
+	}
+	if strings.ContainsAny(s.config.RecorderID, ".*> \t") {
+		return fmt.Errorf("%w: %q", errInvalidRecorderID, s.config.RecorderID)
+	}
+	if s.config.TranscriptionWorkers < 1 {
+		return fmt.Errorf("%w: %d", errInvalidWorkers, s.config.TranscriptionWorkers)
+	}
+	if s.config.PendingTranscriptions < 1 {
+		return fmt.Errorf("%w: %d", errInvalidPending, s.config.PendingTranscriptions)
+	}
+	if s.config.JetStream != nil && (s.config.MaxDeliver < 1 || s.config.AckWait <= 0) {
+		return fmt.Errorf("%w: max deliver %d, ack wait %s", errInvalidDelivery, s.config.MaxDeliver, s.config.AckWait)
+	}
+	return nil
+}
+
+// recordingSubjects maps the subjects of the recording role to their command subjects
+func (s *Subscriber) recordingSubjects() map[string]string {
+	subjects := make(map[string]string)
+	if !slices.Contains(s.config.Roles, RoleRecording) {
+		return subjects
+	}
+
+	for _, action := range recordingActions {
+		command := "speakr.command.recording." + action
+		if s.config.RecorderID != "" {
+			subjects["speakr.recorder."+s.config.RecorderID+".command.recording."+action] = command
+		} else {
+			subjects[command] = command
+		}
+	}
+	return subjects
+}
+
+// dispatchTranscription hands a transcription to a free worker. While every worker
+// is busy it blocks, and NATS holds up to PendingTranscriptions following commands for
+// this replica; it drops the rest, which handleAsyncError reports.
+func (s *Subscriber) dispatchTranscription(msg *nats.Msg) {
+	s.workers <- struct{}{}
+
+	// A command still delivered while closing is dropped like the ones behind it
+	s.inFlightMu.Lock()
+	if s.closing {
+		s.inFlightMu.Unlock()
+		<-s.workers
+		s.logger.Warn("Dropping transcription command received while closing", "subject", msg.Subject)
+		return
+	}
+	s.inFlight.Add(1)
+	s.inFlightMu.Unlock()
+
+	go func() {
+		defer func() {
+			<-s.workers
+			s.inFlight.Done()
+		}()
+		s.handleMessage(msg)
+	}()
+}
+
+// handleAsyncError logs errors NATS reports outside of any call. Commands dropped
+// because a subscription fell behind are gone without a reply, so they are counted.
+func (s *Subscriber) handleAsyncError(conn *nats.Conn, sub *nats.Subscription, err error) {
+	if sub == nil {
+		s.logger.Error("NATS connection error", "error", err)
+		return
+	}
+
+	logger := s.logger.With("subject", sub.Subject, "error", err)
+	if !errors.Is(err, nats.ErrSlowConsumer) {
+		logger.Error("NATS subscription error")
+		return
+	}
+
+	dropped, droppedErr := sub.Dropped()
+	if droppedErr != nil {
+		logger.Warn("Subscription fell behind, commands were dropped")
+		return
+	}
+	logger.Warn("Subscription fell behind, commands were dropped", "dropped_total", dropped)
+
+	// Dropped is a running total; only the increase since the last report is counted
+	s.droppedMu.Lock()
+	defer s.droppedMu.Unlock()
+	if s.dropped == nil {
+		s.dropped = make(map[*nats.Subscription]int)
+	}
+	if increase := dropped - s.dropped[sub]; increase > 0 {
+		commandsDropped.WithLabelValues(sub.Subject).Add(float64(increase))
+	}
+	s.dropped[sub] = dropped
+}
+
+// Errors returned by Subscribe for an invalid configuration
+var (
+	errNoRoles           = errors.New("no roles configured")
+	errUnknownRole       = errors.New("unknown role")
+	errInvalidRecorderID = errors.New("recorder ID must be a single subject token")
+	errInvalidWorkers    = errors.New("at least one transcription worker is required")
+	errInvalidPending    = errors.New("at least one pending transcription is required")
+	errInvalidDelivery   = errors.New("stream delivery needs at least one delivery and a positive ack wait")
+)
+
+// errUnknownCommand is returned for messages on subjects the subscriber does not handle
+var errUnknownCommand = errors.New("unknown command subject")
+
+// handleMessage processes incoming NATS messages and replies when the sender used request-reply
 func (s *Subscriber) handleMessage(msg *nats.Msg) {
-	correlationID := uuid.New().String()
-	ctx := context.WithValue(context.Background(), "correlation_id", correlationID)
+	ctx, logger := s.messageContext(msg)
+	ctx, span := startConsumerSpan(ctx, msg.Subject)
+	reply, err := s.handleCommand(ctx, logger, msg.Subject, msg.Data)
+	s.reply(ctx, logger, msg, reply)
+
+	if err != nil && s.deadLetterable(msg.Subject, err) {
+		s.deadLetter(ctx, msg, err, coreDeliveryAttempts)
+	}
+	endSpan(span, err)
+}
+
+// coreDeliveryAttempts is how often core NATS delivers a command: once, as it does not
+// redeliver, so a command that fails is dead-lettered after its only attempt
+const coreDeliveryAttempts = 1
+
+// deadLetterable reports whether core NATS sends a failed command to the dead-letter
+// queue: payloads that cannot be parsed, and transcriptions that failed for a reason
+// a replay may get past. Other failures, such as a rejected option or a recording
+// command, are only answered, as replaying them cannot succeed.
+func (s *Subscriber) deadLetterable(subject string, err error) bool {
+	code := errorCodeFor(err)
+	if code == ErrorCodeInvalidPayload {
+		return true
+	}
+	return s.commandSubject(subject) == transcriptionSubject && retryableCodes[code]
+}
+
+// deadLetter sends a command that failed for good to the dead-letter queue
+func (s *Subscriber) deadLetter(ctx context.Context, msg *nats.Msg, err error, attempts int) {
+	if s.config.DeadLetters != nil {
+		s.config.DeadLetters.Publish(ctx, msg.Subject, msg.Data, err, attempts)
+	}
+}
+
+// handleDeadLetterMessage answers a dead-letter admin command
+func (s *Subscriber) handleDeadLetterMessage(msg *nats.Msg) {
+	ctx, logger := s.messageContext(msg)
+	ctx, span := startConsumerSpan(ctx, msg.Subject)
+	logger.Info("Received message", "data", string(msg.Data))
+
+	reply, err := s.config.DeadLetters.handleCommand(msg.Subject, msg.Data)
+	if err != nil {
+		logger.Error("Failed to handle message", "error", err)
+		reply = newCommandReply("", err)
+	}
+	s.reply(ctx, logger, msg, reply)
+	commandsHandled.WithLabelValues(msg.Subject, commandOutcome(err)).Inc()
+	endSpan(span, err)
+}
+
+// messageContext starts the context and logger of a received message, which keeps
+// the sender's correlation ID and trace, and becomes the cause of everything
+// published for it
+func (s *Subscriber) messageContext(msg *nats.Msg) (context.Context, *slog.Logger) {
+	messageID, correlationID := messageIDs(msg)
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier(msg.Header))
+	ctx = ports.WithCorrelationID(ctx, correlationID)
+	if messageID != "" {
+		ctx = ports.WithCausationID(ctx, messageID)
+	}
 	
 	logger := s.logger.With(
 		"correlation_id", correlationID,
+		"message_id", messageID,
 		"subject", msg.Subject,
 	)
+	return ctx, logger
+}
 
-	logger.Info("Received message", "data", string(msg.Data))
+// handleCommand runs the command received on a subject and returns its reply
+func (s *Subscriber) handleCommand(ctx context.Context, logger *slog.Logger, subject string, data []byte) (interface{}, error) {
+	logger.Info("Received message", "data", string(data))
 
+	var recordingID string
+	var recording *core.RecordingInfo
+	var recordings []core.RecordingInfo
 	var err error
-	switch msg.Subject {
+	switch s.commandSubject(subject) {
 	case "speakr.command.recording.start":
-		err = s.handleStartRecording(ctx, msg.Data)
+		recordingID, err = s.handleStartRecording(ctx, data)
 	case "speakr.command.recording.stop":
-		err = s.handleStopRecording(ctx, msg.Data)
+		recordingID, err = s.handleStopRecording(ctx, data)
 	case "speakr.command.recording.cancel":
-		err = s.handleCancelRecording(ctx, msg.Data)
+		recordingID, err = s.handleCancelRecording(ctx, data)
+	case "speakr.command.recording.pause":
+		recordingID, err = s.handlePauseRecording(ctx, data)
+	case "speakr.command.recording.resume":
+		recordingID, err = s.handleResumeRecording(ctx, data)
 	case "speakr.command.transcription.run":
-		err = s.handleTranscription(ctx, msg.Data)
+		recordingID, err = s.handleTranscription(ctx, data)
+	case "speakr.command.recording.status":
+		recordingID, recording, err = s.handleRecordingStatus(ctx, data)
+	case "speakr.command.recording.list":
+		recordings, err = s.service.ListRecordings(ctx)
 	default:
-		logger.Error("Unknown subject", "subject", msg.Subject)
-		return
+		logger.Error("Unknown subject", "subject", subject)
+		err = errUnknownCommand
 	}
 
 	if err != nil {
-		logger.Error("Failed to handle message", "error", err)
+		logger.Error("Failed to handle message", "error", err, "recording_id", recordingID)
 	} else {
-		logger.Info("Message handled successfully")
+		logger.Info("Message handled successfully", "recording_id", recordingID)
+	}
+	commandsHandled.WithLabelValues(s.commandSubject(subject), commandOutcome(err)).Inc()
+
+	reply := newCommandReply(recordingID, err)
+	reply.Recording = recording
+	if recordings != nil {
+		return RecordingListReply{CommandReply: reply, Recordings: recordings}, err
+	}
+	return reply, err
+}
+
+// commandSubject returns the command a message was received for, resolving the
+// subjects of a named recorder to the shared command subjects
+func (s *Subscriber) commandSubject(subject string) string {
+	if command, ok := s.commands[subject]; ok {
+		return command
 	}
+	return subject
 }
 
-func (s *Subscriber) handleStartRecording(ctx context.Context, data []byte) error {
+// reply sends the command reply if the sender is waiting for one
+func (s *Subscriber) reply(ctx context.Context, logger *slog.Logger, msg *nats.Msg, reply interface{}) {
+	if msg.Reply == "" {
+		return
+	}
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		logger.Error("Failed to marshal command reply", "error", err)
+		return
+	}
+
+	if err := msg.RespondMsg(newMsg(ctx, msg.Reply, data)); err != nil {
+		logger.Error("Failed to send command reply", "error", err)
+	}
+}
+
+func (s *Subscriber) handleStartRecording(ctx context.Context, data []byte) (string, error) {
 	var cmd core.StartRecordingCommand
 	if err := json.Unmarshal(data, &cmd); err != nil {
-		return fmt.Errorf("failed to unmarshal start recording command: %w", err)
+		return "", fmt.Errorf("failed to unmarshal start recording command: %w", err)
 	}
 
 	return s.service.StartRecording(ctx, cmd)
 }
 
-func (s *Subscriber) handleStopRecording(ctx context.Context, data []byte) error {
+func (s *Subscriber) handleStopRecording(ctx context.Context, data []byte) (string, error) {
 	var cmd core.StopRecordingCommand
 	if err := json.Unmarshal(data, &cmd); err != nil {
-		return fmt.Errorf("failed to unmarshal stop recording command: %w", err)
+		return "", fmt.Errorf("failed to unmarshal stop recording command: %w", err)
 	}
 
-	return s.service.StopRecording(ctx, cmd)
+	return cmd.RecordingID, s.service.StopRecording(ctx, cmd)
 }
 
-func (s *Subscriber) handleCancelRecording(ctx context.Context, data []byte) error {
+func (s *Subscriber) handleCancelRecording(ctx context.Context, data []byte) (string, error) {
 	var cmd core.CancelRecordingCommand
 	if err := json.Unmarshal(data, &cmd); err != nil {
-		return fmt.Errorf("failed to unmarshal cancel recording command: %w", err)
+		return "", fmt.Errorf("failed to unmarshal cancel recording command: %w", err)
+	}
+
+	return cmd.RecordingID, s.service.CancelRecording(ctx, cmd)
+}
+
+func (s *Subscriber) handlePauseRecording(ctx context.Context, data []byte) (string, error) {
+	var cmd core.PauseRecordingCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return "", fmt.Errorf("failed to unmarshal pause recording command: %w", err)
 	}
 
-	return s.service.CancelRecording(ctx, cmd)
+	return cmd.RecordingID, s.service.PauseRecording(ctx, cmd)
 }
 
-func (s *Subscriber) handleTranscription(ctx context.Context, data []byte) error {
+func (s *Subscriber) handleResumeRecording(ctx context.Context, data []byte) (string, error) {
+	var cmd core.ResumeRecordingCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return "", fmt.Errorf("failed to unmarshal resume recording command: %w", err)
+	}
+
+	return cmd.RecordingID, s.service.ResumeRecording(ctx, cmd)
+}
+
+func (s *Subscriber) handleTranscription(ctx context.Context, data []byte) (string, error) {
 	var cmd core.TranscriptionCommand
 	if err := json.Unmarshal(data, &cmd); err != nil {
-		return fmt.Errorf("failed to unmarshal transcription command: %w", err)
+		return "", fmt.Errorf("failed to unmarshal transcription command: %w", err)
+	}
+
+	// A command from the stream may be delivered again, so its raw audio keeps one
+	// ID and only the last failure is published
+	if audioID, ok := streamDeliveryFrom(ctx); ok {
+		cmd.AudioID = audioID
+		cmd.DeferFailure = true
 	}
 
 	return s.service.TranscribeAudio(ctx, cmd)
-}
\ No newline at end of file
+}
+
+func (s *Subscriber) handleRecordingStatus(ctx context.Context, data []byte) (string, *core.RecordingInfo, error) {
+	var cmd core.RecordingStatusCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal recording status command: %w", err)
+	}
+
+	recording, err := s.service.RecordingStatus(ctx, cmd)
+	return cmd.RecordingID, recording, err
+}
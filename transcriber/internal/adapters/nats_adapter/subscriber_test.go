@@ -1,10 +1,25 @@
 package nats_adapter
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"speakr/transcriber/internal/adapters/memory_adapter"
 	"speakr/transcriber/internal/core"
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestHandleStartRecording_ValidJSON(t *testing.T) {
@@ -116,4 +131,307 @@ func TestContractCompliance_AllCommands(t *testing.T) {
 	if err := json.Unmarshal(data, &parsedTranscribeCmd); err != nil {
 		t.Fatalf("Failed to unmarshal transcribe command: %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestSubscriber_RecordingSubjects(t *testing.T) {
+	shared := NewSubscriber(nil, nil, nil)
+	subjects := shared.recordingSubjects()
+	if len(subjects) != len(recordingActions) {
+		t.Fatalf("Expected %d recording subjects, got %d", len(recordingActions), len(subjects))
+	}
+	if subjects["speakr.command.recording.start"] != "speakr.command.recording.start" {
+		t.Errorf("Expected the shared start subject, got %v", subjects)
+	}
+
+	named := NewSubscriber(nil, nil, nil, WithRecorderID("desk"))
+	named.commands = named.recordingSubjects()
+	if command := named.commandSubject("speakr.recorder.desk.command.recording.stop"); command != "speakr.command.recording.stop" {
+		t.Errorf("Expected the stop command, got %s", command)
+	}
+	if _, ok := named.commands["speakr.command.recording.start"]; ok {
+		t.Error("Expected a named recorder not to subscribe to the shared subjects")
+	}
+
+	transcriber := NewSubscriber(nil, nil, nil, WithRoles(RoleTranscription))
+	if subjects := transcriber.recordingSubjects(); len(subjects) != 0 {
+		t.Errorf("Expected no recording subjects for the transcription role, got %v", subjects)
+	}
+}
+
+func TestSubscriber_DeadLetterable(t *testing.T) {
+	subscriber := NewSubscriber(nil, nil, nil)
+	invalidPayload := fmt.Errorf("failed to unmarshal command: %w", &json.SyntaxError{})
+
+	tests := []struct {
+		name    string
+		subject string
+		err     error
+		want    bool
+	}{
+		{"unparseable recording command", "speakr.command.recording.start", invalidPayload, true},
+		{"failed recording command", "speakr.command.recording.stop", core.ErrRecordingNotFound, false},
+		{"unparseable transcription", transcriptionSubject, invalidPayload, true},
+		{"provider outage", transcriptionSubject, fmt.Errorf("request failed: %w", ports.ErrProviderUnavailable), true},
+		{"rejected option", transcriptionSubject, core.ErrInvalidTask, false},
+		{"unknown profile", transcriptionSubject, core.ErrUnknownProfile, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subscriber.deadLetterable(tt.subject, tt.err); got != tt.want {
+				t.Errorf("Expected dead-lettering %v for %v, got %v", tt.want, tt.err, got)
+			}
+		})
+	}
+}
+
+func TestSubscriber_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []SubscriberOption
+		wantErr error
+	}{
+		{"defaults", nil, nil},
+		{"both roles with a recorder", []SubscriberOption{WithRoles(RoleRecording, RoleTranscription), WithRecorderID("studio-1")}, nil},
+		{"no roles", []SubscriberOption{WithRoles()}, errNoRoles},
+		{"unknown role", []SubscriberOption{WithRoles("embedding")}, errUnknownRole},
+		{"recorder with a dot", []SubscriberOption{WithRecorderID("studio.1")}, errInvalidRecorderID},
+		{"recorder with a wildcard", []SubscriberOption{WithRecorderID("*")}, errInvalidRecorderID},
+		{"no workers", []SubscriberOption{WithTranscriptionWorkers(0)}, errInvalidWorkers},
+		{"no pending transcriptions", []SubscriberOption{WithPendingTranscriptions(0)}, errInvalidPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSubscriber(nil, nil, nil, tt.opts...).validateConfig()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubscriber_DispatchTranscriptionBoundsWorkers(t *testing.T) {
+	subscriber := NewSubscriber(nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), WithTranscriptionWorkers(1))
+	subscriber.workers = make(chan struct{}, 1)
+
+	// Occupy the only worker so the next transcription has to wait for it
+	subscriber.workers <- struct{}{}
+
+	dispatched := make(chan struct{})
+	go func() {
+		subscriber.dispatchTranscription(&nats.Msg{Subject: "speakr.command.unknown"})
+		close(dispatched)
+	}()
+
+	select {
+	case <-dispatched:
+		t.Fatal("Expected dispatch to wait for a free worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-subscriber.workers
+	<-dispatched
+
+	if err := subscriber.Close(context.Background()); err != nil {
+		t.Errorf("Expected close to wait for the transcription, got %v", err)
+	}
+}
+
+// fakeServer speaks just enough of the NATS protocol to accept a client, hand back
+// the payloads it publishes and deliver messages to its subscriptions
+type fakeServer struct {
+	listener   net.Listener
+	published  chan *nats.Msg
+	subscribed chan string // subscription IDs
+	conn       net.Conn
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &fakeServer{listener: listener, published: make(chan *nats.Msg, 16), subscribed: make(chan string, 16)}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (f *fakeServer) url() string {
+	return "nats://" + f.listener.Addr().String()
+}
+
+func (f *fakeServer) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	f.conn = conn
+
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"version\":\"2.10.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576}\r\n")
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			fmt.Fprintf(conn, "PONG\r\n")
+		case "SUB":
+			f.subscribed <- fields[len(fields)-1]
+		case "PUB", "HPUB":
+			headerLen := 0
+			if fields[0] == "HPUB" {
+				headerLen, _ = strconv.Atoi(fields[len(fields)-2])
+			}
+			totalLen, _ := strconv.Atoi(fields[len(fields)-1])
+			data := make([]byte, totalLen+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			f.published <- &nats.Msg{Subject: fields[1], Data: data[headerLen:totalLen]}
+		}
+	}
+}
+
+// deliver sends a message to a subscription; it must follow a receive from subscribed
+func (f *fakeServer) deliver(t *testing.T, sid, subject, data string) {
+	t.Helper()
+	if _, err := fmt.Fprintf(f.conn, "MSG %s %s %d\r\n%s\r\n", subject, sid, len(data), data); err != nil {
+		t.Fatalf("Failed to deliver: %v", err)
+	}
+}
+
+// stubRecorder records nothing and hands back fixed audio
+type stubRecorder struct{}
+
+func (stubRecorder) StartRecording(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+	return nil
+}
+func (stubRecorder) StopRecording(ctx context.Context, recordingID string) (io.Reader, error) {
+	return strings.NewReader("audio"), nil
+}
+func (stubRecorder) CancelRecording(ctx context.Context, recordingID string) error { return nil }
+func (stubRecorder) PauseRecording(ctx context.Context, recordingID string) error  { return nil }
+func (stubRecorder) ResumeRecording(ctx context.Context, recordingID string) error { return nil }
+func (stubRecorder) ListRecordings(ctx context.Context) ([]ports.RecordingStatus, error) {
+	return nil, nil
+}
+
+func TestHandleMessage_RepliesOnInbox(t *testing.T) {
+	server := newFakeServer(t)
+	conn, err := nats.Connect(server.url())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := core.NewService(stubRecorder{}, nil, nil, memory_adapter.NewSessionRegistry(), NewPublisher(conn, logger), logger)
+	subscriber := NewSubscriber(conn, service, logger)
+	subscriber.commands = subscriber.recordingSubjects()
+
+	sub, err := conn.SubscribeSync("speakr.command.recording.start")
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	// replyTo runs a command as if it had been sent with request-reply and returns the reply
+	replyTo := func(subject, data string) CommandReply {
+		t.Helper()
+		subscriber.handleMessage(&nats.Msg{Subject: subject, Reply: "_INBOX.test", Data: []byte(data), Sub: sub})
+		if err := conn.Flush(); err != nil {
+			t.Fatalf("Failed to flush: %v", err)
+		}
+		for {
+			select {
+			case msg := <-server.published:
+				if msg.Subject != "_INBOX.test" {
+					continue // an event published while handling the command
+				}
+				var reply CommandReply
+				if err := json.Unmarshal(msg.Data, &reply); err != nil {
+					t.Fatalf("Failed to unmarshal reply %q: %v", msg.Data, err)
+				}
+				return reply
+			case <-time.After(time.Second):
+				t.Fatal("Expected a reply on the inbox")
+			}
+		}
+	}
+
+	started := replyTo("speakr.command.recording.start", `{"output_format":"wav"}`)
+	if started.Status != ReplyStatusOK || started.RecordingID == "" {
+		t.Errorf("Expected an ok reply with a recording ID, got %+v", started)
+	}
+
+	failed := replyTo("speakr.command.recording.stop", `{"recording_id":`)
+	if failed.Status != ReplyStatusError || failed.Error == nil || failed.Error.Code != ErrorCodeInvalidPayload {
+		t.Errorf("Expected an invalid_payload reply, got %+v", failed)
+	}
+}
+
+func TestSubscriber_DropsTranscriptionsBeyondPendingLimit(t *testing.T) {
+	server := newFakeServer(t)
+	conn, err := nats.Connect(server.url())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	subscriber := NewSubscriber(conn, nil, logger,
+		WithRoles(RoleTranscription),
+		WithTranscriptionWorkers(1),
+		WithPendingTranscriptions(2),
+	)
+	if err := subscriber.Subscribe(context.Background()); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	sid := <-server.subscribed
+
+	if msgs, _, err := subscriber.subscriptions[0].PendingLimits(); err != nil || msgs != 2 {
+		t.Fatalf("Expected a pending limit of two commands, got %d (%v)", msgs, err)
+	}
+
+	dropped := testutil.ToFloat64(commandsDropped.WithLabelValues(transcriptionSubject))
+
+	// With the only worker busy, one command waits for it, one is pending and the
+	// third is dropped
+	subscriber.workers <- struct{}{}
+	for i := 0; i < 3; i++ {
+		server.deliver(t, sid, transcriptionSubject, "{")
+	}
+
+	waitFor(t, func() bool {
+		return testutil.ToFloat64(commandsDropped.WithLabelValues(transcriptionSubject)) > dropped
+	})
+	if got := testutil.ToFloat64(commandsDropped.WithLabelValues(transcriptionSubject)); got != dropped+1 {
+		t.Errorf("Expected one dropped command to be counted, got %v", got-dropped)
+	}
+
+	<-subscriber.workers
+	if err := subscriber.Close(context.Background()); err != nil {
+		t.Errorf("Expected close to wait for the transcriptions, got %v", err)
+	}
+}
+
+// waitFor polls until done reports true, failing the test after a second
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for !done() {
+		select {
+		case <-deadline:
+			t.Fatal("Timed out waiting")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package nats_adapter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records the messages the adapter publishes and handles. The trace context
+// travels in the message headers, so the spans of every service join one trace.
+var tracer = otel.Tracer("speakr/transcriber/internal/adapters/nats_adapter")
+
+// startProducerSpan starts the span of publishing a message to a subject
+func startProducerSpan(ctx context.Context, subject string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "publish "+subject,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messagingAttributes("publish", subject)...),
+	)
+}
+
+// startConsumerSpan starts the span of handling a received message; ctx carries the
+// trace context of its sender
+func startConsumerSpan(ctx context.Context, subject string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "process "+subject,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messagingAttributes("process", subject)...),
+	)
+}
+
+// messagingAttributes describes a NATS operation on a subject
+func messagingAttributes(operation, subject string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "nats"),
+		attribute.String("messaging.operation", operation),
+		attribute.String("messaging.destination.name", subject),
+	}
+}
+
+// endSpan ends a span, marking it failed when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
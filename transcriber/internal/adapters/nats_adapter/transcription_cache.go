@@ -0,0 +1,115 @@
+package nats_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+// TranscriptionCacheConfig holds configuration for the NATS key-value transcription cache
+type TranscriptionCacheConfig struct {
+	Bucket string
+	TTL    time.Duration
+}
+
+// TranscriptionCacheOption is a functional option for configuring the transcription cache
+type TranscriptionCacheOption func(*TranscriptionCacheConfig)
+
+// WithCacheBucket sets the key-value bucket that holds cached transcripts
+func WithCacheBucket(bucket string) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.Bucket = bucket
+	}
+}
+
+// WithCacheTTL sets how long a transcript is reused
+func WithCacheTTL(ttl time.Duration) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.TTL = ttl
+	}
+}
+
+// TranscriptionCache implements the TranscriptionCache port using a JetStream key-value
+// bucket, shared by every transcriber connected to the same NATS
+type TranscriptionCache struct {
+	kv     nats.KeyValue
+	config TranscriptionCacheConfig
+	logger *slog.Logger
+}
+
+// NewTranscriptionCache creates a transcription cache, creating its bucket if necessary
+func NewTranscriptionCache(conn *nats.Conn, logger *slog.Logger, opts ...TranscriptionCacheOption) (*TranscriptionCache, error) {
+	config := TranscriptionCacheConfig{
+		Bucket: "speakr_transcription_cache",
+		TTL:    30 * 24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	kv, err := js.KeyValue(config.Bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		logger.Info("Transcription cache bucket does not exist, creating it", "bucket", config.Bucket)
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{
+			Bucket:      config.Bucket,
+			Description: "Speakr transcripts by audio hash and options",
+			TTL:         config.TTL,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcription cache bucket %s: %w", config.Bucket, err)
+	}
+
+	return &TranscriptionCache{
+		kv:     kv,
+		config: config,
+		logger: logger,
+	}, nil
+}
+
+// GetTranscription returns the cached transcript, or nil if there is none
+func (c *TranscriptionCache) GetTranscription(ctx context.Context, key string) (*ports.TranscriptionResult, error) {
+	entry, err := c.kv.Get(key)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return nil, nil
+		}
+		c.logger.Error("Failed to get cached transcription", "cache_key", key, "error", err)
+		return nil, fmt.Errorf("failed to get cached transcription: %w", err)
+	}
+
+	var result ports.TranscriptionResult
+	if err := json.Unmarshal(entry.Value(), &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached transcription: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SaveTranscription caches a transcript
+func (c *TranscriptionCache) SaveTranscription(ctx context.Context, key string, result *ports.TranscriptionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcription: %w", err)
+	}
+
+	if _, err := c.kv.Put(key, data); err != nil {
+		c.logger.Error("Failed to cache transcription", "cache_key", key, "error", err)
+		return fmt.Errorf("failed to cache transcription: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package nats_adapter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestTranscriptionCacheWithOptions(t *testing.T) {
+	config := TranscriptionCacheConfig{}
+
+	opts := []TranscriptionCacheOption{
+		WithCacheBucket("test_cache"),
+		WithCacheTTL(time.Hour),
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.Bucket != "test_cache" {
+		t.Errorf("Expected bucket 'test_cache', got %s", config.Bucket)
+	}
+
+	if config.TTL != time.Hour {
+		t.Errorf("Expected TTL 1h, got %v", config.TTL)
+	}
+}
+
+func TestTranscriptionCacheOperations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	conn, err := nats.Connect("nats://localhost:4222", nats.Timeout(time.Second))
+	if err != nil {
+		t.Skipf("NATS not available, skipping integration test: %v", err)
+	}
+	defer conn.Close()
+
+	cache, err := NewTranscriptionCache(conn, logger, WithCacheBucket("speakr_test_transcription_cache"))
+	if err != nil {
+		t.Skipf("JetStream not available, skipping integration test: %v", err)
+	}
+
+	ctx := context.Background()
+	result := &ports.TranscriptionResult{
+		Text:     "hello",
+		Segments: []ports.TranscriptSegment{{Start: 0, End: 1, Text: "hello"}},
+	}
+
+	if err := cache.SaveTranscription(ctx, "test-key.0123", result); err != nil {
+		t.Fatalf("Failed to save transcription: %v", err)
+	}
+
+	got, err := cache.GetTranscription(ctx, "test-key.0123")
+	if err != nil {
+		t.Fatalf("Failed to get transcription: %v", err)
+	}
+
+	if got == nil || got.Text != "hello" || len(got.Segments) != 1 {
+		t.Errorf("Unexpected transcription: %+v", got)
+	}
+
+	if got, err := cache.GetTranscription(ctx, "unknown-key"); err != nil || got != nil {
+		t.Errorf("Expected a miss for an unknown key, got %+v, %v", got, err)
+	}
+}
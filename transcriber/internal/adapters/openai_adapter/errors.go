@@ -1,16 +1,20 @@
 package openai_adapter
 
-import "errors"
+import (
+	"fmt"
+
+	"speakr/transcriber/internal/ports"
+)
 
 // Custom error types for OpenAI-specific failures
 var (
-	ErrAPIKeyNotSet         = errors.New("OpenAI API key not set")
-	ErrAPIKeyInvalid        = errors.New("OpenAI API key is invalid")
-	ErrQuotaExceeded        = errors.New("OpenAI API quota exceeded")
-	ErrAudioTooLarge        = errors.New("audio file exceeds OpenAI size limit (25MB)")
-	ErrInvalidAudioFormat   = errors.New("invalid audio format for OpenAI API")
-	ErrRequestTimeout       = errors.New("request to OpenAI API timed out")
-	ErrServiceUnavailable   = errors.New("OpenAI API service unavailable")
-	ErrEmptyTranscription   = errors.New("OpenAI API returned empty transcription")
-	ErrNetworkError         = errors.New("network error communicating with OpenAI API")
+	ErrAPIKeyNotSet         = fmt.Errorf("OpenAI API key not set: %w", ports.ErrProviderAuthFailed)
+	ErrAPIKeyInvalid        = fmt.Errorf("OpenAI API key is invalid: %w", ports.ErrProviderAuthFailed)
+	ErrQuotaExceeded        = fmt.Errorf("OpenAI API quota exceeded: %w", ports.ErrProviderQuotaExceeded)
+	ErrAudioTooLarge        = fmt.Errorf("audio file exceeds OpenAI size limit (25MB): %w", ports.ErrAudioTooLarge)
+	ErrInvalidAudioFormat   = fmt.Errorf("invalid audio format for OpenAI API: %w", ports.ErrUnsupportedAudio)
+	ErrRequestTimeout       = fmt.Errorf("request to OpenAI API timed out: %w", ports.ErrProviderTimeout)
+	ErrServiceUnavailable   = fmt.Errorf("OpenAI API service unavailable: %w", ports.ErrProviderUnavailable)
+	ErrEmptyTranscription   = fmt.Errorf("OpenAI API returned empty transcription: %w", ports.ErrNoSpeech)
+	ErrNetworkError         = fmt.Errorf("network error communicating with OpenAI API: %w", ports.ErrProviderUnavailable)
 )
\ No newline at end of file
@@ -0,0 +1,32 @@
+package openai_adapter
+
+import (
+	"errors"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestErrors_WrapPortSentinels(t *testing.T) {
+	tests := []struct {
+		err  error
+		port error
+	}{
+		{ErrAPIKeyNotSet, ports.ErrProviderAuthFailed},
+		{ErrAPIKeyInvalid, ports.ErrProviderAuthFailed},
+		{ErrQuotaExceeded, ports.ErrProviderQuotaExceeded},
+		{ErrQuotaExceeded, ports.ErrProviderUnavailable},
+		{ErrRequestTimeout, ports.ErrProviderTimeout},
+		{ErrServiceUnavailable, ports.ErrProviderUnavailable},
+		{ErrNetworkError, ports.ErrProviderUnavailable},
+		{ErrAudioTooLarge, ports.ErrAudioTooLarge},
+		{ErrInvalidAudioFormat, ports.ErrUnsupportedAudio},
+		{ErrEmptyTranscription, ports.ErrNoSpeech},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.port) {
+			t.Errorf("Expected %q to wrap %q", tt.err, tt.port)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 func TestMultipleProviders(t *testing.T) {
@@ -60,7 +62,7 @@ func TestMultipleProviders(t *testing.T) {
 			ctx := context.Background()
 			audioData := strings.NewReader("mock audio data")
 
-			_, err = transcriber.TranscribeAudio(ctx, audioData, "wav")
+			_, err = transcriber.TranscribeAudio(ctx, audioData, "wav", ports.TranscriptionOptions{})
 			
 			// We expect this to fail with mock data, but we can verify the error type
 			if err != nil {
@@ -9,8 +9,13 @@ import (
 	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // TranscriberConfig holds configuration for the OpenAI transcriber
@@ -20,6 +25,8 @@ type TranscriberConfig struct {
 	Model      string
 	Timeout    time.Duration
 	MaxRetries int
+	// WordTimestamps requests per-word timings in addition to segments
+	WordTimestamps bool
 }
 
 // TranscriberOption is a functional option for configuring the transcriber
@@ -60,6 +67,13 @@ func WithMaxRetries(maxRetries int) TranscriberOption {
 	}
 }
 
+// WithWordTimestamps enables or disables per-word timings
+func WithWordTimestamps(enabled bool) TranscriberOption {
+	return func(c *TranscriberConfig) {
+		c.WordTimestamps = enabled
+	}
+}
+
 // Transcriber implements the TranscriptionService port using OpenAI Whisper API
 type Transcriber struct {
 	config TranscriberConfig
@@ -89,8 +103,10 @@ func NewTranscriber(logger *slog.Logger, opts ...TranscriberOption) (*Transcribe
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
 
+	// Every request to the provider becomes a client span carrying the trace context
 	client := &http.Client{
-		Timeout: config.Timeout,
+		Timeout:   config.Timeout,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
 	return &Transcriber{
@@ -100,9 +116,10 @@ func NewTranscriber(logger *slog.Logger, opts ...TranscriberOption) (*Transcribe
 	}, nil
 }
 
-// TranscribeAudio transcribes audio using OpenAI Whisper API
-func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string) (string, error) {
-	logger := t.logger.With("model", t.config.Model, "format", format)
+// TranscribeAudio transcribes audio using OpenAI Whisper API, or translates it
+// into English when the translate task is requested
+func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	logger := t.logger.With("model", t.model(opts), "format", format, "task", opts.Task, "language", opts.Language)
 
 	logger.Info("Starting audio transcription")
 
@@ -110,13 +127,13 @@ func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader,
 	audioBytes, err := io.ReadAll(audioData)
 	if err != nil {
 		logger.Error("Failed to read audio data", "error", err)
-		return "", fmt.Errorf("failed to read audio data: %w", err)
+		return nil, fmt.Errorf("failed to read audio data: %w", err)
 	}
 
 	// Check audio size (OpenAI has a 25MB limit)
 	if len(audioBytes) > 25*1024*1024 {
 		logger.Error("Audio file too large", "size", len(audioBytes))
-		return "", ErrAudioTooLarge
+		return nil, ErrAudioTooLarge
 	}
 
 	// Attempt transcription with retries
@@ -124,10 +141,13 @@ func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader,
 	for attempt := 1; attempt <= t.config.MaxRetries; attempt++ {
 		logger.Info("Transcription attempt", "attempt", attempt, "max_retries", t.config.MaxRetries)
 
-		transcript, err := t.transcribeWithRetry(ctx, audioBytes, format)
+		result, err := t.transcribeWithRetry(ctx, audioBytes, format, opts)
 		if err == nil {
-			logger.Info("Transcription completed successfully", "text_length", len(transcript))
-			return transcript, nil
+			logger.Info("Transcription completed successfully",
+				"text_length", len(result.Text),
+				"segments", len(result.Segments),
+				"words", len(result.Words))
+			return result, nil
 		}
 
 		lastErr = err
@@ -148,11 +168,14 @@ func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader,
 	}
 
 	logger.Error("All transcription attempts failed", "error", lastErr)
-	return "", fmt.Errorf("transcription failed after %d attempts: %w", t.config.MaxRetries, lastErr)
+	return nil, fmt.Errorf("transcription failed after %d attempts: %w", t.config.MaxRetries, lastErr)
 }
 
 // transcribeWithRetry performs a single transcription attempt
-func (t *Transcriber) transcribeWithRetry(ctx context.Context, audioBytes []byte, format string) (string, error) {
+func (t *Transcriber) transcribeWithRetry(ctx context.Context, audioBytes []byte, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	translate := opts.Task == ports.TaskTranslate
+	model := t.model(opts)
+
 	// Create multipart form data
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
@@ -160,32 +183,69 @@ func (t *Transcriber) transcribeWithRetry(ctx context.Context, audioBytes []byte
 	// Add the audio file
 	part, err := writer.CreateFormFile("file", fmt.Sprintf("audio.%s", format))
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %w", err)
+		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
 
 	if _, err := part.Write(audioBytes); err != nil {
-		return "", fmt.Errorf("failed to write audio data: %w", err)
+		return nil, fmt.Errorf("failed to write audio data: %w", err)
 	}
 
 	// Add model parameter
-	if err := writer.WriteField("model", t.config.Model); err != nil {
-		return "", fmt.Errorf("failed to write model field: %w", err)
+	if err := writer.WriteField("model", model); err != nil {
+		return nil, fmt.Errorf("failed to write model field: %w", err)
+	}
+
+	if opts.Temperature > 0 {
+		if err := writer.WriteField("temperature", strconv.FormatFloat(opts.Temperature, 'f', -1, 64)); err != nil {
+			return nil, fmt.Errorf("failed to write temperature field: %w", err)
+		}
 	}
 
-	// Add response format
-	if err := writer.WriteField("response_format", "json"); err != nil {
-		return "", fmt.Errorf("failed to write response format field: %w", err)
+	// The translations endpoint always produces English and takes no language hint
+	if opts.Language != "" && !translate {
+		if err := writer.WriteField("language", opts.Language); err != nil {
+			return nil, fmt.Errorf("failed to write language field: %w", err)
+		}
+	}
+
+	if opts.Prompt != "" {
+		if err := writer.WriteField("prompt", opts.Prompt); err != nil {
+			return nil, fmt.Errorf("failed to write prompt field: %w", err)
+		}
+	}
+
+	// Add response format and timestamp granularities
+	verbose := supportsVerboseJSON(model)
+	responseFormat := "json"
+	if verbose {
+		responseFormat = "verbose_json"
+	}
+	if err := writer.WriteField("response_format", responseFormat); err != nil {
+		return nil, fmt.Errorf("failed to write response format field: %w", err)
+	}
+
+	// Translations return segments but do not accept timestamp granularities
+	if verbose && !translate {
+		for _, granularity := range t.timestampGranularities() {
+			if err := writer.WriteField("timestamp_granularities[]", granularity); err != nil {
+				return nil, fmt.Errorf("failed to write timestamp granularities field: %w", err)
+			}
+		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
 	// Create HTTP request
-	url := fmt.Sprintf("%s/audio/transcriptions", t.config.BaseURL)
+	endpoint := "transcriptions"
+	if translate {
+		endpoint = "translations"
+	}
+	url := fmt.Sprintf("%s/audio/%s", t.config.BaseURL, endpoint)
 	req, err := http.NewRequestWithContext(ctx, "POST", url, &buf)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -196,37 +256,81 @@ func (t *Transcriber) transcribeWithRetry(ctx context.Context, audioBytes []byte
 	resp, err := t.client.Do(req)
 	if err != nil {
 		if ctx.Err() != nil {
-			return "", ErrRequestTimeout
+			return nil, ErrRequestTimeout
 		}
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to make request: %w: %v", ErrNetworkError, err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Handle HTTP errors
 	if resp.StatusCode != http.StatusOK {
-		return "", t.handleHTTPError(resp.StatusCode, respBody)
+		return nil, t.handleHTTPError(resp.StatusCode, respBody)
 	}
 
 	// Parse successful response
-	var transcriptionResp struct {
-		Text string `json:"text"`
+	return parseTranscriptionResponse(respBody)
+}
+
+// model returns the model requested for this call, falling back to the configured one
+func (t *Transcriber) model(opts ports.TranscriptionOptions) string {
+	if opts.Model != "" {
+		return opts.Model
 	}
+	return t.config.Model
+}
 
-	if err := json.Unmarshal(respBody, &transcriptionResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+// timestampGranularities returns the timings requested alongside a verbose response
+func (t *Transcriber) timestampGranularities() []string {
+	if t.config.WordTimestamps {
+		return []string{"segment", "word"}
+	}
+	return []string{"segment"}
+}
+
+// supportsVerboseJSON reports whether a model can return timestamps. The GPT-4o
+// transcription models only support plain json.
+func supportsVerboseJSON(model string) bool {
+	return !strings.HasPrefix(model, "gpt-4o")
+}
+
+// transcriptionResponse is the json or verbose_json transcription response body
+type transcriptionResponse struct {
+	Text     string                    `json:"text"`
+	Language string                    `json:"language"`
+	Duration float64                   `json:"duration"`
+	Segments []ports.TranscriptSegment `json:"segments"`
+	Words    []ports.TranscriptWord    `json:"words"`
+}
+
+// parseTranscriptionResponse converts a transcription response body into a result
+func parseTranscriptionResponse(body []byte) (*ports.TranscriptionResult, error) {
+	var transcriptionResp transcriptionResponse
+	if err := json.Unmarshal(body, &transcriptionResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if transcriptionResp.Text == "" {
-		return "", ErrEmptyTranscription
+		return nil, ErrEmptyTranscription
+	}
+
+	segments := transcriptionResp.Segments
+	for i := range segments {
+		segments[i].Text = strings.TrimSpace(segments[i].Text)
 	}
 
-	return strings.TrimSpace(transcriptionResp.Text), nil
+	return &ports.TranscriptionResult{
+		Text:     strings.TrimSpace(transcriptionResp.Text),
+		Language: transcriptionResp.Language,
+		Duration: transcriptionResp.Duration,
+		Segments: segments,
+		Words:    transcriptionResp.Words,
+	}, nil
 }
 
 // handleHTTPError converts HTTP errors to appropriate error types
@@ -265,7 +369,7 @@ func (t *Transcriber) handleHTTPError(statusCode int, body []byte) error {
 // isNonRetryableError determines if an error should not be retried
 func isNonRetryableError(err error) bool {
 	switch err {
-	case ErrAPIKeyInvalid, ErrAPIKeyNotSet, ErrAudioTooLarge, ErrInvalidAudioFormat:
+	case ErrAPIKeyInvalid, ErrAPIKeyNotSet, ErrAudioTooLarge, ErrInvalidAudioFormat, ErrEmptyTranscription:
 		return true
 	default:
 		return false
@@ -2,11 +2,16 @@ package openai_adapter
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"speakr/transcriber/internal/ports"
 )
 
 func TestNewTranscriber(t *testing.T) {
@@ -76,7 +81,7 @@ func TestTranscribeAudio_InvalidInput(t *testing.T) {
 
 	// Test with audio that's too large (simulate 26MB)
 	largeAudio := strings.NewReader(strings.Repeat("a", 26*1024*1024))
-	_, err = transcriber.TranscribeAudio(ctx, largeAudio, "wav")
+	_, err = transcriber.TranscribeAudio(ctx, largeAudio, "wav", ports.TranscriptionOptions{})
 	if err != ErrAudioTooLarge {
 		t.Errorf("Expected ErrAudioTooLarge, got %v", err)
 	}
@@ -91,6 +96,7 @@ func TestIsNonRetryableError(t *testing.T) {
 		{ErrAPIKeyNotSet, false},
 		{ErrAudioTooLarge, false},
 		{ErrInvalidAudioFormat, false},
+		{ErrEmptyTranscription, false},
 		{ErrQuotaExceeded, true},
 		{ErrServiceUnavailable, true},
 		{ErrRequestTimeout, true},
@@ -126,10 +132,220 @@ func TestTranscribeAudio_Integration(t *testing.T) {
 
 	// This will fail with the OpenAI API since it's not real audio,
 	// but it will test our error handling
-	_, err = transcriber.TranscribeAudio(ctx, audioData, "wav")
+	_, err = transcriber.TranscribeAudio(ctx, audioData, "wav", ports.TranscriptionOptions{})
 	if err == nil {
 		t.Log("Transcription succeeded (unexpected with mock data)")
 	} else {
 		t.Logf("Transcription failed as expected with mock data: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestTranscribeAudio_VerboseJSON(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var responseFormat string
+	var granularities []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse multipart form: %v", err)
+		}
+		responseFormat = r.FormValue("response_format")
+		granularities = r.MultipartForm.Value["timestamp_granularities[]"]
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"text": " Hello there. General Kenobi. ",
+			"language": "english",
+			"duration": 3.5,
+			"segments": [
+				{"id": 0, "start": 0.0, "end": 1.2, "text": " Hello there.", "avg_logprob": -0.21},
+				{"id": 1, "start": 1.2, "end": 3.5, "text": " General Kenobi.", "avg_logprob": -0.35}
+			],
+			"words": [
+				{"word": "Hello", "start": 0.0, "end": 0.5},
+				{"word": "there", "start": 0.5, "end": 1.2}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewTranscriber(logger,
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithWordTimestamps(true),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+
+	result, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if responseFormat != "verbose_json" {
+		t.Errorf("Expected response_format verbose_json, got %s", responseFormat)
+	}
+
+	if strings.Join(granularities, ",") != "segment,word" {
+		t.Errorf("Expected segment and word granularities, got %v", granularities)
+	}
+
+	if result.Text != "Hello there. General Kenobi." || result.Language != "english" || result.Duration != 3.5 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if len(result.Segments) != 2 || result.Segments[1].Text != "General Kenobi." || result.Segments[1].AvgLogprob != -0.35 {
+		t.Errorf("Unexpected segments: %+v", result.Segments)
+	}
+
+	if len(result.Words) != 2 || result.Words[1].Word != "there" || result.Words[1].End != 1.2 {
+		t.Errorf("Unexpected words: %+v", result.Words)
+	}
+}
+
+func TestTranscribeAudio_PlainJSONModel(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var responseFormat string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		responseFormat = r.FormValue("response_format")
+		w.Write([]byte(`{"text": "plain text"}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewTranscriber(logger,
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithModel("gpt-4o-transcribe"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+
+	result, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if responseFormat != "json" {
+		t.Errorf("Expected response_format json, got %s", responseFormat)
+	}
+
+	if result.Text != "plain text" || len(result.Segments) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestTranscribeAudio_LanguageAndPrompt(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var path, language, prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		path = r.URL.Path
+		language = r.FormValue("language")
+		prompt = r.FormValue("prompt")
+		w.Write([]byte(`{"text": "Dzień dobry", "language": "polish"}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewTranscriber(logger, WithAPIKey("test-key"), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+
+	opts := ports.TranscriptionOptions{Language: "pl", Prompt: "Standup: Speakr, NATS, MinIO"}
+	result, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/audio/transcriptions" || language != "pl" || prompt != opts.Prompt {
+		t.Errorf("Unexpected request: path=%s language=%q prompt=%q", path, language, prompt)
+	}
+
+	if result.Language != "polish" {
+		t.Errorf("Expected detected language polish, got %q", result.Language)
+	}
+}
+
+func TestTranscribeAudio_Translate(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var path string
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		path = r.URL.Path
+		form = r.MultipartForm.Value
+		w.Write([]byte(`{"text": "Good morning", "language": "english"}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewTranscriber(logger, WithAPIKey("test-key"), WithBaseURL(server.URL), WithWordTimestamps(true))
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+
+	opts := ports.TranscriptionOptions{Language: "pl", Task: ports.TaskTranslate}
+	result, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if path != "/audio/translations" {
+		t.Errorf("Expected the translations endpoint, got %s", path)
+	}
+
+	if _, ok := form["language"]; ok {
+		t.Error("Expected no language field for a translation")
+	}
+
+	if _, ok := form["timestamp_granularities[]"]; ok {
+		t.Error("Expected no timestamp granularities for a translation")
+	}
+
+	if result.Text != "Good morning" {
+		t.Errorf("Expected translated text, got %q", result.Text)
+	}
+}
+
+func TestTranscribeAudio_ModelAndTemperatureOverride(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	var model, temperature, responseFormat string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		model = r.FormValue("model")
+		temperature = r.FormValue("temperature")
+		responseFormat = r.FormValue("response_format")
+		w.Write([]byte(`{"text": "kubectl apply"}`))
+	}))
+	defer server.Close()
+
+	transcriber, err := NewTranscriber(logger, WithAPIKey("test-key"), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+
+	opts := ports.TranscriptionOptions{Model: "gpt-4o-transcribe", Temperature: 0.2}
+	if _, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if model != "gpt-4o-transcribe" || temperature != "0.2" {
+		t.Errorf("Expected the overridden model and temperature, got model=%q temperature=%q", model, temperature)
+	}
+
+	if responseFormat != "json" {
+		t.Errorf("Expected the response format of the overridden model, got %s", responseFormat)
+	}
+}
+
+func TestErrEmptyTranscription_IsNoSpeech(t *testing.T) {
+	if !errors.Is(ErrEmptyTranscription, ports.ErrNoSpeech) {
+		t.Error("Expected ErrEmptyTranscription to match ports.ErrNoSpeech")
+	}
+}
@@ -0,0 +1,110 @@
+package otel_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Trace exporters a service can send its spans to
+const (
+	// ExporterNone drops spans; trace context is still passed on to other services
+	ExporterNone = "none"
+	// ExporterOTLP sends spans to an OpenTelemetry collector over OTLP/HTTP, at
+	// OTEL_EXPORTER_OTLP_ENDPOINT (default: http://localhost:4318)
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans as JSON, for tests and local debugging
+	ExporterStdout = "stdout"
+)
+
+// ErrUnknownExporter is returned for an exporter other than the ones above
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// TracingConfig holds configuration for tracing
+type TracingConfig struct {
+	Exporter string
+	Output   io.Writer
+}
+
+// TracingOption is a functional option for configuring tracing
+type TracingOption func(*TracingConfig)
+
+// WithExporter sets where spans are sent: none, otlp or stdout
+func WithExporter(exporter string) TracingOption {
+	return func(c *TracingConfig) {
+		c.Exporter = exporter
+	}
+}
+
+// WithOutput sets where the stdout exporter writes, os.Stdout by default
+func WithOutput(output io.Writer) TracingOption {
+	return func(c *TracingConfig) {
+		c.Output = output
+	}
+}
+
+// SetupTracing installs the global tracer provider and the W3C trace context
+// propagator for a service. The returned function flushes the remaining spans and
+// stops the provider; call it on shutdown.
+func SetupTracing(ctx context.Context, serviceName string, logger *slog.Logger, opts ...TracingOption) (func(context.Context) error, error) {
+	config := TracingConfig{
+		Exporter: ExporterNone,
+		Output:   os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone:
+		logger.Info("Tracing disabled")
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(config.Output))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", config.Exporter, err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the service name
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe service for tracing: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info("Tracing enabled", "exporter", config.Exporter)
+	return provider.Shutdown, nil
+}
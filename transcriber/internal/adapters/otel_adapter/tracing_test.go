@@ -0,0 +1,61 @@
+package otel_adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetupTracing_Stdout(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	var out bytes.Buffer
+
+	shutdown, err := SetupTracing(context.Background(), "speakr-transcriber", logger,
+		WithExporter(ExporterStdout),
+		WithOutput(&out),
+	)
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	_, span := otel.Tracer("test").Start(context.Background(), "Service.StopRecording")
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down tracing: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Service.StopRecording") {
+		t.Errorf("Expected the span to be written, got %s", out.String())
+	}
+	if !strings.Contains(out.String(), "speakr-transcriber") {
+		t.Errorf("Expected the service name in the span resource, got %s", out.String())
+	}
+}
+
+func TestSetupTracing_None(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	shutdown, err := SetupTracing(context.Background(), "speakr-transcriber", logger)
+	if err != nil {
+		t.Fatalf("Failed to set up tracing: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a no-op shutdown, got %v", err)
+	}
+}
+
+func TestSetupTracing_UnknownExporter(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	_, err := SetupTracing(context.Background(), "speakr-transcriber", logger, WithExporter("zipkin"))
+	if !errors.Is(err, ErrUnknownExporter) {
+		t.Errorf("Expected ErrUnknownExporter, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+package postgres_adapter
+
+import "errors"
+
+// Custom error types for database-specific failures
+var (
+	ErrConnectionFailed    = errors.New("failed to connect to database")
+	ErrTableCreationFailed = errors.New("failed to create required tables")
+)
@@ -0,0 +1,209 @@
+package postgres_adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	_ "github.com/lib/pq"
+)
+
+// TranscriptionCacheConfig holds configuration for the PostgreSQL transcription cache
+type TranscriptionCacheConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+	TTL      time.Duration
+	Timeout  time.Duration
+}
+
+// TranscriptionCacheOption is a functional option for configuring the transcription cache
+type TranscriptionCacheOption func(*TranscriptionCacheConfig)
+
+// WithHost sets the database host
+func WithHost(host string) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.Host = host
+	}
+}
+
+// WithPort sets the database port
+func WithPort(port int) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.Port = port
+	}
+}
+
+// WithCredentials sets the database credentials
+func WithCredentials(user, password string) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.User = user
+		c.Password = password
+	}
+}
+
+// WithDatabase sets the database name
+func WithDatabase(dbName string) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.DBName = dbName
+	}
+}
+
+// WithSSLMode sets the SSL mode
+func WithSSLMode(sslMode string) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.SSLMode = sslMode
+	}
+}
+
+// WithCacheTTL sets how long a transcript is reused
+func WithCacheTTL(ttl time.Duration) TranscriptionCacheOption {
+	return func(c *TranscriptionCacheConfig) {
+		c.TTL = ttl
+	}
+}
+
+// TranscriptionCache implements the TranscriptionCache port in a PostgreSQL table,
+// next to the embedder's transcriptions
+type TranscriptionCache struct {
+	db     *sql.DB
+	config TranscriptionCacheConfig
+	logger *slog.Logger
+}
+
+// NewTranscriptionCache connects to the database, creating the cache table if necessary
+// and dropping expired transcripts
+func NewTranscriptionCache(logger *slog.Logger, opts ...TranscriptionCacheOption) (*TranscriptionCache, error) {
+	config := TranscriptionCacheConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "postgres",
+		DBName:   "speakr",
+		SSLMode:  "disable",
+		TTL:      30 * 24 * time.Hour,
+		Timeout:  30 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	cache := &TranscriptionCache{
+		db:     db,
+		config: config,
+		logger: logger,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
+	if err := cache.ensureTableExists(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// ensureTableExists creates the cache table and removes expired rows
+func (c *TranscriptionCache) ensureTableExists(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS transcription_cache (
+			cache_key TEXT PRIMARY KEY,
+			result JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`
+	if _, err := c.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("%w: %v", ErrTableCreationFailed, err)
+	}
+
+	if _, err := c.db.ExecContext(ctx, `DELETE FROM transcription_cache WHERE created_at < $1`, c.expiry()); err != nil {
+		c.logger.Warn("Failed to remove expired cached transcriptions", "error", err)
+	}
+
+	return nil
+}
+
+// expiry is the creation time before which transcripts are no longer reused
+func (c *TranscriptionCache) expiry() time.Time {
+	return time.Now().Add(-c.config.TTL)
+}
+
+// GetTranscription returns the cached transcript, or nil if there is none or it has expired
+func (c *TranscriptionCache) GetTranscription(ctx context.Context, key string) (*ports.TranscriptionResult, error) {
+	ctx, span := startQuerySpan(ctx, "SELECT", "transcription_cache")
+	var data []byte
+	err := c.db.QueryRowContext(ctx,
+		`SELECT result FROM transcription_cache WHERE cache_key = $1 AND created_at >= $2`,
+		key, c.expiry(),
+	).Scan(&data)
+	endQuerySpan(span, err)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		c.logger.Error("Failed to get cached transcription", "cache_key", key, "error", err)
+		return nil, fmt.Errorf("failed to get cached transcription: %w", err)
+	}
+
+	var result ports.TranscriptionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached transcription: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SaveTranscription caches a transcript, replacing any earlier one for the key
+func (c *TranscriptionCache) SaveTranscription(ctx context.Context, key string, result *ports.TranscriptionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcription: %w", err)
+	}
+
+	query := `
+		INSERT INTO transcription_cache (cache_key, result, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (cache_key) DO UPDATE SET
+			result = EXCLUDED.result,
+			created_at = NOW()
+	`
+	ctx, span := startQuerySpan(ctx, "INSERT", "transcription_cache")
+	_, err = c.db.ExecContext(ctx, query, key, data)
+	endQuerySpan(span, err)
+	if err != nil {
+		c.logger.Error("Failed to cache transcription", "cache_key", key, "error", err)
+		return fmt.Errorf("failed to cache transcription: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the database connection
+func (c *TranscriptionCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,73 @@
+package postgres_adapter
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestTranscriptionCacheWithOptions(t *testing.T) {
+	config := TranscriptionCacheConfig{}
+
+	opts := []TranscriptionCacheOption{
+		WithHost("db.internal"),
+		WithPort(5433),
+		WithCredentials("speakr", "secret"),
+		WithDatabase("speakr_test"),
+		WithSSLMode("require"),
+		WithCacheTTL(time.Hour),
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if config.Host != "db.internal" || config.Port != 5433 {
+		t.Errorf("Unexpected address %s:%d", config.Host, config.Port)
+	}
+
+	if config.User != "speakr" || config.Password != "secret" || config.DBName != "speakr_test" {
+		t.Errorf("Unexpected credentials or database: %+v", config)
+	}
+
+	if config.SSLMode != "require" || config.TTL != time.Hour {
+		t.Errorf("Unexpected SSL mode or TTL: %s, %v", config.SSLMode, config.TTL)
+	}
+}
+
+func TestTranscriptionCacheOperations(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	cache, err := NewTranscriptionCache(logger, WithCacheTTL(time.Hour))
+	if err != nil {
+		t.Skipf("PostgreSQL not available, skipping integration test: %v", err)
+	}
+	defer cache.Close()
+
+	ctx := context.Background()
+	result := &ports.TranscriptionResult{
+		Text:     "hello",
+		Segments: []ports.TranscriptSegment{{Start: 0, End: 1, Text: "hello"}},
+	}
+
+	if err := cache.SaveTranscription(ctx, "test-key.0123", result); err != nil {
+		t.Fatalf("Failed to save transcription: %v", err)
+	}
+
+	got, err := cache.GetTranscription(ctx, "test-key.0123")
+	if err != nil {
+		t.Fatalf("Failed to get transcription: %v", err)
+	}
+
+	if got == nil || got.Text != "hello" || len(got.Segments) != 1 {
+		t.Errorf("Unexpected transcription: %+v", got)
+	}
+
+	if got, err := cache.GetTranscription(ctx, "unknown-key"); err != nil || got != nil {
+		t.Errorf("Expected a miss for an unknown key, got %+v, %v", got, err)
+	}
+}
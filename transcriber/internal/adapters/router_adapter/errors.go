@@ -0,0 +1,9 @@
+package router_adapter
+
+import "errors"
+
+// Custom error types for router configuration failures
+var (
+	ErrNoProviders       = errors.New("no transcription providers configured")
+	ErrDuplicateProvider = errors.New("transcription provider configured twice")
+)
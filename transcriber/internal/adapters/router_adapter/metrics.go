@@ -0,0 +1,75 @@
+package router_adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Provider metrics, labelled by the provider's configured name
+var (
+	providerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "provider_request_duration_seconds",
+		Help:      "Time a transcription provider took to answer, by provider and outcome.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"provider", "outcome"})
+
+	providerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "provider_errors_total",
+		Help:      "Failed transcription provider requests, by provider and error.",
+	}, []string{"provider", "error"})
+
+	providerRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "provider_retries_total",
+		Help:      "Transcriptions retried on the next provider after this one failed.",
+	}, []string{"provider"})
+)
+
+// errorTypes names the port-level errors providers wrap, most specific first, so a
+// provider error is counted by what went wrong rather than by its message. Quota and
+// timeout failures are also ErrProviderUnavailable, so they come before it.
+var errorTypes = []struct {
+	err  error
+	name string
+}{
+	{ports.ErrProviderAuthFailed, "auth_failed"},
+	{ports.ErrProviderQuotaExceeded, "quota_exceeded"},
+	{ports.ErrProviderTimeout, "timeout"},
+	{ports.ErrProviderUnavailable, "provider_unavailable"},
+	{ports.ErrAudioTooLarge, "audio_too_large"},
+	{ports.ErrUnsupportedAudio, "invalid_audio_format"},
+	{ports.ErrNoSpeech, "no_speech"},
+	{context.Canceled, "canceled"},
+	{context.DeadlineExceeded, "deadline_exceeded"},
+}
+
+// observeRequest records the duration and outcome of a request to a provider
+func observeRequest(provider string, started time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		providerErrors.WithLabelValues(provider, errorType(err)).Inc()
+	}
+	providerRequestDuration.WithLabelValues(provider, outcome).Observe(time.Since(started).Seconds())
+}
+
+// errorType returns the metric label of a provider error
+func errorType(err error) string {
+	for _, errorType := range errorTypes {
+		if errors.Is(err, errorType.err) {
+			return errorType.name
+		}
+	}
+	return "other"
+}
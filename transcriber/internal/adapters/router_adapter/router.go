@@ -0,0 +1,187 @@
+package router_adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// Provider is a named transcription service the router can send requests to
+type Provider struct {
+	Name    string
+	Service ports.TranscriptionService
+}
+
+// RouterConfig holds configuration for the transcription router
+type RouterConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// RouterOption is a functional option for configuring the router
+type RouterOption func(*RouterConfig)
+
+// WithFailureThreshold sets how many retryable failures in a row take a provider
+// out of rotation
+func WithFailureThreshold(failures int) RouterOption {
+	return func(c *RouterConfig) {
+		c.FailureThreshold = failures
+	}
+}
+
+// WithCooldown sets how long a failing provider is out of rotation
+func WithCooldown(cooldown time.Duration) RouterOption {
+	return func(c *RouterConfig) {
+		c.Cooldown = cooldown
+	}
+}
+
+// providerHealth tracks recent failures of one provider
+type providerHealth struct {
+	failures       int // retryable failures in a row
+	unhealthyUntil time.Time
+}
+
+// Router implements the TranscriptionService port over several providers. Requests go
+// to the first healthy provider in priority order and fail over to the next when a
+// provider is rate limited or unavailable.
+type Router struct {
+	providers []Provider
+	config    RouterConfig
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu     sync.Mutex
+	health map[string]*providerHealth
+}
+
+// NewRouter creates a router over the providers, highest priority first
+func NewRouter(providers []Provider, logger *slog.Logger, opts ...RouterOption) (*Router, error) {
+	config := RouterConfig{
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	if config.FailureThreshold < 1 {
+		return nil, fmt.Errorf("failure threshold must be at least 1, got %d", config.FailureThreshold)
+	}
+
+	health := make(map[string]*providerHealth, len(providers))
+	for _, provider := range providers {
+		if _, exists := health[provider.Name]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateProvider, provider.Name)
+		}
+		health[provider.Name] = &providerHealth{}
+	}
+
+	return &Router{
+		providers: providers,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+		health:    health,
+	}, nil
+}
+
+// TranscribeAudio transcribes audio with the first provider that succeeds. Errors that
+// another provider would also return, such as unsupported audio, are returned at once.
+// A model in opts names a model of the primary provider, so fallbacks use their own.
+func (r *Router) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	// Kept in memory so each provider reads the audio from the start
+	audioBytes, err := io.ReadAll(audioData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio data: %w", err)
+	}
+
+	var lastErr error
+	for _, provider := range r.candidates() {
+		logger := r.logger.With("provider", provider.Name, "format", format)
+
+		providerOpts := opts
+		if provider.Name != r.providers[0].Name {
+			providerOpts.Model = ""
+		}
+
+		started := time.Now()
+		result, err := provider.Service.TranscribeAudio(ctx, bytes.NewReader(audioBytes), format, providerOpts)
+		observeRequest(provider.Name, started, err)
+		if err == nil {
+			r.markHealthy(logger, provider.Name)
+			result.Provider = provider.Name
+			return result, nil
+		}
+
+		if !errors.Is(err, ports.ErrProviderUnavailable) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		lastErr = err
+		r.markFailed(logger, provider.Name)
+		providerRetries.WithLabelValues(provider.Name).Inc()
+		logger.Warn("Transcription provider failed, trying the next one", "error", err)
+	}
+
+	r.logger.Error("All transcription providers failed", "providers", len(r.providers), "error", lastErr)
+	return nil, fmt.Errorf("all %d transcription providers failed: %w", len(r.providers), lastErr)
+}
+
+// candidates returns the providers in the order to try them: healthy ones by priority,
+// then those cooling down, which may have recovered and are better than failing outright
+func (r *Router) candidates() []Provider {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	healthy := make([]Provider, 0, len(r.providers))
+	var coolingDown []Provider
+
+	for _, provider := range r.providers {
+		if now.Before(r.health[provider.Name].unhealthyUntil) {
+			coolingDown = append(coolingDown, provider)
+			continue
+		}
+		healthy = append(healthy, provider)
+	}
+
+	return append(healthy, coolingDown...)
+}
+
+// markFailed counts a retryable failure, taking the provider out of rotation for the
+// cooldown once it reaches the threshold
+func (r *Router) markFailed(logger *slog.Logger, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := r.health[name]
+	health.failures++
+	if health.failures >= r.config.FailureThreshold {
+		health.unhealthyUntil = r.now().Add(r.config.Cooldown)
+		logger.Warn("Transcription provider marked unhealthy", "failures", health.failures, "cooldown", r.config.Cooldown)
+	}
+}
+
+// markHealthy clears the failures of a provider that served a request
+func (r *Router) markHealthy(logger *slog.Logger, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := r.health[name]
+	if !health.unhealthyUntil.IsZero() {
+		logger.Info("Transcription provider recovered")
+	}
+	*health = providerHealth{}
+}
@@ -0,0 +1,215 @@
+package router_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// mockProvider records the audio it is given and fails with err, if set
+type mockProvider struct {
+	err   error
+	calls int
+	audio string
+	model string
+}
+
+func (m *mockProvider) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	m.calls++
+	data, _ := io.ReadAll(audioData)
+	m.audio = string(data)
+	m.model = opts.Model
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &ports.TranscriptionResult{Text: "hello"}, nil
+}
+
+var errUnavailable = fmt.Errorf("service unavailable: %w", ports.ErrProviderUnavailable)
+
+func newTestRouter(t *testing.T, providers []Provider, opts ...RouterOption) *Router {
+	t.Helper()
+	router, err := NewRouter(providers, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...)
+	if err != nil {
+		t.Fatalf("Failed to create router: %v", err)
+	}
+	return router
+}
+
+func TestNewRouter_Validation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if _, err := NewRouter(nil, logger); !errors.Is(err, ErrNoProviders) {
+		t.Errorf("Expected ErrNoProviders, got %v", err)
+	}
+
+	providers := []Provider{{Name: "openai", Service: &mockProvider{}}, {Name: "openai", Service: &mockProvider{}}}
+	if _, err := NewRouter(providers, logger); !errors.Is(err, ErrDuplicateProvider) {
+		t.Errorf("Expected ErrDuplicateProvider, got %v", err)
+	}
+}
+
+func TestRouter_FailsOverOnRetryableErrors(t *testing.T) {
+	primary := &mockProvider{err: errUnavailable}
+	fallback := &mockProvider{}
+	router := newTestRouter(t, []Provider{{Name: "openai", Service: primary}, {Name: "groq", Service: fallback}})
+
+	result, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Provider != "groq" {
+		t.Errorf("Expected the fallback to serve the request, got %q", result.Provider)
+	}
+	if fallback.audio != "audio" {
+		t.Errorf("Expected the fallback to get the full audio, got %q", fallback.audio)
+	}
+}
+
+func TestRouter_ModelOnlyForPrimary(t *testing.T) {
+	primary := &mockProvider{err: errUnavailable}
+	fallback := &mockProvider{}
+	router := newTestRouter(t, []Provider{{Name: "openai", Service: primary}, {Name: "groq", Service: fallback}})
+
+	opts := ports.TranscriptionOptions{Model: "whisper-1", Language: "pl"}
+	if _, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if primary.model != "whisper-1" {
+		t.Errorf("Expected the primary to get the requested model, got %q", primary.model)
+	}
+	if fallback.model != "" {
+		t.Errorf("Expected the fallback to use its configured model, got %q", fallback.model)
+	}
+
+	// The primary's model stays its own while it cools down behind the fallback
+	if _, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fallback.model != "" {
+		t.Errorf("Expected the fallback to use its configured model, got %q", fallback.model)
+	}
+}
+
+func TestRouter_ReturnsNonRetryableErrors(t *testing.T) {
+	errBadAudio := errors.New("invalid audio format")
+	fallback := &mockProvider{}
+	router := newTestRouter(t, []Provider{{Name: "openai", Service: &mockProvider{err: errBadAudio}}, {Name: "groq", Service: fallback}})
+
+	_, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+	if !errors.Is(err, errBadAudio) {
+		t.Errorf("Expected the provider's error, got %v", err)
+	}
+	if fallback.calls != 0 {
+		t.Error("Expected no failover for an error every provider would return")
+	}
+}
+
+func TestRouter_AllProvidersFail(t *testing.T) {
+	router := newTestRouter(t, []Provider{
+		{Name: "openai", Service: &mockProvider{err: errUnavailable}},
+		{Name: "groq", Service: &mockProvider{err: errUnavailable}},
+	})
+
+	_, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+	if !errors.Is(err, ports.ErrProviderUnavailable) {
+		t.Errorf("Expected the last provider error, got %v", err)
+	}
+}
+
+func TestRouter_SkipsProvidersCoolingDown(t *testing.T) {
+	primary := &mockProvider{err: errUnavailable}
+	fallback := &mockProvider{}
+	router := newTestRouter(t, []Provider{{Name: "openai", Service: primary}, {Name: "groq", Service: fallback}},
+		WithFailureThreshold(2),
+		WithCooldown(time.Minute),
+	)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+
+	transcribe := func() string {
+		t.Helper()
+		result, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return result.Provider
+	}
+
+	// The primary stays in rotation until it reaches the threshold
+	transcribe()
+	transcribe()
+	if primary.calls != 2 {
+		t.Fatalf("Expected the primary tried twice, got %d", primary.calls)
+	}
+
+	transcribe()
+	if primary.calls != 2 {
+		t.Errorf("Expected the unhealthy primary to be skipped, got %d calls", primary.calls)
+	}
+
+	// Once the cooldown runs out it is tried first again and recovers
+	now = now.Add(time.Minute)
+	primary.err = nil
+	if provider := transcribe(); provider != "openai" {
+		t.Errorf("Expected the recovered primary to serve the request, got %q", provider)
+	}
+	if health := router.health["openai"]; health.failures != 0 || !health.unhealthyUntil.IsZero() {
+		t.Errorf("Expected the primary's health reset, got %+v", health)
+	}
+}
+
+func TestRouter_RecordsProviderMetrics(t *testing.T) {
+	router := newTestRouter(t, []Provider{
+		{Name: "metrics-primary", Service: &mockProvider{err: errUnavailable}},
+		{Name: "metrics-fallback", Service: &mockProvider{}},
+	})
+
+	if _, err := router.TranscribeAudio(context.Background(), strings.NewReader("audio"), "wav", ports.TranscriptionOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := testutil.ToFloat64(providerErrors.WithLabelValues("metrics-primary", "provider_unavailable")); got != 1 {
+		t.Errorf("Expected 1 provider_unavailable error, got %v", got)
+	}
+	if got := testutil.ToFloat64(providerRetries.WithLabelValues("metrics-primary")); got != 1 {
+		t.Errorf("Expected 1 retry after the primary, got %v", got)
+	}
+	if got := testutil.CollectAndCount(providerRequestDuration); got < 2 {
+		t.Errorf("Expected request durations for both providers, got %d series", got)
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ports.ErrProviderQuotaExceeded, "quota_exceeded"},
+		{fmt.Errorf("request failed: %w", ports.ErrProviderTimeout), "timeout"},
+		{fmt.Errorf("upload: %w", ports.ErrAudioTooLarge), "audio_too_large"},
+		{fmt.Errorf("convert: %w", ports.ErrUnsupportedAudio), "invalid_audio_format"},
+		{fmt.Errorf("empty transcription: %w", ports.ErrNoSpeech), "no_speech"},
+		{errUnavailable, "provider_unavailable"},
+		{context.DeadlineExceeded, "deadline_exceeded"},
+		{errors.New("boom"), "other"},
+	}
+
+	for _, tt := range tests {
+		if got := errorType(tt.err); got != tt.want {
+			t.Errorf("errorType(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package whisper_adapter
+
+import (
+	"fmt"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// Custom error types for local whisper.cpp failures
+var (
+	ErrBinaryNotFound      = fmt.Errorf("whisper.cpp binary not found in PATH: %w", ports.ErrProviderUnavailable)
+	ErrModelNotFound       = fmt.Errorf("whisper.cpp model file not found: %w", ports.ErrProviderUnavailable)
+	ErrInvalidAudioFormat  = fmt.Errorf("audio format needs ffmpeg to convert it for whisper.cpp: %w", ports.ErrUnsupportedAudio)
+	ErrTranscriptionFailed = fmt.Errorf("whisper.cpp transcription failed: %w", ports.ErrProviderUnavailable)
+	ErrEmptyTranscription  = fmt.Errorf("whisper.cpp returned empty transcription: %w", ports.ErrNoSpeech)
+)
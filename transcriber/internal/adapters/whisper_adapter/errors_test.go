@@ -0,0 +1,27 @@
+package whisper_adapter
+
+import (
+	"errors"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+func TestErrors_WrapPortSentinels(t *testing.T) {
+	tests := []struct {
+		err  error
+		port error
+	}{
+		{ErrBinaryNotFound, ports.ErrProviderUnavailable},
+		{ErrModelNotFound, ports.ErrProviderUnavailable},
+		{ErrInvalidAudioFormat, ports.ErrUnsupportedAudio},
+		{ErrTranscriptionFailed, ports.ErrProviderUnavailable},
+		{ErrEmptyTranscription, ports.ErrNoSpeech},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.port) {
+			t.Errorf("Expected %q to wrap %q", tt.err, tt.port)
+		}
+	}
+}
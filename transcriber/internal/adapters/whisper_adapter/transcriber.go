@@ -0,0 +1,269 @@
+package whisper_adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// blankAudio is the text whisper.cpp gives a segment without speech
+const blankAudio = "[BLANK_AUDIO]"
+
+// TranscriberConfig holds configuration for the whisper.cpp transcriber
+type TranscriberConfig struct {
+	Binary    string
+	ModelPath string
+	Threads   int
+	TempDir   string
+}
+
+// TranscriberOption is a functional option for configuring the transcriber
+type TranscriberOption func(*TranscriberConfig)
+
+// WithBinary sets the whisper.cpp executable, a name looked up in PATH or a path
+func WithBinary(binary string) TranscriberOption {
+	return func(c *TranscriberConfig) {
+		c.Binary = binary
+	}
+}
+
+// WithModelPath sets the ggml model file
+func WithModelPath(path string) TranscriberOption {
+	return func(c *TranscriberConfig) {
+		c.ModelPath = path
+	}
+}
+
+// WithThreads sets how many threads whisper.cpp uses; 0 keeps its default
+func WithThreads(threads int) TranscriberOption {
+	return func(c *TranscriberConfig) {
+		c.Threads = threads
+	}
+}
+
+// WithTempDir sets the directory used for the audio and output files of each run
+func WithTempDir(dir string) TranscriberOption {
+	return func(c *TranscriberConfig) {
+		c.TempDir = dir
+	}
+}
+
+// Transcriber implements the TranscriptionService port by running whisper.cpp locally
+type Transcriber struct {
+	config TranscriberConfig
+	binary string // resolved path of the executable
+	ffmpeg bool   // whether audio can be converted to the 16 kHz WAV whisper.cpp reads
+	logger *slog.Logger
+}
+
+// NewTranscriber creates a new whisper.cpp transcriber
+func NewTranscriber(logger *slog.Logger, opts ...TranscriberOption) (*Transcriber, error) {
+	config := TranscriberConfig{
+		Binary:  "whisper-cli",
+		TempDir: "/tmp/speakr",
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	binary, err := exec.LookPath(config.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBinaryNotFound, config.Binary)
+	}
+
+	if _, err := os.Stat(config.ModelPath); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, config.ModelPath)
+	}
+
+	if err := os.MkdirAll(config.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	_, err = exec.LookPath("ffmpeg")
+	if err != nil {
+		logger.Warn("FFmpeg not found, whisper.cpp will only transcribe WAV audio")
+	}
+
+	return &Transcriber{
+		config: config,
+		binary: binary,
+		ffmpeg: err == nil,
+		logger: logger,
+	}, nil
+}
+
+// TranscribeAudio transcribes audio with whisper.cpp, or translates it into English
+// when the translate task is requested
+func (t *Transcriber) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	logger := t.logger.With("model_path", t.config.ModelPath, "format", format, "task", opts.Task, "language", opts.Language)
+
+	logger.Info("Starting local audio transcription")
+
+	workDir, err := os.MkdirTemp(t.config.TempDir, "whisper-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcription directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	audioPath, err := t.prepareAudio(ctx, workDir, audioData, format)
+	if err != nil {
+		logger.Error("Failed to prepare audio", "error", err)
+		return nil, err
+	}
+
+	outputPrefix := filepath.Join(workDir, "transcript")
+	args := buildWhisperArgs(t.config, audioPath, outputPrefix, opts)
+
+	cmd := exec.CommandContext(ctx, t.binary, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("whisper.cpp failed", "error", err, "args", args)
+		return nil, fmt.Errorf("%w: %v: %s", ErrTranscriptionFailed, err, output)
+	}
+
+	body, err := os.ReadFile(outputPrefix + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read output: %v", ErrTranscriptionFailed, err)
+	}
+
+	result, err := parseWhisperOutput(body)
+	if err != nil {
+		logger.Error("Failed to parse whisper.cpp output", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Local transcription completed successfully",
+		"text_length", len(result.Text),
+		"segments", len(result.Segments))
+	return result, nil
+}
+
+// prepareAudio writes the audio where whisper.cpp can read it, converting it to 16 kHz
+// mono WAV when ffmpeg is available. Without ffmpeg only WAV audio is accepted.
+func (t *Transcriber) prepareAudio(ctx context.Context, workDir string, audioData io.Reader, format string) (string, error) {
+	if !t.ffmpeg && format != "wav" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidAudioFormat, format)
+	}
+
+	sourcePath := filepath.Join(workDir, "source."+format)
+	file, err := os.Create(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	_, err = io.Copy(file, audioData)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	if !t.ffmpeg {
+		return sourcePath, nil
+	}
+
+	audioPath := filepath.Join(workDir, "audio.wav")
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-i", sourcePath, "-ar", "16000", "-ac", "1", "-acodec", "pcm_s16le", "-y", audioPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to convert audio for whisper.cpp: %w: %s", err, output)
+	}
+
+	return audioPath, nil
+}
+
+// buildWhisperArgs builds the whisper.cpp arguments that transcribe one file and
+// write the result as JSON to the output prefix plus ".json"
+func buildWhisperArgs(config TranscriberConfig, audioPath, outputPrefix string, opts ports.TranscriptionOptions) []string {
+	modelPath := config.ModelPath
+	// Only model files override the configured one; OpenAI model names in profiles do not apply
+	if opts.Model != "" {
+		if _, err := os.Stat(opts.Model); err == nil {
+			modelPath = opts.Model
+		}
+	}
+
+	language := opts.Language
+	if language == "" {
+		language = "auto"
+	}
+
+	args := []string{
+		"-m", modelPath,
+		"-f", audioPath,
+		"-l", language,
+		"-oj",
+		"-of", outputPrefix,
+		"-np",
+	}
+	if config.Threads > 0 {
+		args = append(args, "-t", strconv.Itoa(config.Threads))
+	}
+	if opts.Task == ports.TaskTranslate {
+		args = append(args, "-tr")
+	}
+	if opts.Prompt != "" {
+		args = append(args, "--prompt", opts.Prompt)
+	}
+	if opts.Temperature > 0 {
+		args = append(args, "-tp", strconv.FormatFloat(opts.Temperature, 'f', -1, 64))
+	}
+
+	return args
+}
+
+// whisperOutput is the JSON file whisper.cpp writes with -oj
+type whisperOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// parseWhisperOutput converts whisper.cpp JSON output into a result. Offsets are in
+// milliseconds; segments without speech are dropped.
+func parseWhisperOutput(body []byte) (*ports.TranscriptionResult, error) {
+	var output whisperOutput
+	if err := json.Unmarshal(body, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse whisper.cpp output: %w", err)
+	}
+
+	result := &ports.TranscriptionResult{Language: output.Result.Language}
+	var texts []string
+
+	for _, entry := range output.Transcription {
+		text := strings.TrimSpace(entry.Text)
+		if text == "" || text == blankAudio {
+			continue
+		}
+
+		segment := ports.TranscriptSegment{
+			ID:    len(result.Segments),
+			Start: float64(entry.Offsets.From) / 1000,
+			End:   float64(entry.Offsets.To) / 1000,
+			Text:  text,
+		}
+		result.Segments = append(result.Segments, segment)
+		result.Duration = segment.End
+		texts = append(texts, text)
+	}
+
+	if len(texts) == 0 {
+		return nil, ErrEmptyTranscription
+	}
+
+	result.Text = strings.Join(texts, " ")
+	return result, nil
+}
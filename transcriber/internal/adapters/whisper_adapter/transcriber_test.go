@@ -0,0 +1,141 @@
+package whisper_adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+const whisperJSON = `{
+  "systeminfo": "AVX = 1 | NEON = 0",
+  "params": {"model": "models/ggml-base.bin", "language": "auto", "translate": false},
+  "result": {"language": "en"},
+  "transcription": [
+    {"timestamps": {"from": "00:00:00,000", "to": "00:00:02,500"}, "offsets": {"from": 0, "to": 2500}, "text": " Hello there."},
+    {"timestamps": {"from": "00:00:02,500", "to": "00:00:04,000"}, "offsets": {"from": 2500, "to": 4000}, "text": " [BLANK_AUDIO]"},
+    {"timestamps": {"from": "00:00:04,000", "to": "00:00:06,120"}, "offsets": {"from": 4000, "to": 6120}, "text": " General Kenobi."}
+  ]
+}`
+
+// installFakeWhisper puts a whisper-cli on PATH that records its arguments and writes
+// the given JSON where -of asks, and returns the file the arguments are recorded in
+func installFakeWhisper(t *testing.T, output string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsPath := filepath.Join(dir, "args")
+
+	// Only shell builtins, since PATH holds nothing else
+	script := `#!/bin/sh
+echo "$@" > ` + argsPath + `
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-of" ]; then printf '%s' '` + output + `' > "$2.json"; fi
+  shift
+done
+`
+	if err := os.WriteFile(filepath.Join(dir, "whisper-cli"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake whisper-cli: %v", err)
+	}
+
+	t.Setenv("PATH", dir)
+	return argsPath
+}
+
+func newTestTranscriber(t *testing.T) *Transcriber {
+	t.Helper()
+
+	modelPath := filepath.Join(t.TempDir(), "ggml-base.bin")
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatalf("Failed to write model file: %v", err)
+	}
+
+	transcriber, err := NewTranscriber(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithModelPath(modelPath),
+		WithTempDir(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create transcriber: %v", err)
+	}
+	return transcriber
+}
+
+func TestNewTranscriber_Validation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewTranscriber(logger, WithModelPath("model.bin")); !errors.Is(err, ErrBinaryNotFound) {
+		t.Errorf("Expected ErrBinaryNotFound, got %v", err)
+	}
+
+	installFakeWhisper(t, whisperJSON)
+	if _, err := NewTranscriber(logger, WithModelPath(filepath.Join(t.TempDir(), "missing.bin"))); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+}
+
+func TestTranscriber_TranscribeAudio(t *testing.T) {
+	argsPath := installFakeWhisper(t, whisperJSON)
+	transcriber := newTestTranscriber(t)
+
+	opts := ports.TranscriptionOptions{Language: "pl", Prompt: "Speakr", Task: ports.TaskTranslate}
+	result, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("RIFF"), "wav", opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Text != "Hello there. General Kenobi." || result.Language != "en" {
+		t.Errorf("Unexpected text or language: %q, %q", result.Text, result.Language)
+	}
+	if len(result.Segments) != 2 || result.Segments[1].ID != 1 || result.Segments[1].Start != 4 || result.Segments[1].End != 6.12 {
+		t.Errorf("Expected two timed segments without the blank one, got %+v", result.Segments)
+	}
+	if result.Duration != 6.12 {
+		t.Errorf("Expected the duration of the last segment, got %v", result.Duration)
+	}
+
+	args, err := os.ReadFile(argsPath)
+	if err != nil {
+		t.Fatalf("Failed to read recorded arguments: %v", err)
+	}
+	for _, expected := range []string{"-l pl", "-oj", "-tr", "--prompt Speakr"} {
+		if !strings.Contains(string(args), expected) {
+			t.Errorf("Expected %q in the arguments, got %q", expected, args)
+		}
+	}
+}
+
+func TestTranscriber_TranscribeAudio_NeedsFFmpegForOtherFormats(t *testing.T) {
+	installFakeWhisper(t, whisperJSON)
+	transcriber := newTestTranscriber(t)
+
+	_, err := transcriber.TranscribeAudio(context.Background(), strings.NewReader("ID3"), "mp3", ports.TranscriptionOptions{})
+	if !errors.Is(err, ErrInvalidAudioFormat) {
+		t.Errorf("Expected ErrInvalidAudioFormat, got %v", err)
+	}
+}
+
+func TestParseWhisperOutput_NoSpeech(t *testing.T) {
+	body := `{"result": {"language": "en"}, "transcription": [{"offsets": {"from": 0, "to": 3000}, "text": " [BLANK_AUDIO]"}]}`
+
+	if _, err := parseWhisperOutput([]byte(body)); !errors.Is(err, ports.ErrNoSpeech) {
+		t.Errorf("Expected ErrNoSpeech, got %v", err)
+	}
+}
+
+func TestBuildWhisperArgs_AutoDetectsLanguage(t *testing.T) {
+	config := TranscriberConfig{ModelPath: "/models/ggml-base.bin", Threads: 4}
+
+	args := buildWhisperArgs(config, "/tmp/audio.wav", "/tmp/transcript", ports.TranscriptionOptions{Model: "whisper-1"})
+
+	expected := "-m /models/ggml-base.bin -f /tmp/audio.wav -l auto -oj -of /tmp/transcript -np -t 4"
+	if got := strings.Join(args, " "); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
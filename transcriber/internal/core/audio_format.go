@@ -0,0 +1,61 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Audio formats recognised by DetectAudioFormat. Opus audio is reported as ogg, its container.
+const (
+	AudioFormatWAV  = "wav"
+	AudioFormatMP3  = "mp3"
+	AudioFormatFLAC = "flac"
+	AudioFormatOGG  = "ogg"
+	AudioFormatM4A  = "m4a"
+	AudioFormatWebM = "webm"
+)
+
+// audioHeaderSize is the number of leading bytes DetectAudioFormat inspects
+const audioHeaderSize = 12
+
+// DetectAudioFormat identifies an audio format from the magic bytes at the start of a file.
+// It returns an empty string if the format is not recognised.
+func DetectAudioFormat(header []byte) string {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return AudioFormatWAV
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return AudioFormatFLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return AudioFormatOGG
+	case len(header) >= 8 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return AudioFormatM4A
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return AudioFormatWebM
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioFormatMP3
+	case len(header) >= 2 && isMPEGAudioFrameSync(header[0], header[1]):
+		return AudioFormatMP3
+	}
+	return ""
+}
+
+// isMPEGAudioFrameSync reports whether two bytes start an MPEG audio frame. AAC ADTS
+// shares the sync word but uses layer bits 00, so it is not mistaken for MP3.
+func isMPEGAudioFrameSync(b0, b1 byte) bool {
+	return b0 == 0xFF && b1&0xE0 == 0xE0 && b1&0x06 != 0
+}
+
+// sniffAudioFormat detects the format of an audio stream and returns a reader that
+// still yields the complete stream
+func sniffAudioFormat(audioData io.Reader) (io.Reader, string, error) {
+	header := make([]byte, audioHeaderSize)
+	n, err := io.ReadFull(audioData, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, "", fmt.Errorf("failed to read audio header: %w", err)
+	}
+	header = header[:n]
+
+	return io.MultiReader(bytes.NewReader(header), audioData), DetectAudioFormat(header), nil
+}
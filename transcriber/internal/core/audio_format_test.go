@@ -0,0 +1,62 @@
+package core
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectAudioFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"wav", []byte("RIFF\x24\x08\x00\x00WAVEfmt "), AudioFormatWAV},
+		{"riff without wave", []byte("RIFF\x24\x08\x00\x00AVI LIST"), ""},
+		{"mp3 with id3 tag", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), AudioFormatMP3},
+		{"mp3 frame sync", []byte{0xFF, 0xFB, 0x90, 0x64}, AudioFormatMP3},
+		{"aac adts is not mp3", []byte{0xFF, 0xF1, 0x50, 0x80}, ""},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), AudioFormatFLAC},
+		{"ogg opus", []byte("OggS\x00\x02\x00\x00\x00\x00\x00\x00"), AudioFormatOGG},
+		{"m4a", []byte("\x00\x00\x00\x20ftypM4A "), AudioFormatM4A},
+		{"webm", []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81}, AudioFormatWebM},
+		{"text", []byte("hello world!"), ""},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectAudioFormat(tt.header); got != tt.want {
+				t.Errorf("DetectAudioFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffAudioFormat_PreservesStream(t *testing.T) {
+	reader, format, err := sniffAudioFormat(strings.NewReader("fLaC and the rest of the stream"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if format != AudioFormatFLAC {
+		t.Errorf("Expected flac, got %q", format)
+	}
+
+	data, _ := io.ReadAll(reader)
+	if string(data) != "fLaC and the rest of the stream" {
+		t.Errorf("Expected the full stream, got %q", string(data))
+	}
+
+	// Streams shorter than the header are still readable
+	reader, format, err = sniffAudioFormat(strings.NewReader("ID3"))
+	if err != nil || format != AudioFormatMP3 {
+		t.Fatalf("Expected mp3 without error, got %q, %v", format, err)
+	}
+
+	data, _ = io.ReadAll(reader)
+	if string(data) != "ID3" {
+		t.Errorf("Expected the full stream, got %q", string(data))
+	}
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// Reasons a recording stopped, reported as stop_reason in recording.finished
+const (
+	StopReasonManual  = "manual"
+	StopReasonSilence = "silence"
+	StopReasonLimit   = "limit"
+)
+
+// autoStopOptions adds to the recording options the silence timeout and limits
+// configured by the start command, with callbacks that stop the recording
+func (s *Service) autoStopOptions(ctx context.Context, recordingID string, cmd StartRecordingCommand, opts ports.RecordingOptions) ports.RecordingOptions {
+	if cmd.AutoStopSilenceSeconds > 0 {
+		opts.SilenceTimeout = time.Duration(cmd.AutoStopSilenceSeconds * float64(time.Second))
+		opts.OnSilence = func() {
+			// The recorder waits for this callback before it can stop
+			go s.autoStop(ctx, recordingID, cmd.TranscribeOnStop, StopReasonSilence)
+		}
+	}
+
+	// Always set, so a recording reaching the recorder's own maximum is stopped too
+	opts.MaxDuration = time.Duration(cmd.MaxDurationSeconds * float64(time.Second))
+	opts.MaxBytes = cmd.MaxBytes
+	opts.OnLimit = func() {
+		go s.autoStop(ctx, recordingID, cmd.TranscribeOnStop, StopReasonLimit)
+	}
+
+	return opts
+}
+
+// autoStop stops a recording on its own, as a stop command carrying the start
+// command's transcribe_on_stop would
+func (s *Service) autoStop(ctx context.Context, recordingID string, transcribeOnStop bool, reason string) {
+	// The start command has long been answered, but its correlation ID still applies
+	ctx = context.WithoutCancel(ctx)
+
+	logger := s.logger.With(
+		"correlation_id", s.getCorrelationID(ctx),
+		"recording_id", recordingID,
+		"operation", "auto_stop",
+		"stop_reason", reason,
+	)
+
+	logger.Info("Stopping recording automatically")
+
+	cmd := StopRecordingCommand{
+		RecordingID:      recordingID,
+		TranscribeOnStop: transcribeOnStop,
+	}
+	if err := s.stopRecording(ctx, cmd, reason); err != nil {
+		logger.Error("Failed to stop recording automatically", "error", err)
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// waitForEvents waits until at least n events have been published
+func waitForEvents(t *testing.T, publisher *mockEventPublisher, n int) []ports.Event {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		publisher.mu.Lock()
+		events := append([]ports.Event(nil), publisher.publishedEvents...)
+		publisher.mu.Unlock()
+
+		if len(events) >= n {
+			return events
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("Timed out waiting for %d events", n)
+	return nil
+}
+
+func TestService_StartRecording_AutoStopsOnSilence(t *testing.T) {
+	service, recorder, _, _, _, eventPublisher := createTestService()
+
+	var recordingOpts ports.RecordingOptions
+	recorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		recordingOpts = opts
+		return nil
+	}
+
+	recordingID, err := service.StartRecording(context.Background(), StartRecordingCommand{
+		OutputFormat:           "wav",
+		AutoStopSilenceSeconds: 2.5,
+		TranscribeOnStop:       true,
+		Tags:                   []string{"hotkey"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if recordingOpts.SilenceTimeout != 2500*time.Millisecond || recordingOpts.OnSilence == nil {
+		t.Fatalf("Expected a 2.5s silence timeout, got %+v", recordingOpts)
+	}
+
+	recordingOpts.OnSilence()
+
+	// started, finished and transcription.succeeded
+	events := waitForEvents(t, eventPublisher, 3)
+
+	finished := events[1]
+	if finished.Subject != "speakr.event.recording.finished" {
+		t.Fatalf("Expected recording.finished, got %s", finished.Subject)
+	}
+
+	data := finished.Data.(map[string]interface{})
+	if data["recording_id"] != recordingID || data["stop_reason"] != StopReasonSilence {
+		t.Errorf("Expected %s stopped on silence, got %+v", recordingID, data)
+	}
+
+	if events[2].Subject != "speakr.event.transcription.succeeded" {
+		t.Errorf("Expected transcribe_on_stop to be honoured, got %s", events[2].Subject)
+	}
+}
+
+func TestService_StartRecording_NoAutoStopByDefault(t *testing.T) {
+	service, recorder, _, _, _, _ := createTestService()
+
+	recorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		if opts.SilenceTimeout != 0 || opts.OnSilence != nil {
+			t.Errorf("Expected no silence detection, got %+v", opts)
+		}
+		return nil
+	}
+
+	if _, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestService_StartRecording_NegativeAutoStop(t *testing.T) {
+	service, recorder, _, _, _, _ := createTestService()
+
+	recorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		t.Error("Expected the recorder not to be started")
+		return nil
+	}
+
+	_, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav", AutoStopSilenceSeconds: -1})
+	if !errors.Is(err, ErrInvalidAutoStop) {
+		t.Errorf("Expected ErrInvalidAutoStop, got %v", err)
+	}
+}
+
+func TestService_StartRecording_StopsAtLimit(t *testing.T) {
+	service, recorder, _, _, _, eventPublisher := createTestService()
+
+	var recordingOpts ports.RecordingOptions
+	recorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		recordingOpts = opts
+		return nil
+	}
+
+	_, err := service.StartRecording(context.Background(), StartRecordingCommand{
+		OutputFormat:       "wav",
+		MaxDurationSeconds: 600,
+		MaxBytes:           50 << 20,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if recordingOpts.MaxDuration != 10*time.Minute || recordingOpts.MaxBytes != 50<<20 || recordingOpts.OnLimit == nil {
+		t.Fatalf("Expected a 10m and 50 MB limit, got %+v", recordingOpts)
+	}
+
+	recordingOpts.OnLimit()
+
+	// started and finished; transcribe_on_stop was not requested
+	events := waitForEvents(t, eventPublisher, 2)
+
+	data := events[1].Data.(map[string]interface{})
+	if events[1].Subject != "speakr.event.recording.finished" || data["stop_reason"] != StopReasonLimit {
+		t.Errorf("Expected recording.finished with stop_reason %q, got %s %+v", StopReasonLimit, events[1].Subject, data)
+	}
+}
+
+func TestService_StartRecording_NegativeLimit(t *testing.T) {
+	service, _, _, _, _, _ := createTestService()
+
+	_, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav", MaxBytes: -1})
+	if !errors.Is(err, ErrInvalidRecordingLimit) {
+		t.Errorf("Expected ErrInvalidRecordingLimit, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package core
+
+import "errors"
+
+// Custom error types for predictable failures
+var (
+	ErrMissingAudioSource        = errors.New("neither recording_id nor audio_data provided")
+	ErrInvalidAudioData          = errors.New("audio_data is not valid base64")
+	ErrEmptyAudioData            = errors.New("audio_data decodes to an empty payload")
+	ErrRecordingNotFound         = errors.New("recording is not in progress")
+	ErrUnknownAudioFormat        = errors.New("audio format could not be detected")
+	ErrUnsupportedSubtitleFormat = errors.New("unsupported subtitle format")
+	ErrInvalidTask               = errors.New("task must be transcribe or translate")
+	ErrUnknownProfile            = errors.New("unknown transcription profile")
+	ErrInvalidAutoStop           = errors.New("auto_stop_silence_seconds must not be negative")
+	ErrInvalidRecordingLimit     = errors.New("max_duration_seconds and max_bytes must not be negative")
+
+	ErrLiveTranscriptionUnavailable = errors.New("live transcription needs a transcription provider on the recording host")
+)
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultLiveSegmentDuration is how much audio each partial transcript covers
+const defaultLiveSegmentDuration = 15 * time.Second
+
+// liveTranscriptRetention is how long the partials of a stopped recording are kept
+// for a later transcription.run when the recording was not transcribed on stop
+const liveTranscriptRetention = time.Hour
+
+// liveTranscript collects the partial transcripts of a recording as its live segments
+// are transcribed in the background
+type liveTranscript struct {
+	recordingID   string
+	opts          ports.TranscriptionOptions
+	preprocessing []string
+	profile       *TranscriptionProfile
+	tags          []string
+	metadata      map[string]interface{}
+	ctx           context.Context // outlives the start command, keeps its correlation ID
+	logger        *slog.Logger
+
+	pending sync.WaitGroup
+
+	mu        sync.Mutex
+	delivered int                                // segments handed over by the recorder
+	results   map[int]*ports.TranscriptionResult // by sequence, timed from the start of the recording
+	failed    bool
+	stoppedAt time.Time
+}
+
+// startLiveTranscript registers the partial transcripts of a new recording and returns
+// the recording options that feed its live segments into them
+func (s *Service) startLiveTranscript(ctx context.Context, logger *slog.Logger, recordingID string, cmd StartRecordingCommand, opts ports.TranscriptionOptions, profile *TranscriptionProfile) ports.RecordingOptions {
+	if profile != nil {
+		opts = profile.apply(opts)
+	}
+
+	live := &liveTranscript{
+		recordingID:   recordingID,
+		opts:          opts,
+		preprocessing: s.preprocessingFor(profile),
+		profile:       profile,
+		tags:          mergeTags(cmd.Tags),
+		metadata:      cmd.Metadata,
+		ctx:           context.WithoutCancel(ctx),
+		logger:        logger.With("operation", "transcribe_live_segment"),
+		results:       make(map[int]*ports.TranscriptionResult),
+	}
+
+	s.liveMu.Lock()
+	s.pruneLiveTranscripts(time.Now())
+	s.live[recordingID] = live
+	s.liveMu.Unlock()
+
+	return ports.RecordingOptions{
+		LiveSegmentDuration: s.liveSegmentDuration,
+		OnLiveSegment: func(segment ports.LiveSegment) {
+			live.mu.Lock()
+			live.delivered++
+			live.mu.Unlock()
+
+			// Registered before the recorder returns from StopRecording, so a final
+			// transcription always waits for every segment
+			live.pending.Add(1)
+			go func() {
+				defer live.pending.Done()
+				s.transcribeLiveSegment(live, segment)
+			}()
+		},
+	}
+}
+
+// transcribeLiveSegment transcribes one live segment and publishes it as a partial transcript
+func (s *Service) transcribeLiveSegment(live *liveTranscript, segment ports.LiveSegment) {
+	logger := live.logger.With("sequence", segment.Sequence, "offset", segment.Offset)
+
+	audioData, format, trimmed, err := s.preprocessAudio(live.ctx, logger, bytes.NewReader(segment.Audio), segment.Format, live.preprocessing)
+	if err != nil {
+		live.fail()
+		return
+	}
+
+	ctx, span := tracer.Start(live.ctx, "TranscriptionService.TranscribeAudio", trace.WithAttributes(attribute.Int("sequence", segment.Sequence)))
+	result, err := s.transcriptionSvc.TranscribeAudio(ctx, audioData, format, live.opts)
+	endSpan(span, err)
+	if errors.Is(err, ports.ErrNoSpeech) {
+		logger.Debug("Live segment has no speech")
+		live.store(segment.Sequence, &ports.TranscriptionResult{})
+		return
+	}
+	if err != nil {
+		logger.Warn("Failed to transcribe live segment, the final transcript will transcribe the full recording", "error", err)
+		live.fail()
+		return
+	}
+
+	offsetResult(result, (segment.Offset + trimmed).Seconds())
+	live.store(segment.Sequence, result)
+
+	partial := cloneResult(result)
+	if live.profile != nil {
+		live.profile.applyReplacements(partial)
+	}
+
+	segments := partial.Segments
+	if segments == nil {
+		segments = []ports.TranscriptSegment{}
+	}
+
+	event := ports.Event{
+		Subject: "speakr.event.transcription.partial",
+		Data: map[string]interface{}{
+			"recording_id":  live.recordingID,
+			"sequence":      segment.Sequence,
+			"text":          partial.Text,
+			"start_seconds": segment.Offset.Seconds(),
+			"end_seconds":   (segment.Offset + segment.Duration).Seconds(),
+			"segments":      segments,
+			"tags":          live.tags,
+			"metadata":      live.metadata,
+		},
+	}
+
+	if err := s.eventPublisher.PublishEvent(live.ctx, event); err != nil {
+		logger.Error("Failed to publish partial transcript", "error", err)
+		return
+	}
+
+	logger.Info("Partial transcript published", "text_length", len(partial.Text))
+}
+
+// store records the transcript of a live segment
+func (live *liveTranscript) store(sequence int, result *ports.TranscriptionResult) {
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	live.results[sequence] = result
+}
+
+// fail marks the partials as unusable for the final transcript
+func (live *liveTranscript) fail() {
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	live.failed = true
+}
+
+// assemble joins the partials into the transcript of the whole recording. It returns
+// nil if any segment is missing or failed, or nothing was said.
+func (live *liveTranscript) assemble() *ports.TranscriptionResult {
+	live.mu.Lock()
+	defer live.mu.Unlock()
+
+	if live.failed || live.delivered == 0 || len(live.results) != live.delivered {
+		return nil
+	}
+
+	assembled := &ports.TranscriptionResult{}
+	var texts []string
+	var results []*ports.TranscriptionResult
+
+	for sequence := 0; sequence < live.delivered; sequence++ {
+		result, ok := live.results[sequence]
+		if !ok {
+			return nil
+		}
+
+		results = append(results, result)
+		if result.Text != "" {
+			texts = append(texts, result.Text)
+		}
+		if assembled.Language == "" {
+			assembled.Language = result.Language
+		}
+		if result.Duration > assembled.Duration {
+			assembled.Duration = result.Duration
+		}
+		for _, segment := range result.Segments {
+			segment.ID = len(assembled.Segments)
+			assembled.Segments = append(assembled.Segments, segment)
+		}
+		assembled.Words = append(assembled.Words, result.Words...)
+	}
+
+	if len(texts) == 0 {
+		return nil
+	}
+
+	assembled.Text = strings.Join(texts, " ")
+	assembled.Provider = ports.JoinProviders(results)
+	return assembled
+}
+
+// takeLiveResult removes the recording's partial transcripts and returns them joined
+// into a full transcript, or nil if there are none or they cannot stand in for a
+// transcription with these options
+func (s *Service) takeLiveResult(logger *slog.Logger, recordingID string, opts ports.TranscriptionOptions, preprocessing []string) *ports.TranscriptionResult {
+	s.liveMu.Lock()
+	live, ok := s.live[recordingID]
+	delete(s.live, recordingID)
+	s.liveMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	// Partials still in flight are published before the final transcript either way
+	live.pending.Wait()
+
+	if live.opts != opts || !slices.Equal(live.preprocessing, preprocessing) {
+		logger.Info("Transcription options differ from the live transcript, transcribing the full recording")
+		return nil
+	}
+
+	result := live.assemble()
+	if result == nil {
+		logger.Info("Partial transcripts are incomplete, transcribing the full recording")
+		return nil
+	}
+
+	logger.Info("Reusing partial transcripts", "partials", live.delivered)
+	return result
+}
+
+// stopLiveTranscript marks the partials of a stopped recording, which are kept
+// for a later transcription until the retention runs out
+func (s *Service) stopLiveTranscript(recordingID string) {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	if live, ok := s.live[recordingID]; ok {
+		live.mu.Lock()
+		live.stoppedAt = time.Now()
+		live.mu.Unlock()
+	}
+}
+
+// dropLiveTranscript discards the partials of a recording
+func (s *Service) dropLiveTranscript(recordingID string) {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	delete(s.live, recordingID)
+}
+
+// pruneLiveTranscripts drops partials of recordings stopped longer ago than the retention.
+// The caller holds liveMu.
+func (s *Service) pruneLiveTranscripts(now time.Time) {
+	for recordingID, live := range s.live {
+		live.mu.Lock()
+		expired := !live.stoppedAt.IsZero() && now.Sub(live.stoppedAt) > liveTranscriptRetention
+		live.mu.Unlock()
+
+		if expired {
+			delete(s.live, recordingID)
+		}
+	}
+}
+
+// offsetResult shifts the timings of a segment's transcript to its position in the recording
+func offsetResult(result *ports.TranscriptionResult, offset float64) {
+	for i := range result.Segments {
+		result.Segments[i].Start += offset
+		result.Segments[i].End += offset
+	}
+	for i := range result.Words {
+		result.Words[i].Start += offset
+		result.Words[i].End += offset
+	}
+	if result.Duration > 0 {
+		result.Duration += offset
+	}
+}
+
+// cloneResult copies a result so its text can be changed without affecting the original
+func cloneResult(result *ports.TranscriptionResult) *ports.TranscriptionResult {
+	clone := *result
+	clone.Segments = append([]ports.TranscriptSegment(nil), result.Segments...)
+	clone.Words = append([]ports.TranscriptWord(nil), result.Words...)
+	return &clone
+}
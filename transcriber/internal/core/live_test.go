@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// startLiveRecording starts a live-transcribed recording and returns its ID and the
+// options the recorder received
+func startLiveRecording(t *testing.T, service *Service, recorder *mockAudioRecorder, cmd StartRecordingCommand) (string, ports.RecordingOptions) {
+	t.Helper()
+
+	var recordingOpts ports.RecordingOptions
+	recorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		recordingOpts = opts
+		return nil
+	}
+
+	cmd.LiveTranscription = true
+	recordingID, err := service.StartRecording(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return recordingID, recordingOpts
+}
+
+// liveSegment builds a live segment whose audio is the given text
+func liveSegment(recordingID string, sequence int, text string) ports.LiveSegment {
+	return ports.LiveSegment{
+		RecordingID: recordingID,
+		Sequence:    sequence,
+		Offset:      time.Duration(sequence) * 15 * time.Second,
+		Duration:    15 * time.Second,
+		Format:      "wav",
+		Audio:       []byte(text),
+	}
+}
+
+// echoTranscriber transcribes audio to its own bytes, failing with the error set for a text
+type echoTranscriber struct {
+	mu     sync.Mutex
+	calls  []string
+	errors map[string]error
+}
+
+func (e *echoTranscriber) transcribe(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+	data, _ := io.ReadAll(audioData)
+	text := string(data)
+
+	e.mu.Lock()
+	e.calls = append(e.calls, text)
+	e.mu.Unlock()
+
+	if err := e.errors[text]; err != nil {
+		return nil, err
+	}
+	return &ports.TranscriptionResult{
+		Text:     text,
+		Segments: []ports.TranscriptSegment{{ID: 0, Start: 1, End: 3, Text: text}},
+	}, nil
+}
+
+func eventsWithSubject(events []ports.Event, subject string) []map[string]interface{} {
+	var matching []map[string]interface{}
+	for _, event := range events {
+		if event.Subject == subject {
+			matching = append(matching, event.Data.(map[string]interface{}))
+		}
+	}
+	return matching
+}
+
+func TestService_LiveTranscription_PublishesPartialsAndReusesThem(t *testing.T) {
+	service, recorder, transcriptionSvc, _, _, eventPublisher := createTestService()
+	echo := &echoTranscriber{}
+	transcriptionSvc.transcribeAudioFunc = echo.transcribe
+
+	recordingID, opts := startLiveRecording(t, service, recorder, StartRecordingCommand{
+		OutputFormat: "wav",
+		Tags:         []string{"standup"},
+	})
+
+	if opts.LiveSegmentDuration != defaultLiveSegmentDuration || opts.OnLiveSegment == nil {
+		t.Fatalf("Expected live segments every %v, got %+v", defaultLiveSegmentDuration, opts)
+	}
+
+	recorder.stopRecordingFunc = func(ctx context.Context, id string) (io.Reader, error) {
+		opts.OnLiveSegment(liveSegment(id, 0, "first words"))
+		opts.OnLiveSegment(liveSegment(id, 1, "and the rest"))
+		return strings.NewReader("full recording"), nil
+	}
+
+	err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: recordingID, TranscribeOnStop: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(echo.calls) != 2 {
+		t.Errorf("Expected only the live segments to be transcribed, got %q", echo.calls)
+	}
+
+	partials := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.partial")
+	if len(partials) != 2 {
+		t.Fatalf("Expected 2 partial transcripts, got %d", len(partials))
+	}
+	for _, partial := range partials {
+		if partial["recording_id"] != recordingID {
+			t.Errorf("Expected partial for %s, got %v", recordingID, partial["recording_id"])
+		}
+		if partial["sequence"] == 1 && (partial["text"] != "and the rest" || partial["start_seconds"] != 15.0) {
+			t.Errorf("Unexpected second partial: %+v", partial)
+		}
+	}
+
+	succeeded := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.succeeded")
+	if len(succeeded) != 1 {
+		t.Fatalf("Expected a transcription.succeeded event, got %d", len(succeeded))
+	}
+
+	data := succeeded[0]
+	if data["transcribed_text"] != "first words and the rest" || data["from_partials"] != true {
+		t.Errorf("Expected the joined partials, got text=%v from_partials=%v", data["transcribed_text"], data["from_partials"])
+	}
+
+	segments := data["segments"].([]ports.TranscriptSegment)
+	if len(segments) != 2 || segments[1].ID != 1 || segments[1].Start != 16 || segments[1].End != 18 {
+		t.Errorf("Expected segments timed from the start of the recording, got %+v", segments)
+	}
+}
+
+func TestService_LiveTranscription_FailedSegmentFallsBackToFullRecording(t *testing.T) {
+	service, recorder, transcriptionSvc, _, _, eventPublisher := createTestService()
+	echo := &echoTranscriber{errors: map[string]error{"garbled": errors.New("provider unavailable")}}
+	transcriptionSvc.transcribeAudioFunc = echo.transcribe
+
+	recordingID, opts := startLiveRecording(t, service, recorder, StartRecordingCommand{OutputFormat: "wav"})
+
+	recorder.stopRecordingFunc = func(ctx context.Context, id string) (io.Reader, error) {
+		opts.OnLiveSegment(liveSegment(id, 0, "clear"))
+		opts.OnLiveSegment(liveSegment(id, 1, "garbled"))
+		return strings.NewReader("full recording"), nil
+	}
+
+	err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: recordingID, TranscribeOnStop: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	succeeded := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.succeeded")
+	if len(succeeded) != 1 {
+		t.Fatalf("Expected a transcription.succeeded event, got %d", len(succeeded))
+	}
+
+	// The mock object store returns its own audio for the full recording
+	if succeeded[0]["transcribed_text"] != "mock audio data" || succeeded[0]["from_partials"] != nil {
+		t.Errorf("Expected the full recording to be transcribed, got %+v", succeeded[0])
+	}
+}
+
+func TestService_LiveTranscription_DifferentOptionsTranscribeFullRecording(t *testing.T) {
+	service, recorder, transcriptionSvc, _, _, eventPublisher := createTestService()
+	echo := &echoTranscriber{}
+	transcriptionSvc.transcribeAudioFunc = echo.transcribe
+
+	recordingID, opts := startLiveRecording(t, service, recorder, StartRecordingCommand{OutputFormat: "wav"})
+
+	recorder.stopRecordingFunc = func(ctx context.Context, id string) (io.Reader, error) {
+		opts.OnLiveSegment(liveSegment(id, 0, "hello"))
+		return strings.NewReader("full recording"), nil
+	}
+
+	if err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: recordingID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cmd := TranscriptionCommand{RecordingID: recordingID, Task: ports.TaskTranslate}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	succeeded := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.succeeded")
+	if len(succeeded) != 1 || succeeded[0]["transcribed_text"] != "mock audio data" {
+		t.Errorf("Expected the full recording to be translated, got %+v", succeeded)
+	}
+}
+
+func TestService_LiveTranscription_SkipsSegmentsWithoutSpeech(t *testing.T) {
+	service, recorder, transcriptionSvc, _, _, eventPublisher := createTestService()
+	echo := &echoTranscriber{errors: map[string]error{"": ports.ErrNoSpeech}}
+	transcriptionSvc.transcribeAudioFunc = echo.transcribe
+
+	recordingID, opts := startLiveRecording(t, service, recorder, StartRecordingCommand{OutputFormat: "wav"})
+
+	recorder.stopRecordingFunc = func(ctx context.Context, id string) (io.Reader, error) {
+		opts.OnLiveSegment(liveSegment(id, 0, ""))
+		opts.OnLiveSegment(liveSegment(id, 1, "finally"))
+		return strings.NewReader("full recording"), nil
+	}
+
+	err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: recordingID, TranscribeOnStop: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if partials := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.partial"); len(partials) != 1 {
+		t.Errorf("Expected a partial only for the segment with speech, got %d", len(partials))
+	}
+
+	succeeded := eventsWithSubject(eventPublisher.publishedEvents, "speakr.event.transcription.succeeded")
+	if len(succeeded) != 1 || succeeded[0]["transcribed_text"] != "finally" || succeeded[0]["from_partials"] != true {
+		t.Errorf("Expected the partials to be reused, got %+v", succeeded)
+	}
+}
+
+func TestService_LiveTranscription_CancelDropsPartials(t *testing.T) {
+	service, recorder, _, _, _, _ := createTestService()
+
+	recordingID, _ := startLiveRecording(t, service, recorder, StartRecordingCommand{OutputFormat: "wav"})
+
+	if err := service.CancelRecording(context.Background(), CancelRecordingCommand{RecordingID: recordingID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := service.live[recordingID]; ok {
+		t.Error("Expected the partial transcripts to be dropped")
+	}
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"errors"
+	"io"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Recording and storage metrics, served on /metrics with those of the adapters
+var (
+	activeRecordings = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "active_recordings",
+		Help:      "Recordings started and not yet stopped or cancelled.",
+	})
+
+	recordingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "recording_duration_seconds",
+		Help:      "Time from the start to the stop of a recording, by stop reason.",
+		Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"stop_reason"})
+
+	audioBytesStored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "speakr",
+		Subsystem: "transcriber",
+		Name:      "audio_stored_bytes_total",
+		Help:      "Bytes of audio written to the object store, by source: recording or upload.",
+	}, []string{"source"})
+)
+
+// Sources of stored audio
+const (
+	audioSourceRecording = "recording"
+	audioSourceUpload    = "upload"
+)
+
+// recordingDropped reports whether the recorder no longer holds a recording after a
+// stop or cancel that returned err, so it no longer counts as active
+func recordingDropped(err error) bool {
+	return !errors.Is(err, ports.ErrRecordingNotFound)
+}
+
+// countingReader counts the bytes read through it, so the size of audio streamed to
+// the object store is known once it is stored
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// sampleCount returns how many values a histogram has observed
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := observer.(prometheus.Metric).Write(&metric); err != nil {
+		t.Fatalf("Failed to read histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestService_RecordingMetrics(t *testing.T) {
+	service, _, _, objectStore, _, _ := createTestService()
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		_, err := io.Copy(io.Discard, audioData)
+		return "/mock/path/" + recordingID + "." + format, err
+	}
+
+	active := testutil.ToFloat64(activeRecordings)
+	stored := testutil.ToFloat64(audioBytesStored.WithLabelValues(audioSourceRecording))
+	stopped := sampleCount(t, recordingDuration.WithLabelValues(StopReasonManual))
+
+	ctx := context.Background()
+	recordingID, err := service.StartRecording(ctx, StartRecordingCommand{OutputFormat: "wav"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(activeRecordings); got != active+1 {
+		t.Errorf("Expected %v active recordings, got %v", active+1, got)
+	}
+
+	if err := service.StopRecording(ctx, StopRecordingCommand{RecordingID: recordingID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := testutil.ToFloat64(activeRecordings); got != active {
+		t.Errorf("Expected %v active recordings, got %v", active, got)
+	}
+	if got := testutil.ToFloat64(audioBytesStored.WithLabelValues(audioSourceRecording)); got != stored+float64(len("mock audio data")) {
+		t.Errorf("Expected the recorded bytes to be counted, got %v more", got-stored)
+	}
+	if got := sampleCount(t, recordingDuration.WithLabelValues(StopReasonManual)); got != stopped+1 {
+		t.Errorf("Expected the recording duration to be observed, got %d samples", got-stopped)
+	}
+}
+
+func TestService_ActiveRecordingsAfterFailedStop(t *testing.T) {
+	service, recorder, _, _, _, _ := createTestService()
+	ctx := context.Background()
+
+	recordingID, err := service.StartRecording(ctx, StartRecordingCommand{OutputFormat: "wav"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	active := testutil.ToFloat64(activeRecordings)
+
+	// ffmpeg already exited: the recorder drops the recording but has no audio
+	recorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return nil, errors.New("failed to open recording file")
+	}
+	if err := service.StopRecording(ctx, StopRecordingCommand{RecordingID: recordingID}); err == nil {
+		t.Fatal("Expected the stop to fail")
+	}
+	if got := testutil.ToFloat64(activeRecordings); got != active-1 {
+		t.Errorf("Expected the dropped recording to leave the gauge, got %v active, want %v", got, active-1)
+	}
+
+	// An unknown recording was never counted
+	recorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return nil, fmt.Errorf("recording not found: %w", ports.ErrRecordingNotFound)
+	}
+	if err := service.StopRecording(ctx, StopRecordingCommand{RecordingID: recordingID}); err == nil {
+		t.Fatal("Expected the stop to fail")
+	}
+	if got := testutil.ToFloat64(activeRecordings); got != active-1 {
+		t.Errorf("Expected an unknown recording not to change the gauge, got %v active, want %v", got, active-1)
+	}
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// preprocessingSteps lists the steps an audio pre-processor understands
+var preprocessingSteps = map[string]bool{
+	ports.PreprocessHighpass:    true,
+	ports.PreprocessDenoise:     true,
+	ports.PreprocessLoudnorm:    true,
+	ports.PreprocessTrimSilence: true,
+	ports.PreprocessResample:    true,
+}
+
+// ParsePreprocessing parses a comma-separated pre-processing chain such as
+// "highpass,denoise,loudnorm". An empty list means no pre-processing.
+func ParsePreprocessing(chain string) ([]string, error) {
+	var steps []string
+	for _, step := range strings.Split(chain, ",") {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return normalizePreprocessing(steps)
+}
+
+// normalizePreprocessing lower-cases the steps of a chain and rejects unknown ones.
+// The order is kept, as it is the order the filters run in.
+func normalizePreprocessing(steps []string) ([]string, error) {
+	if steps == nil {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(steps))
+	for _, step := range steps {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if !preprocessingSteps[step] {
+			return nil, fmt.Errorf("unknown pre-processing step %q", step)
+		}
+		normalized = append(normalized, step)
+	}
+	return normalized, nil
+}
+
+// preprocessingFor returns the pre-processing chain for a transcription: the profile's
+// when it sets one, even an empty one, else the configured default
+func (s *Service) preprocessingFor(profile *TranscriptionProfile) []string {
+	if s.preprocessor == nil {
+		return nil
+	}
+	if profile != nil && profile.Preprocessing != nil {
+		return profile.Preprocessing
+	}
+	return s.preprocessing
+}
+
+// preprocessAudio runs the chain over the audio, returning the processed audio, its
+// format and how much was trimmed from its start
+func (s *Service) preprocessAudio(ctx context.Context, logger *slog.Logger, audioData io.Reader, format string, steps []string) (io.Reader, string, time.Duration, error) {
+	if len(steps) == 0 {
+		return audioData, format, 0, nil
+	}
+
+	processed, err := s.preprocessor.Preprocess(ctx, audioData, format, steps)
+	if err != nil {
+		logger.Error("Failed to pre-process audio", "error", err, "preprocessing", steps)
+		return nil, "", 0, fmt.Errorf("failed to pre-process audio: %w", err)
+	}
+
+	logger.Debug("Audio pre-processed", "preprocessing", steps, "trimmed", processed.Trimmed)
+	return processed.Audio, processed.Format, processed.Trimmed, nil
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"speakr/transcriber/internal/ports"
+)
+
+type mockPreprocessor struct {
+	steps   []string
+	trimmed time.Duration
+}
+
+func (m *mockPreprocessor) Preprocess(ctx context.Context, audioData io.Reader, format string, steps []string) (*ports.PreprocessedAudio, error) {
+	data, _ := io.ReadAll(audioData)
+	m.steps = steps
+	return &ports.PreprocessedAudio{
+		Audio:   strings.NewReader("clean " + string(data)),
+		Format:  "wav",
+		Trimmed: m.trimmed,
+	}, nil
+}
+
+func TestParsePreprocessing(t *testing.T) {
+	steps, err := ParsePreprocessing(" highpass, Denoise,,loudnorm ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !slices.Equal(steps, []string{"highpass", "denoise", "loudnorm"}) {
+		t.Errorf("Unexpected steps: %v", steps)
+	}
+
+	if steps, err := ParsePreprocessing(""); err != nil || steps != nil {
+		t.Errorf("Expected no steps for an empty chain, got %v, %v", steps, err)
+	}
+
+	if _, err := ParsePreprocessing("highpass,reverb"); err == nil {
+		t.Error("Expected error for an unknown step")
+	}
+}
+
+func TestService_TranscribeAudio_Preprocesses(t *testing.T) {
+	service, _, transcriptionSvc, objectStore, _, eventPublisher := createTestService()
+
+	preprocessor := &mockPreprocessor{trimmed: 2 * time.Second}
+	WithPreprocessor(preprocessor, []string{ports.PreprocessHighpass, ports.PreprocessTrimSilence})(service)
+
+	objectStore.retrieveAudioFunc = func(ctx context.Context, recordingID string) (io.Reader, string, error) {
+		return strings.NewReader("audio"), "mp3", nil
+	}
+
+	var received, receivedFormat string
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		data, _ := io.ReadAll(audioData)
+		received, receivedFormat = string(data), format
+		return &ports.TranscriptionResult{
+			Text:     "hello",
+			Segments: []ports.TranscriptSegment{{Start: 0.5, End: 1.5, Text: "hello"}},
+		}, nil
+	}
+
+	if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if received != "clean audio" || receivedFormat != "wav" {
+		t.Errorf("Expected the pre-processed audio, got %q as %s", received, receivedFormat)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if steps, _ := data["preprocessing"].([]string); !slices.Equal(steps, []string{"highpass", "trim_silence"}) {
+		t.Errorf("Expected the applied chain in the event, got %v", data["preprocessing"])
+	}
+
+	segments := data["segments"].([]ports.TranscriptSegment)
+	if segments[0].Start != 2.5 || segments[0].End != 3.5 {
+		t.Errorf("Expected timings shifted back by the trimmed silence, got %+v", segments[0])
+	}
+}
+
+func TestService_TranscribeAudio_ProfileChoosesPreprocessing(t *testing.T) {
+	service, _, _, _, _, eventPublisher := createTestService()
+
+	preprocessor := &mockPreprocessor{}
+	WithPreprocessor(preprocessor, []string{ports.PreprocessHighpass})(service)
+
+	profiles, err := ParseProfiles([]byte(`{"profiles": [
+		{"name": "office", "match_tags": ["office"], "preprocessing": ["denoise", "loudnorm", "resample"]},
+		{"name": "studio", "match_tags": ["studio"], "preprocessing": []}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse profiles: %v", err)
+	}
+	WithProfiles(profiles)(service)
+
+	cmd := TranscriptionCommand{RecordingID: "test-recording-id", Tags: []string{"office"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !slices.Equal(preprocessor.steps, []string{"denoise", "loudnorm", "resample"}) {
+		t.Errorf("Expected the profile's chain, got %v", preprocessor.steps)
+	}
+
+	preprocessor.steps = nil
+	cmd.Tags = []string{"studio"}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if preprocessor.steps != nil {
+		t.Errorf("Expected an empty chain to turn pre-processing off, got %v", preprocessor.steps)
+	}
+
+	if data := eventPublisher.publishedEvents[1].Data.(map[string]interface{}); data["preprocessing"] != nil {
+		t.Errorf("Expected no preprocessing in the event, got %v", data["preprocessing"])
+	}
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// profileMetadataKey is the metadata field that selects a transcription profile by name
+const profileMetadataKey = "profile"
+
+// TranscriptionProfile is a named set of provider settings and transcript fixes for a kind
+// of dictation, such as engineering standups full of service names and acronyms
+type TranscriptionProfile struct {
+	Name          string        `json:"name"`
+	MatchTags     []string      `json:"match_tags,omitempty"`
+	Model         string        `json:"model,omitempty"`
+	Language      string        `json:"language,omitempty"`
+	Prompt        string        `json:"prompt,omitempty"`
+	Task          string        `json:"task,omitempty"`
+	Temperature   float64       `json:"temperature,omitempty"`
+	Preprocessing []string      `json:"preprocessing,omitempty"`
+	Replacements  []Replacement `json:"replacements,omitempty"`
+}
+
+// Replacement is a post-processing rule applied to the transcript. From matches a word or
+// phrase case-insensitively on word boundaries; Pattern is a regular expression instead,
+// whose To may refer to submatches as $1.
+type Replacement struct {
+	From    string `json:"from,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	To      string `json:"to"`
+
+	re *regexp.Regexp
+}
+
+// Profiles holds the configured transcription profiles and the default used when
+// neither the metadata nor the tags of a command select one
+type Profiles struct {
+	Default  string                 `json:"default,omitempty"`
+	Profiles []TranscriptionProfile `json:"profiles"`
+}
+
+// ParseProfiles parses and validates a JSON profile configuration
+func ParseProfiles(data []byte) (*Profiles, error) {
+	var profiles Profiles
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription profiles: %w", err)
+	}
+
+	seen := make(map[string]bool, len(profiles.Profiles))
+	for i := range profiles.Profiles {
+		profile := &profiles.Profiles[i]
+
+		if profile.Name == "" {
+			return nil, fmt.Errorf("transcription profile %d has no name", i+1)
+		}
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("duplicate transcription profile %q", profile.Name)
+		}
+		seen[profile.Name] = true
+
+		if profile.Temperature < 0 || profile.Temperature > 1 {
+			return nil, fmt.Errorf("profile %q: temperature must be between 0 and 1, got %v", profile.Name, profile.Temperature)
+		}
+
+		opts, err := normalizeTranscriptionOptions(profile.Language, profile.Prompt, profile.Task)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+		profile.Language, profile.Prompt, profile.Task = opts.Language, opts.Prompt, opts.Task
+
+		// An empty list turns off the default pre-processing for the profile
+		if profile.Preprocessing, err = normalizePreprocessing(profile.Preprocessing); err != nil {
+			return nil, fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+
+		for j := range profile.Replacements {
+			if err := profile.Replacements[j].compile(); err != nil {
+				return nil, fmt.Errorf("profile %q: replacement %d: %w", profile.Name, j+1, err)
+			}
+		}
+	}
+
+	if profiles.Default != "" && !seen[profiles.Default] {
+		return nil, fmt.Errorf("default transcription profile %q is not defined", profiles.Default)
+	}
+
+	return &profiles, nil
+}
+
+// compile builds the rule's regular expression
+func (r *Replacement) compile() error {
+	switch {
+	case r.From != "" && r.Pattern != "":
+		return fmt.Errorf("only one of from and pattern may be set")
+	case r.From != "":
+		r.re = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(r.From) + `\b`)
+	case r.Pattern != "":
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.re = re
+	default:
+		return fmt.Errorf("one of from or pattern is required")
+	}
+	return nil
+}
+
+// Lookup returns the profile with the given name, or nil if there is none
+func (p *Profiles) Lookup(name string) *TranscriptionProfile {
+	for i := range p.Profiles {
+		if p.Profiles[i].Name == name {
+			return &p.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// Select picks the profile for a command: the one named by metadata.profile, else the
+// first profile (in configuration order) matching one of the tags, else the default.
+// It returns nil when nothing applies and ErrUnknownProfile for an unknown name.
+func (p *Profiles) Select(tags []string, metadata map[string]interface{}) (*TranscriptionProfile, error) {
+	if name, ok := metadata[profileMetadataKey].(string); ok && name != "" {
+		profile := p.Lookup(name)
+		if profile == nil {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+		}
+		return profile, nil
+	}
+
+	for i := range p.Profiles {
+		for _, matchTag := range p.Profiles[i].MatchTags {
+			for _, tag := range tags {
+				if strings.EqualFold(tag, matchTag) {
+					return &p.Profiles[i], nil
+				}
+			}
+		}
+	}
+
+	if p.Default != "" {
+		return p.Lookup(p.Default), nil
+	}
+	return nil, nil
+}
+
+// apply fills the options the command left empty from the profile. The profile's
+// vocabulary prompt is kept in front of a prompt given by the command.
+func (profile *TranscriptionProfile) apply(opts ports.TranscriptionOptions) ports.TranscriptionOptions {
+	if opts.Language == "" {
+		opts.Language = profile.Language
+	}
+	if opts.Task == "" {
+		opts.Task = profile.Task
+	}
+	switch {
+	case opts.Prompt == "":
+		opts.Prompt = profile.Prompt
+	case profile.Prompt != "":
+		opts.Prompt = profile.Prompt + " " + opts.Prompt
+	}
+	opts.Model = profile.Model
+	opts.Temperature = profile.Temperature
+	return opts
+}
+
+// applyReplacements runs the profile's replacement rules over the transcript text,
+// segments and words. Words are replaced one at a time, so rules that match across
+// several words leave the timed words unchanged.
+func (profile *TranscriptionProfile) applyReplacements(result *ports.TranscriptionResult) {
+	if len(profile.Replacements) == 0 {
+		return
+	}
+
+	result.Text = profile.replace(result.Text)
+	for i := range result.Segments {
+		result.Segments[i].Text = profile.replace(result.Segments[i].Text)
+	}
+	for i := range result.Words {
+		result.Words[i].Word = profile.replace(result.Words[i].Word)
+	}
+}
+
+// replace applies every replacement rule to the text in order
+func (profile *TranscriptionProfile) replace(text string) string {
+	for _, replacement := range profile.Replacements {
+		if replacement.From != "" {
+			text = replacement.re.ReplaceAllLiteralString(text, replacement.To)
+		} else {
+			text = replacement.re.ReplaceAllString(text, replacement.To)
+		}
+	}
+	return text
+}
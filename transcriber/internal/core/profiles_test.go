@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+const testProfilesJSON = `{
+	"default": "general",
+	"profiles": [
+		{"name": "general", "prompt": "Speakr"},
+		{
+			"name": "engineering",
+			"match_tags": ["standup", "Engineering"],
+			"model": "whisper-1",
+			"language": "EN",
+			"prompt": "NATS, JetStream, MinIO, kubectl",
+			"temperature": 0.2,
+			"replacements": [
+				{"from": "nuts", "to": "NATS"},
+				{"from": "cube control", "to": "kubectl"},
+				{"pattern": "(?i)mini ?o\\b", "to": "MinIO"}
+			]
+		},
+		{"name": "polish", "match_tags": ["pl"], "language": "pl", "task": "translate"}
+	]
+}`
+
+func TestParseProfiles(t *testing.T) {
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	engineering := profiles.Lookup("engineering")
+	if engineering == nil || engineering.Language != "en" || engineering.Temperature != 0.2 {
+		t.Fatalf("Unexpected engineering profile: %+v", engineering)
+	}
+
+	if profiles.Lookup("missing") != nil {
+		t.Error("Expected no profile for an unknown name")
+	}
+}
+
+func TestParseProfiles_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":      `{"profiles": [{"model": "whisper-1"}]}`,
+		"duplicate name":    `{"profiles": [{"name": "a"}, {"name": "a"}]}`,
+		"unknown default":   `{"default": "b", "profiles": [{"name": "a"}]}`,
+		"bad temperature":   `{"profiles": [{"name": "a", "temperature": 1.5}]}`,
+		"bad task":          `{"profiles": [{"name": "a", "task": "summarize"}]}`,
+		"empty replacement": `{"profiles": [{"name": "a", "replacements": [{"to": "x"}]}]}`,
+		"both from/pattern": `{"profiles": [{"name": "a", "replacements": [{"from": "a", "pattern": "b", "to": "x"}]}]}`,
+		"bad pattern":       `{"profiles": [{"name": "a", "replacements": [{"pattern": "(", "to": "x"}]}]}`,
+		"unknown step":      `{"profiles": [{"name": "a", "preprocessing": ["highpass", "reverb"]}]}`,
+		"not json":          `profiles:`,
+	}
+
+	for name, data := range tests {
+		if _, err := ParseProfiles([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestProfiles_Select(t *testing.T) {
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tags     []string
+		metadata map[string]interface{}
+		want     string
+	}{
+		{"metadata wins over tags", []string{"standup"}, map[string]interface{}{"profile": "polish"}, "polish"},
+		{"tag match is case-insensitive", []string{"project-x", "engineering"}, nil, "engineering"},
+		{"first matching profile", []string{"pl", "standup"}, nil, "engineering"},
+		{"default", []string{"project-x"}, nil, "general"},
+	}
+
+	for _, tc := range tests {
+		profile, err := profiles.Select(tc.tags, tc.metadata)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tc.name, err)
+		}
+		if profile == nil || profile.Name != tc.want {
+			t.Errorf("%s: expected profile %s, got %+v", tc.name, tc.want, profile)
+		}
+	}
+
+	if _, err := profiles.Select(nil, map[string]interface{}{"profile": "legal"}); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("Expected ErrUnknownProfile, got %v", err)
+	}
+
+	profiles.Default = ""
+	if profile, err := profiles.Select([]string{"project-x"}, nil); err != nil || profile != nil {
+		t.Errorf("Expected no profile without a default, got %+v, %v", profile, err)
+	}
+}
+
+func TestTranscriptionProfile_Apply(t *testing.T) {
+	profile := &TranscriptionProfile{
+		Name:        "engineering",
+		Model:       "whisper-1",
+		Language:    "en",
+		Prompt:      "NATS, MinIO",
+		Temperature: 0.2,
+	}
+
+	opts := profile.apply(ports.TranscriptionOptions{Language: "pl", Prompt: "Sprint review"})
+
+	expected := ports.TranscriptionOptions{
+		Language:    "pl",
+		Prompt:      "NATS, MinIO Sprint review",
+		Model:       "whisper-1",
+		Temperature: 0.2,
+	}
+	if opts != expected {
+		t.Errorf("Expected %+v, got %+v", expected, opts)
+	}
+}
+
+func TestTranscriptionProfile_ApplyReplacements(t *testing.T) {
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := &ports.TranscriptionResult{
+		Text:     "Nuts is down, so run cube control against mini o. Peanuts are fine.",
+		Segments: []ports.TranscriptSegment{{Text: "Nuts is down"}},
+		Words:    []ports.TranscriptWord{{Word: "Nuts"}, {Word: "cube"}, {Word: "control"}},
+	}
+	profiles.Lookup("engineering").applyReplacements(result)
+
+	if result.Text != "NATS is down, so run kubectl against MinIO. Peanuts are fine." {
+		t.Errorf("Unexpected text: %q", result.Text)
+	}
+
+	if result.Segments[0].Text != "NATS is down" {
+		t.Errorf("Unexpected segment text: %q", result.Segments[0].Text)
+	}
+
+	// Rules spanning several words cannot be applied to single words
+	if result.Words[0].Word != "NATS" || result.Words[1].Word != "cube" || result.Words[2].Word != "control" {
+		t.Errorf("Unexpected words: %+v", result.Words)
+	}
+}
@@ -1,14 +1,21 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"speakr/transcriber/internal/ports"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Service represents the core transcriber service
@@ -16,8 +23,77 @@ type Service struct {
 	audioRecorder       ports.AudioRecorder
 	transcriptionSvc    ports.TranscriptionService
 	objectStore         ports.ObjectStore
+	sessionRegistry     ports.SessionRegistry
 	eventPublisher      ports.EventPublisher
+	profiles            *Profiles
+	preprocessor        ports.AudioPreprocessor
+	preprocessing       []string
+	liveSegmentDuration time.Duration
+	transcoder          ports.AudioTranscoder
+	cache               ports.TranscriptionCache
+	cacheProviders      string
+	storageFormat       string
+	storagePreset       string
+	recorderID          string
+	commandPublisher    ports.EventPublisher
 	logger              *slog.Logger
+
+	liveMu sync.Mutex
+	live   map[string]*liveTranscript // partial transcripts by recording ID
+}
+
+// ServiceOption is a functional option for configuring the service
+type ServiceOption func(*Service)
+
+// WithProfiles sets the transcription profiles commands can select
+func WithProfiles(profiles *Profiles) ServiceOption {
+	return func(s *Service) {
+		s.profiles = profiles
+	}
+}
+
+// WithPreprocessor sets the audio pre-processor and the chain it runs when the
+// transcription profile does not choose one
+func WithPreprocessor(preprocessor ports.AudioPreprocessor, defaultChain []string) ServiceOption {
+	return func(s *Service) {
+		s.preprocessor = preprocessor
+		s.preprocessing = defaultChain
+	}
+}
+
+// WithLiveSegmentDuration sets how much audio each partial transcript of a live
+// transcription covers
+func WithLiveSegmentDuration(d time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.liveSegmentDuration = d
+	}
+}
+
+// WithRecorderID names the host whose microphone this service records, so clients
+// know where to send the commands of a recording
+func WithRecorderID(recorderID string) ServiceOption {
+	return func(s *Service) {
+		s.recorderID = recorderID
+	}
+}
+
+// WithRemoteTranscription hands transcriptions requested when a recording stops to the
+// transcription workers, publishing a transcription.run command instead of
+// transcribing in this process. Hosts that only record use it.
+func WithRemoteTranscription(commandPublisher ports.EventPublisher) ServiceOption {
+	return func(s *Service) {
+		s.commandPublisher = commandPublisher
+	}
+}
+
+// WithStorageTranscoding re-encodes audio into the given format and preset before it
+// is stored, so recordings sit in the object store in a compact codec
+func WithStorageTranscoding(transcoder ports.AudioTranscoder, format, preset string) ServiceOption {
+	return func(s *Service) {
+		s.transcoder = transcoder
+		s.storageFormat = format
+		s.storagePreset = preset
+	}
 }
 
 // NewService creates a new transcriber service
@@ -25,29 +101,50 @@ func NewService(
 	audioRecorder ports.AudioRecorder,
 	transcriptionSvc ports.TranscriptionService,
 	objectStore ports.ObjectStore,
+	sessionRegistry ports.SessionRegistry,
 	eventPublisher ports.EventPublisher,
 	logger *slog.Logger,
+	opts ...ServiceOption,
 ) *Service {
-	return &Service{
-		audioRecorder:    audioRecorder,
-		transcriptionSvc: transcriptionSvc,
-		objectStore:      objectStore,
-		eventPublisher:   eventPublisher,
-		logger:           logger,
+	service := &Service{
+		audioRecorder:       audioRecorder,
+		transcriptionSvc:    transcriptionSvc,
+		objectStore:         objectStore,
+		sessionRegistry:     sessionRegistry,
+		eventPublisher:      eventPublisher,
+		liveSegmentDuration: defaultLiveSegmentDuration,
+		logger:              logger,
+		live:                make(map[string]*liveTranscript),
+	}
+
+	for _, opt := range opts {
+		opt(service)
 	}
+
+	return service
 }
 
 // StartRecordingCommand represents the start recording command payload
 type StartRecordingCommand struct {
-	OutputFormat string                 `json:"output_format"`
-	Tags         []string               `json:"tags"`
-	Metadata     map[string]interface{} `json:"metadata"`
+	OutputFormat           string                 `json:"output_format"`
+	Preset                 string                 `json:"preset,omitempty"`
+	Language               string                 `json:"language,omitempty"`
+	Prompt                 string                 `json:"prompt,omitempty"`
+	Task                   string                 `json:"task,omitempty"`
+	LiveTranscription      bool                   `json:"live_transcription,omitempty"`
+	AutoStopSilenceSeconds float64                `json:"auto_stop_silence_seconds,omitempty"`
+	MaxDurationSeconds     float64                `json:"max_duration_seconds,omitempty"`
+	MaxBytes               int64                  `json:"max_bytes,omitempty"`
+	TranscribeOnStop       bool                   `json:"transcribe_on_stop,omitempty"`
+	Tags                   []string               `json:"tags"`
+	Metadata               map[string]interface{} `json:"metadata"`
 }
 
 // StopRecordingCommand represents the stop recording command payload
 type StopRecordingCommand struct {
 	RecordingID      string                 `json:"recording_id"`
 	TranscribeOnStop bool                   `json:"transcribe_on_stop"`
+	SubtitleFormats  []string               `json:"subtitle_formats,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata"`
 }
 
@@ -56,17 +153,42 @@ type CancelRecordingCommand struct {
 	RecordingID string `json:"recording_id"`
 }
 
+// PauseRecordingCommand represents the pause recording command payload
+type PauseRecordingCommand struct {
+	RecordingID string `json:"recording_id"`
+}
+
+// ResumeRecordingCommand represents the resume recording command payload
+type ResumeRecordingCommand struct {
+	RecordingID string `json:"recording_id"`
+}
+
 // TranscriptionCommand represents the transcription command payload
 type TranscriptionCommand struct {
-	RecordingID string                 `json:"recording_id,omitempty"`
-	AudioData   string                 `json:"audio_data,omitempty"`
-	Tags        []string               `json:"tags"`
-	Metadata    map[string]interface{} `json:"metadata"`
+	RecordingID     string                 `json:"recording_id,omitempty"`
+	AudioData       string                 `json:"audio_data,omitempty"`
+	SubtitleFormats []string               `json:"subtitle_formats,omitempty"`
+	Language        string                 `json:"language,omitempty"`
+	Prompt          string                 `json:"prompt,omitempty"`
+	Task            string                 `json:"task,omitempty"`
+	Tags            []string               `json:"tags"`
+	Metadata        map[string]interface{} `json:"metadata"`
+
+	// Set by transports that deliver a failed command again, not by clients.
+	// AudioID is the recording ID raw audio is stored under, the same on every
+	// delivery. DeferFailure leaves transcription.failed to the transport, which
+	// publishes it with PublishTranscriptionFailed once it gives up on the command.
+	AudioID      string `json:"-"`
+	DeferFailure bool   `json:"-"`
 }
 
-// StartRecording handles the start recording command
-func (s *Service) StartRecording(ctx context.Context, cmd StartRecordingCommand) error {
+// StartRecording handles the start recording command and returns the new recording ID.
+// The ID is also returned alongside an error if the recording started but its
+// recording.started event could not be published.
+func (s *Service) StartRecording(ctx context.Context, cmd StartRecordingCommand) (string, error) {
 	recordingID := uuid.New().String()
+	ctx, span := startSpan(ctx, "Service.StartRecording", recordingID)
+	defer span.End()
 	
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
@@ -77,33 +199,109 @@ func (s *Service) StartRecording(ctx context.Context, cmd StartRecordingCommand)
 
 	logger.Info("Starting recording", "format", cmd.OutputFormat, "tags", cmd.Tags)
 
-	err := s.audioRecorder.StartRecording(ctx, recordingID, cmd.OutputFormat)
+	// Transcription hints are kept with the session, so reject bad ones before recording
+	opts, err := normalizeTranscriptionOptions(cmd.Language, cmd.Prompt, cmd.Task)
+	if err != nil {
+		logger.Error("Invalid transcription options", "error", err)
+		return "", err
+	}
+
+	if cmd.AutoStopSilenceSeconds < 0 {
+		logger.Error("Invalid auto-stop silence", "auto_stop_silence_seconds", cmd.AutoStopSilenceSeconds)
+		return "", ErrInvalidAutoStop
+	}
+
+	if cmd.MaxDurationSeconds < 0 || cmd.MaxBytes < 0 {
+		logger.Error("Invalid recording limits", "max_duration_seconds", cmd.MaxDurationSeconds, "max_bytes", cmd.MaxBytes)
+		return "", ErrInvalidRecordingLimit
+	}
+
+	// Live segments are transcribed while recording, each published as a partial transcript
+	var recordingOpts ports.RecordingOptions
+	if cmd.LiveTranscription {
+		if s.transcriptionSvc == nil {
+			logger.Error("Live transcription requested without a transcription provider")
+			return "", ErrLiveTranscriptionUnavailable
+		}
+		profile, err := s.selectProfile(cmd.Tags, cmd.Metadata)
+		if err != nil {
+			logger.Error("Failed to select transcription profile", "error", err)
+			return "", err
+		}
+		recordingOpts = s.startLiveTranscript(ctx, logger, recordingID, cmd, opts, profile)
+	}
+	recordingOpts = s.autoStopOptions(ctx, recordingID, cmd, recordingOpts)
+	recordingOpts.Preset = cmd.Preset
+
+	recorderCtx, recorderSpan := tracer.Start(ctx, "AudioRecorder.StartRecording")
+	err = s.audioRecorder.StartRecording(recorderCtx, recordingID, cmd.OutputFormat, recordingOpts)
+	endSpan(recorderSpan, err)
 	if err != nil {
 		logger.Error("Failed to start recording", "error", err)
-		return fmt.Errorf("failed to start recording: %w", err)
+		s.dropLiveTranscript(recordingID)
+		return "", fmt.Errorf("failed to start recording: %w", err)
+	}
+	activeRecordings.Inc()
+
+	// Remember the start command so later events can carry its tags and metadata
+	session := ports.RecordingSession{
+		RecordingID: recordingID,
+		Format:      cmd.OutputFormat,
+		Tags:        cmd.Tags,
+		Metadata:    cmd.Metadata,
+		Caller:      callerFromMetadata(cmd.Metadata),
+		Language:    opts.Language,
+		Prompt:      opts.Prompt,
+		Task:        opts.Task,
+		StartedAt:   time.Now().UTC(),
+	}
+	if err := s.sessionRegistry.SaveSession(ctx, session); err != nil {
+		logger.Error("Failed to register recording session", "error", err)
+		cancelErr := s.audioRecorder.CancelRecording(ctx, recordingID)
+		if recordingDropped(cancelErr) {
+			activeRecordings.Dec()
+		}
+		if cancelErr != nil {
+			logger.Warn("Failed to cancel unregistered recording", "error", cancelErr)
+		}
+		s.dropLiveTranscript(recordingID)
+		return "", fmt.Errorf("failed to register recording session: %w", err)
 	}
 
 	// Publish recording started event
+	data := map[string]interface{}{
+		"recording_id": recordingID,
+		"tags":         cmd.Tags,
+		"metadata":     cmd.Metadata,
+	}
+	if s.recorderID != "" {
+		data["recorder_id"] = s.recorderID
+	}
 	event := ports.Event{
 		Subject: "speakr.event.recording.started",
-		Data: map[string]interface{}{
-			"recording_id": recordingID,
-			"tags":         cmd.Tags,
-			"metadata":     cmd.Metadata,
-		},
+		Data:    data,
 	}
 
 	if err := s.eventPublisher.PublishEvent(ctx, event); err != nil {
 		logger.Error("Failed to publish recording started event", "error", err)
-		return fmt.Errorf("failed to publish recording started event: %w", err)
+		return recordingID, fmt.Errorf("failed to publish recording started event: %w", err)
 	}
 
 	logger.Info("Recording started successfully")
-	return nil
+	return recordingID, nil
 }
 
 // StopRecording handles the stop recording command
 func (s *Service) StopRecording(ctx context.Context, cmd StopRecordingCommand) error {
+	return s.stopRecording(ctx, cmd, StopReasonManual)
+}
+
+// stopRecording stops a recording, stores its audio and publishes recording.finished
+// with the reason it stopped
+func (s *Service) stopRecording(ctx context.Context, cmd StopRecordingCommand, reason string) error {
+	ctx, span := startSpan(ctx, "Service.StopRecording", cmd.RecordingID)
+	defer span.End()
+
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
 		"correlation_id", correlationID,
@@ -111,20 +309,76 @@ func (s *Service) StopRecording(ctx context.Context, cmd StopRecordingCommand) e
 		"operation", "stop_recording",
 	)
 
-	logger.Info("Stopping recording", "transcribe_on_stop", cmd.TranscribeOnStop)
+	logger.Info("Stopping recording", "transcribe_on_stop", cmd.TranscribeOnStop, "stop_reason", reason)
+
+	// Reject unknown subtitle formats while the recording can still be stopped again
+	subtitleFormats, err := normalizeSubtitleFormats(cmd.SubtitleFormats)
+	if err != nil {
+		logger.Error("Invalid subtitle formats", "subtitle_formats", cmd.SubtitleFormats, "error", err)
+		return err
+	}
 
-	audioData, err := s.audioRecorder.StopRecording(ctx, cmd.RecordingID)
+	recorderCtx, recorderSpan := tracer.Start(ctx, "AudioRecorder.StopRecording")
+	audioData, err := s.audioRecorder.StopRecording(recorderCtx, cmd.RecordingID)
+	endSpan(recorderSpan, err)
+	if recordingDropped(err) {
+		activeRecordings.Dec()
+	}
 	if err != nil {
 		logger.Error("Failed to stop recording", "error", err)
 		return fmt.Errorf("failed to stop recording: %w", err)
 	}
+	s.stopLiveTranscript(cmd.RecordingID)
+
+	// Carry the tags and metadata from the start command into every later event
+	session := s.lookupSession(ctx, logger, cmd.RecordingID)
+	if session != nil {
+		recordingDuration.WithLabelValues(reason).Observe(time.Since(session.StartedAt).Seconds())
+	}
+
+	audioData, format, err := sniffAudioFormat(audioData)
+	if err != nil {
+		logger.Error("Failed to read recorded audio", "error", err)
+		return fmt.Errorf("failed to read recorded audio: %w", err)
+	}
+	if format == "" {
+		format = AudioFormatWAV
+		if session != nil && session.Format != "" {
+			format = session.Format
+		}
+		logger.Warn("Could not detect recorded audio format, using requested format", "format", format)
+	}
+
+	audioData, format, err = s.encodeForStorage(ctx, logger, audioData, format)
+	if err != nil {
+		logger.Error("Failed to read recorded audio", "error", err)
+		return fmt.Errorf("failed to read recorded audio: %w", err)
+	}
 
 	// Store audio file
-	audioFilePath, err := s.objectStore.StoreAudio(ctx, cmd.RecordingID, audioData)
+	storeCtx, storeSpan := tracer.Start(ctx, "ObjectStore.StoreAudio")
+	stored := &countingReader{reader: audioData}
+	audioFilePath, err := s.objectStore.StoreAudio(storeCtx, cmd.RecordingID, stored, format)
+	endSpan(storeSpan, err)
 	if err != nil {
 		logger.Error("Failed to store audio file", "error", err)
 		return fmt.Errorf("failed to store audio file: %w", err)
 	}
+	audioBytesStored.WithLabelValues(audioSourceRecording).Add(float64(stored.n))
+
+	tags := mergeTags(nil)
+	metadata := cmd.Metadata
+	if session != nil {
+		tags = mergeTags(session.Tags)
+		metadata = mergeMetadata(session.Metadata, cmd.Metadata)
+
+		stoppedAt := time.Now().UTC()
+		session.StoppedAt = &stoppedAt
+		session.Format = format
+		if err := s.sessionRegistry.SaveSession(ctx, *session); err != nil {
+			logger.Warn("Failed to mark recording session as stopped", "error", err)
+		}
+	}
 
 	// Publish recording finished event
 	event := ports.Event{
@@ -132,8 +386,10 @@ func (s *Service) StopRecording(ctx context.Context, cmd StopRecordingCommand) e
 		Data: map[string]interface{}{
 			"recording_id":    cmd.RecordingID,
 			"audio_file_path": audioFilePath,
-			"tags":            []string{}, // Will be populated from original command
-			"metadata":        cmd.Metadata,
+			"format":          format,
+			"stop_reason":     reason,
+			"tags":            tags,
+			"metadata":        metadata,
 		},
 	}
 
@@ -145,11 +401,25 @@ func (s *Service) StopRecording(ctx context.Context, cmd StopRecordingCommand) e
 	// If transcribe_on_stop is true, trigger transcription
 	if cmd.TranscribeOnStop {
 		transcribeCmd := TranscriptionCommand{
-			RecordingID: cmd.RecordingID,
-			Tags:        []string{}, // Will be populated from original command
-			Metadata:    cmd.Metadata,
+			RecordingID:     cmd.RecordingID,
+			SubtitleFormats: subtitleFormats,
+			Tags:            tags,
+			Metadata:        metadata,
 		}
-		if err := s.TranscribeAudio(ctx, transcribeCmd); err != nil {
+		// A worker on another host may not share the session registry, so the hints
+		// from the start command travel with the command
+		if session != nil {
+			transcribeCmd.Language = session.Language
+			transcribeCmd.Prompt = session.Prompt
+			transcribeCmd.Task = session.Task
+		}
+		if s.commandPublisher != nil {
+			command := ports.Event{Subject: "speakr.command.transcription.run", Data: transcribeCmd}
+			if err := s.commandPublisher.PublishEvent(ctx, command); err != nil {
+				logger.Error("Failed to request transcription after stop", "error", err)
+				return fmt.Errorf("failed to request transcription after stop: %w", err)
+			}
+		} else if _, err := s.TranscribeAudio(ctx, transcribeCmd); err != nil {
 			logger.Error("Failed to transcribe audio after stop", "error", err)
 			return fmt.Errorf("failed to transcribe audio after stop: %w", err)
 		}
@@ -161,6 +431,9 @@ func (s *Service) StopRecording(ctx context.Context, cmd StopRecordingCommand) e
 
 // CancelRecording handles the cancel recording command
 func (s *Service) CancelRecording(ctx context.Context, cmd CancelRecordingCommand) error {
+	ctx, span := startSpan(ctx, "Service.CancelRecording", cmd.RecordingID)
+	defer span.End()
+
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
 		"correlation_id", correlationID,
@@ -171,10 +444,18 @@ func (s *Service) CancelRecording(ctx context.Context, cmd CancelRecordingComman
 	logger.Info("Cancelling recording")
 
 	err := s.audioRecorder.CancelRecording(ctx, cmd.RecordingID)
+	if recordingDropped(err) {
+		activeRecordings.Dec()
+	}
 	if err != nil {
 		logger.Error("Failed to cancel recording", "error", err)
 		return fmt.Errorf("failed to cancel recording: %w", err)
 	}
+	s.dropLiveTranscript(cmd.RecordingID)
+
+	if err := s.sessionRegistry.DeleteSession(ctx, cmd.RecordingID); err != nil {
+		logger.Warn("Failed to delete recording session", "error", err)
+	}
 
 	// Publish recording cancelled event
 	event := ports.Event{
@@ -193,95 +474,527 @@ func (s *Service) CancelRecording(ctx context.Context, cmd CancelRecordingComman
 	return nil
 }
 
-// TranscribeAudio handles the transcription command
-func (s *Service) TranscribeAudio(ctx context.Context, cmd TranscriptionCommand) error {
+// PauseRecording handles the pause recording command
+func (s *Service) PauseRecording(ctx context.Context, cmd PauseRecordingCommand) error {
+	ctx, span := startSpan(ctx, "Service.PauseRecording", cmd.RecordingID)
+	defer span.End()
+
 	correlationID := s.getCorrelationID(ctx)
 	logger := s.logger.With(
 		"correlation_id", correlationID,
 		"recording_id", cmd.RecordingID,
-		"operation", "transcribe_audio",
+		"operation", "pause_recording",
 	)
 
-	logger.Info("Starting transcription")
+	logger.Info("Pausing recording")
 
-	var audioData io.Reader
-	var err error
+	if err := s.audioRecorder.PauseRecording(ctx, cmd.RecordingID); err != nil {
+		logger.Error("Failed to pause recording", "error", err)
+		return fmt.Errorf("failed to pause recording: %w", err)
+	}
 
-	if cmd.RecordingID != "" {
-		// Retrieve audio from object store
-		audioData, err = s.objectStore.RetrieveAudio(ctx, cmd.RecordingID)
+	if err := s.publishRecordingStateChange(ctx, logger, "speakr.event.recording.paused", cmd.RecordingID); err != nil {
+		return err
+	}
+
+	logger.Info("Recording paused successfully")
+	return nil
+}
+
+// ResumeRecording handles the resume recording command
+func (s *Service) ResumeRecording(ctx context.Context, cmd ResumeRecordingCommand) error {
+	ctx, span := startSpan(ctx, "Service.ResumeRecording", cmd.RecordingID)
+	defer span.End()
+
+	correlationID := s.getCorrelationID(ctx)
+	logger := s.logger.With(
+		"correlation_id", correlationID,
+		"recording_id", cmd.RecordingID,
+		"operation", "resume_recording",
+	)
+
+	logger.Info("Resuming recording")
+
+	if err := s.audioRecorder.ResumeRecording(ctx, cmd.RecordingID); err != nil {
+		logger.Error("Failed to resume recording", "error", err)
+		return fmt.Errorf("failed to resume recording: %w", err)
+	}
+
+	if err := s.publishRecordingStateChange(ctx, logger, "speakr.event.recording.resumed", cmd.RecordingID); err != nil {
+		return err
+	}
+
+	logger.Info("Recording resumed successfully")
+	return nil
+}
+
+// publishRecordingStateChange publishes a paused or resumed event carrying the session's tags
+func (s *Service) publishRecordingStateChange(ctx context.Context, logger *slog.Logger, subject, recordingID string) error {
+	var tags []string
+	if session := s.lookupSession(ctx, logger, recordingID); session != nil {
+		tags = session.Tags
+	}
+
+	event := ports.Event{
+		Subject: subject,
+		Data: map[string]interface{}{
+			"recording_id": recordingID,
+			"tags":         mergeTags(tags),
+		},
+	}
+
+	if err := s.eventPublisher.PublishEvent(ctx, event); err != nil {
+		logger.Error("Failed to publish event", "subject", subject, "error", err)
+		return fmt.Errorf("failed to publish %s event: %w", subject, err)
+	}
+
+	return nil
+}
+
+// TranscribeAudio handles the transcription command and returns the recording ID
+// used in its events, which is generated when raw audio data is supplied without
+// an AudioID
+func (s *Service) TranscribeAudio(ctx context.Context, cmd TranscriptionCommand) (string, error) {
+	ctx, span := startSpan(ctx, "Service.TranscribeAudio", cmd.RecordingID)
+	defer span.End()
+
+	// Reject unknown subtitle formats before any audio is stored or sent to the provider
+	subtitleFormats, err := normalizeSubtitleFormats(cmd.SubtitleFormats)
+	if err != nil {
+		s.logger.Error("Invalid subtitle formats", "subtitle_formats", cmd.SubtitleFormats, "error", err)
+		return cmd.RecordingID, err
+	}
+
+	opts, err := normalizeTranscriptionOptions(cmd.Language, cmd.Prompt, cmd.Task)
+	if err != nil {
+		s.logger.Error("Invalid transcription options", "error", err)
+		return cmd.RecordingID, err
+	}
+
+	// Raw audio is read before it gets an ID, so audio that cannot be stored leaves
+	// no recording ID behind; a failure names at most the ID the transport chose
+	rawAudio := cmd.RecordingID == "" && cmd.AudioData != ""
+	var rawBytes []byte
+	var rawFormat string
+	if rawAudio {
+		rawBytes, rawFormat, err = readRawAudio(cmd.AudioData)
 		if err != nil {
-			logger.Error("Failed to retrieve audio file", "error", err)
-			
-			// Publish transcription failed event
-			failEvent := ports.Event{
-				Subject: "speakr.event.transcription.failed",
-				Data: map[string]interface{}{
-					"recording_id": cmd.RecordingID,
-					"error":        "Failed to retrieve audio file",
-					"tags":         cmd.Tags,
-					"metadata":     cmd.Metadata,
-				},
-			}
-			s.eventPublisher.PublishEvent(ctx, failEvent)
-			
-			return fmt.Errorf("failed to retrieve audio file: %w", err)
+			logger := s.logger.With(
+				"correlation_id", s.getCorrelationID(ctx),
+				"audio_id", cmd.AudioID,
+				"operation", "transcribe_audio",
+			)
+			logger.Error("Failed to read audio data", "error", err)
+			cmd.RecordingID = cmd.AudioID
+			s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+			return cmd.RecordingID, err
+		}
+
+		// Readable raw audio has no recording yet, so it gets an ID up front and every
+		// resulting event can be linked back to the stored audio file
+		cmd.RecordingID = cmd.AudioID
+		if cmd.RecordingID == "" {
+			cmd.RecordingID = uuid.New().String()
 		}
-	} else if cmd.AudioData != "" {
-		// Handle base64 encoded audio data
-		// This would need proper base64 decoding implementation
-		logger.Error("Base64 audio data not yet implemented")
-		return fmt.Errorf("base64 audio data not yet implemented")
-	} else {
+		span.SetAttributes(attribute.String("recording_id", cmd.RecordingID))
+	}
+
+	correlationID := s.getCorrelationID(ctx)
+	logger := s.logger.With(
+		"correlation_id", correlationID,
+		"recording_id", cmd.RecordingID,
+		"operation", "transcribe_audio",
+	)
+
+	logger.Info("Starting transcription", "raw_audio", rawAudio)
+
+	if cmd.RecordingID == "" {
 		logger.Error("Neither recording_id nor audio_data provided")
-		return fmt.Errorf("neither recording_id nor audio_data provided")
+		return "", ErrMissingAudioSource
 	}
 
-	// Transcribe the audio
-	transcribedText, err := s.transcriptionSvc.TranscribeAudio(ctx, audioData, "wav")
+	if !rawAudio {
+		if session := s.lookupSession(ctx, logger, cmd.RecordingID); session != nil {
+			cmd.Tags = mergeTags(session.Tags, cmd.Tags)
+			cmd.Metadata = mergeMetadata(session.Metadata, cmd.Metadata)
+			opts = inheritTranscriptionOptions(opts, session)
+		}
+	}
+
+	// An unknown profile fails the command before raw audio is stored under its ID
+	profile, err := s.selectProfile(cmd.Tags, cmd.Metadata)
 	if err != nil {
-		logger.Error("Failed to transcribe audio", "error", err)
-		
-		// Publish transcription failed event
-		failEvent := ports.Event{
-			Subject: "speakr.event.transcription.failed",
-			Data: map[string]interface{}{
-				"recording_id": cmd.RecordingID,
-				"error":        err.Error(),
-				"tags":         cmd.Tags,
-				"metadata":     cmd.Metadata,
-			},
+		logger.Error("Failed to select transcription profile", "error", err)
+		s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+		return cmd.RecordingID, err
+	}
+
+	var audioData io.Reader
+	var format string
+
+	// The session keeps the command's own options, so later runs apply the profile afresh
+	if rawAudio {
+		audioData, format, err = s.storeRawAudio(ctx, logger, cmd, opts, rawBytes, rawFormat)
+		if err != nil {
+			s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+			return cmd.RecordingID, err
+		}
+	}
+
+	if profile != nil {
+		opts = profile.apply(opts)
+		logger = logger.With("profile", profile.Name)
+	}
+
+	preprocessing := s.preprocessingFor(profile)
+	logger = logger.With("task", taskOrDefault(opts.Task), "language_hint", opts.Language)
+
+	// A recording transcribed live already has its transcript in the partials
+	var result *ports.TranscriptionResult
+	if !rawAudio {
+		result = s.takeLiveResult(logger, cmd.RecordingID, opts, preprocessing)
+	}
+	fromPartials := result != nil
+	cached := false
+
+	if !fromPartials {
+		if !rawAudio {
+			audioData, format, err = s.retrieveStoredAudio(ctx, logger, cmd)
+			if err != nil {
+				return cmd.RecordingID, err
+			}
+		}
+
+		// Audio transcribed before with the same options is served from the cache
+		var cacheKey string
+		if s.cache != nil {
+			audioData, cacheKey, result, err = s.lookupTranscriptionCache(ctx, logger, audioData, opts, profile, preprocessing)
+			if err != nil {
+				s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+				return cmd.RecordingID, err
+			}
+		}
+		cached = result != nil
+
+		if !cached {
+			result, err = s.transcribeAudioData(ctx, logger, cmd, audioData, format, opts, preprocessing)
+			if err != nil {
+				return cmd.RecordingID, err
+			}
+			if cacheKey != "" {
+				s.saveTranscriptionCache(ctx, logger, cacheKey, result)
+			}
 		}
-		s.eventPublisher.PublishEvent(ctx, failEvent)
-		
-		return fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	if profile != nil {
+		profile.applyReplacements(result)
+	}
+
+	// Segments are always present so consumers can iterate without nil checks,
+	// even for models that return no timestamps
+	segments := result.Segments
+	if segments == nil {
+		segments = []ports.TranscriptSegment{}
 	}
 
 	// Publish transcription succeeded event
+	data := map[string]interface{}{
+		"recording_id":     cmd.RecordingID,
+		"transcribed_text": result.Text,
+		"segments":         segments,
+		"task":             taskOrDefault(opts.Task),
+		"tags":             cmd.Tags,
+		"metadata":         cmd.Metadata,
+	}
+
+	// Prefer the language the provider detected; models that report none
+	// fall back to the hint they were given. Providers may name the language,
+	// so it is published as an ISO-639-1 code like the hint.
+	language := normalizeLanguage(result.Language)
+	if language == "" {
+		language = opts.Language
+	}
+	if language != "" {
+		data["language"] = language
+	}
+	if result.Duration > 0 {
+		data["duration_seconds"] = result.Duration
+	}
+	if len(result.Words) > 0 {
+		data["words"] = result.Words
+	}
+	if result.Provider != "" {
+		data["provider"] = result.Provider
+	}
+	if profile != nil {
+		data["profile"] = profile.Name
+	}
+	if len(preprocessing) > 0 {
+		data["preprocessing"] = preprocessing
+	}
+	if fromPartials {
+		data["from_partials"] = true
+	}
+	if cached {
+		data["cached"] = true
+	}
+
+	if len(subtitleFormats) > 0 {
+		subtitleFiles, err := s.storeSubtitles(ctx, logger, cmd.RecordingID, subtitleFormats, segments)
+		if err != nil {
+			s.publishTranscriptionFailed(ctx, logger, cmd, "Failed to store subtitle file")
+			return cmd.RecordingID, err
+		}
+		if len(subtitleFiles) > 0 {
+			data["subtitle_files"] = subtitleFiles
+		}
+	}
+
 	event := ports.Event{
 		Subject: "speakr.event.transcription.succeeded",
-		Data: map[string]interface{}{
-			"recording_id":     cmd.RecordingID,
-			"transcribed_text": transcribedText,
-			"tags":             cmd.Tags,
-			"metadata":         cmd.Metadata,
-		},
+		Data:    data,
 	}
 
 	if err := s.eventPublisher.PublishEvent(ctx, event); err != nil {
 		logger.Error("Failed to publish transcription succeeded event", "error", err)
-		return fmt.Errorf("failed to publish transcription succeeded event: %w", err)
+		return cmd.RecordingID, fmt.Errorf("failed to publish transcription succeeded event: %w", err)
 	}
 
-	logger.Info("Transcription completed successfully", "text_length", len(transcribedText))
-	return nil
+	logger.Info("Transcription completed successfully",
+		"text_length", len(result.Text),
+		"segments", len(segments),
+		"from_partials", fromPartials,
+		"cached", cached)
+	return cmd.RecordingID, nil
 }
 
-func (s *Service) getCorrelationID(ctx context.Context) string {
-	if correlationID := ctx.Value("correlation_id"); correlationID != nil {
-		if id, ok := correlationID.(string); ok {
-			return id
+// transcribeAudioData pre-processes and transcribes audio, publishing a failure event
+// if either fails. Timings stay relative to the audio as given.
+func (s *Service) transcribeAudioData(ctx context.Context, logger *slog.Logger, cmd TranscriptionCommand, audioData io.Reader, format string, opts ports.TranscriptionOptions, preprocessing []string) (*ports.TranscriptionResult, error) {
+	audioData, format, trimmed, err := s.preprocessAudio(ctx, logger, audioData, format, preprocessing)
+	if err != nil {
+		s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+		return nil, err
+	}
+
+	// Transcribe the audio
+	providerCtx, providerSpan := tracer.Start(ctx, "TranscriptionService.TranscribeAudio")
+	result, err := s.transcriptionSvc.TranscribeAudio(providerCtx, audioData, format, opts)
+	endSpan(providerSpan, err)
+	if err != nil {
+		logger.Error("Failed to transcribe audio", "error", err, "format", format)
+		s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	// Keep timings relative to the stored audio
+	if trimmed > 0 {
+		offsetResult(result, trimmed.Seconds())
+	}
+
+	return result, nil
+}
+
+// selectProfile returns the transcription profile for a command's tags and metadata,
+// or nil if no profiles are configured or none applies
+func (s *Service) selectProfile(tags []string, metadata map[string]interface{}) (*TranscriptionProfile, error) {
+	if s.profiles == nil {
+		return nil, nil
+	}
+	return s.profiles.Select(tags, metadata)
+}
+
+// retrieveStoredAudio fetches a recording's audio from the object store, publishing
+// a failure event if it cannot be read
+func (s *Service) retrieveStoredAudio(ctx context.Context, logger *slog.Logger, cmd TranscriptionCommand) (io.Reader, string, error) {
+	storeCtx, storeSpan := tracer.Start(ctx, "ObjectStore.RetrieveAudio")
+	audioData, format, err := s.objectStore.RetrieveAudio(storeCtx, cmd.RecordingID)
+	endSpan(storeSpan, err)
+	if err != nil {
+		logger.Error("Failed to retrieve audio file", "error", err)
+		s.publishTranscriptionFailed(ctx, logger, cmd, "Failed to retrieve audio file")
+		return nil, "", fmt.Errorf("failed to retrieve audio file: %w", err)
+	}
+
+	// Audio stored before formats were recorded has to be sniffed
+	if format == "" {
+		audioData, format, err = sniffAudioFormat(audioData)
+		if err != nil {
+			logger.Error("Failed to read stored audio", "error", err)
+			s.publishTranscriptionFailed(ctx, logger, cmd, "Failed to read stored audio")
+			return nil, "", fmt.Errorf("failed to read stored audio: %w", err)
+		}
+		if format == "" {
+			format = AudioFormatWAV
+		}
+	}
+
+	return audioData, format, nil
+}
+
+// storeRawAudio stores the decoded audio of the command under its recording ID and
+// returns the audio and its format for transcription
+func (s *Service) storeRawAudio(ctx context.Context, logger *slog.Logger, cmd TranscriptionCommand, opts ports.TranscriptionOptions, audioBytes []byte, format string) (io.Reader, string, error) {
+	storedAudio, storedFormat, err := s.encodeForStorage(ctx, logger, bytes.NewReader(audioBytes), format)
+	if err != nil {
+		logger.Error("Failed to read audio data", "error", err)
+		return nil, "", err
+	}
+
+	storeCtx, storeSpan := tracer.Start(ctx, "ObjectStore.StoreAudio")
+	stored := &countingReader{reader: storedAudio}
+	audioFilePath, err := s.objectStore.StoreAudio(storeCtx, cmd.RecordingID, stored, storedFormat)
+	endSpan(storeSpan, err)
+	if err != nil {
+		logger.Error("Failed to store audio file", "error", err)
+		return nil, "", fmt.Errorf("failed to store audio file: %w", err)
+	}
+	audioBytesStored.WithLabelValues(audioSourceUpload).Add(float64(stored.n))
+
+	// Register the raw audio like a finished recording so later transcription
+	// runs for this ID still carry the original tags and metadata
+	now := time.Now().UTC()
+	session := ports.RecordingSession{
+		RecordingID: cmd.RecordingID,
+		Format:      storedFormat,
+		Tags:        cmd.Tags,
+		Metadata:    cmd.Metadata,
+		Caller:      callerFromMetadata(cmd.Metadata),
+		Language:    opts.Language,
+		Prompt:      opts.Prompt,
+		Task:        opts.Task,
+		StartedAt:   now,
+		StoppedAt:   &now,
+	}
+	if err := s.sessionRegistry.SaveSession(ctx, session); err != nil {
+		logger.Warn("Failed to register raw audio session", "error", err)
+	}
+
+	// The original audio is transcribed, not the stored copy
+	logger.Info("Raw audio stored", "audio_file_path", audioFilePath, "format", storedFormat, "size", len(audioBytes))
+	return bytes.NewReader(audioBytes), format, nil
+}
+
+// storeSubtitles renders the segments in each subtitle format and stores the files next to
+// the recording's audio, returning their paths keyed by format. Nothing is stored when the
+// provider returned no timed segments, since the cues would have no timings.
+func (s *Service) storeSubtitles(ctx context.Context, logger *slog.Logger, recordingID string, formats []string, segments []ports.TranscriptSegment) (map[string]string, error) {
+	if len(segments) == 0 {
+		logger.Warn("Transcription has no segments, skipping subtitle files", "subtitle_formats", formats)
+		return nil, nil
+	}
+
+	subtitleFiles := make(map[string]string, len(formats))
+	for _, format := range formats {
+		subtitle := renderSubtitles(format, segments)
+
+		filePath, err := s.objectStore.StoreSubtitle(ctx, recordingID, strings.NewReader(subtitle), format)
+		if err != nil {
+			logger.Error("Failed to store subtitle file", "subtitle_format", format, "error", err)
+			return nil, fmt.Errorf("failed to store %s subtitle file: %w", format, err)
 		}
+
+		subtitleFiles[format] = filePath
+	}
+
+	logger.Info("Subtitle files stored", "subtitle_files", subtitleFiles)
+	return subtitleFiles, nil
+}
+
+// PublishTranscriptionFailed publishes transcription.failed for a command whose
+// failure was deferred, once its transport gives up on it. Raw audio is reported
+// under its AudioID.
+func (s *Service) PublishTranscriptionFailed(ctx context.Context, cmd TranscriptionCommand, err error) {
+	if cmd.RecordingID == "" {
+		cmd.RecordingID = cmd.AudioID
+	}
+
+	logger := s.logger.With(
+		"correlation_id", s.getCorrelationID(ctx),
+		"recording_id", cmd.RecordingID,
+		"operation", "transcribe_audio",
+	)
+
+	// Carry the tags and metadata the failed attempts saw
+	if cmd.RecordingID != "" {
+		if session := s.lookupSession(ctx, logger, cmd.RecordingID); session != nil {
+			cmd.Tags = mergeTags(session.Tags, cmd.Tags)
+			cmd.Metadata = mergeMetadata(session.Metadata, cmd.Metadata)
+		}
+	}
+
+	cmd.DeferFailure = false
+	s.publishTranscriptionFailed(ctx, logger, cmd, err.Error())
+}
+
+// publishTranscriptionFailed publishes a transcription.failed event for the command,
+// unless its transport publishes it once it gives up
+func (s *Service) publishTranscriptionFailed(ctx context.Context, logger *slog.Logger, cmd TranscriptionCommand, reason string) {
+	if cmd.DeferFailure {
+		logger.Info("Transcription failed, leaving transcription.failed to the transport", "error", reason)
+		return
+	}
+
+	data := map[string]interface{}{
+		"error":    reason,
+		"tags":     cmd.Tags,
+		"metadata": cmd.Metadata,
+	}
+	// Raw audio that could not be read has no recording to name
+	if cmd.RecordingID != "" {
+		data["recording_id"] = cmd.RecordingID
+	}
+	failEvent := ports.Event{
+		Subject: "speakr.event.transcription.failed",
+		Data:    data,
+	}
+	if err := s.eventPublisher.PublishEvent(ctx, failEvent); err != nil {
+		logger.Error("Failed to publish transcription failed event", "error", err)
+	}
+}
+
+// readRawAudio decodes base64 audio and detects its format
+func readRawAudio(data string) ([]byte, string, error) {
+	audioBytes, err := decodeAudioData(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := DetectAudioFormat(audioBytes)
+	if format == "" {
+		return nil, "", ErrUnknownAudioFormat
+	}
+	return audioBytes, format, nil
+}
+
+// decodeAudioData decodes base64 audio, tolerating line breaks and missing padding
+func decodeAudioData(data string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, data)
+
+	audioBytes, err := base64.StdEncoding.DecodeString(cleaned)
+	if err != nil {
+		audioBytes, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(cleaned, "="))
+		if err != nil {
+			return nil, ErrInvalidAudioData
+		}
+	}
+
+	if len(audioBytes) == 0 {
+		return nil, ErrEmptyAudioData
+	}
+
+	return audioBytes, nil
+}
+
+func (s *Service) getCorrelationID(ctx context.Context) string {
+	if correlationID := ports.CorrelationID(ctx); correlationID != "" {
+		return correlationID
 	}
 	return uuid.New().String()
 }
\ No newline at end of file
@@ -2,25 +2,32 @@ package core
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"speakr/transcriber/internal/ports"
 )
 
 // Mock implementations for testing
 type mockAudioRecorder struct {
-	startRecordingFunc  func(ctx context.Context, recordingID string, format string) error
+	startRecordingFunc  func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error
 	stopRecordingFunc   func(ctx context.Context, recordingID string) (io.Reader, error)
 	cancelRecordingFunc func(ctx context.Context, recordingID string) error
+	listRecordingsFunc  func(ctx context.Context) ([]ports.RecordingStatus, error)
+	pauseRecordingFunc  func(ctx context.Context, recordingID string) error
+	resumeRecordingFunc func(ctx context.Context, recordingID string) error
 }
 
-func (m *mockAudioRecorder) StartRecording(ctx context.Context, recordingID string, format string) error {
+func (m *mockAudioRecorder) StartRecording(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
 	if m.startRecordingFunc != nil {
-		return m.startRecordingFunc(ctx, recordingID, format)
+		return m.startRecordingFunc(ctx, recordingID, format, opts)
 	}
 	return nil
 }
@@ -39,64 +46,122 @@ func (m *mockAudioRecorder) CancelRecording(ctx context.Context, recordingID str
 	return nil
 }
 
+func (m *mockAudioRecorder) PauseRecording(ctx context.Context, recordingID string) error {
+	if m.pauseRecordingFunc != nil {
+		return m.pauseRecordingFunc(ctx, recordingID)
+	}
+	return nil
+}
+
+func (m *mockAudioRecorder) ResumeRecording(ctx context.Context, recordingID string) error {
+	if m.resumeRecordingFunc != nil {
+		return m.resumeRecordingFunc(ctx, recordingID)
+	}
+	return nil
+}
+
+func (m *mockAudioRecorder) ListRecordings(ctx context.Context) ([]ports.RecordingStatus, error) {
+	if m.listRecordingsFunc != nil {
+		return m.listRecordingsFunc(ctx)
+	}
+	return []ports.RecordingStatus{}, nil
+}
+
 type mockTranscriptionService struct {
-	transcribeAudioFunc func(ctx context.Context, audioData io.Reader, format string) (string, error)
+	transcribeAudioFunc func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error)
 }
 
-func (m *mockTranscriptionService) TranscribeAudio(ctx context.Context, audioData io.Reader, format string) (string, error) {
+func (m *mockTranscriptionService) TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
 	if m.transcribeAudioFunc != nil {
-		return m.transcribeAudioFunc(ctx, audioData, format)
+		return m.transcribeAudioFunc(ctx, audioData, format, opts)
 	}
-	return "mock transcription", nil
+	return &ports.TranscriptionResult{Text: "mock transcription"}, nil
 }
 
 type mockObjectStore struct {
-	storeAudioFunc    func(ctx context.Context, recordingID string, audioData io.Reader) (string, error)
-	retrieveAudioFunc func(ctx context.Context, recordingID string) (io.Reader, error)
+	storeAudioFunc    func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error)
+	retrieveAudioFunc func(ctx context.Context, recordingID string) (io.Reader, string, error)
+	storeSubtitleFunc func(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error)
 }
 
-func (m *mockObjectStore) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader) (string, error) {
+func (m *mockObjectStore) StoreAudio(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
 	if m.storeAudioFunc != nil {
-		return m.storeAudioFunc(ctx, recordingID, audioData)
+		return m.storeAudioFunc(ctx, recordingID, audioData, format)
 	}
-	return "/mock/path/" + recordingID + ".wav", nil
+	return "/mock/path/" + recordingID + "." + format, nil
 }
 
-func (m *mockObjectStore) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, error) {
+func (m *mockObjectStore) RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, string, error) {
 	if m.retrieveAudioFunc != nil {
 		return m.retrieveAudioFunc(ctx, recordingID)
 	}
-	return strings.NewReader("mock audio data"), nil
+	return strings.NewReader("mock audio data"), "wav", nil
+}
+
+func (m *mockObjectStore) StoreSubtitle(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error) {
+	if m.storeSubtitleFunc != nil {
+		return m.storeSubtitleFunc(ctx, recordingID, subtitleData, format)
+	}
+	return "/mock/path/" + recordingID + "." + format, nil
+}
+
+type mockSessionRegistry struct {
+	sessions map[string]ports.RecordingSession
+}
+
+func (m *mockSessionRegistry) SaveSession(ctx context.Context, session ports.RecordingSession) error {
+	if m.sessions == nil {
+		m.sessions = make(map[string]ports.RecordingSession)
+	}
+	m.sessions[session.RecordingID] = session
+	return nil
+}
+
+func (m *mockSessionRegistry) GetSession(ctx context.Context, recordingID string) (*ports.RecordingSession, error) {
+	session, exists := m.sessions[recordingID]
+	if !exists {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (m *mockSessionRegistry) DeleteSession(ctx context.Context, recordingID string) error {
+	delete(m.sessions, recordingID)
+	return nil
 }
 
 type mockEventPublisher struct {
 	publishEventFunc func(ctx context.Context, event ports.Event) error
 	publishedEvents  []ports.Event
+	mu               sync.Mutex // partial transcripts are published concurrently
 }
 
 func (m *mockEventPublisher) PublishEvent(ctx context.Context, event ports.Event) error {
+	m.mu.Lock()
 	m.publishedEvents = append(m.publishedEvents, event)
+	m.mu.Unlock()
 	if m.publishEventFunc != nil {
 		return m.publishEventFunc(ctx, event)
 	}
 	return nil
 }
 
-func createTestService() (*Service, *mockAudioRecorder, *mockTranscriptionService, *mockObjectStore, *mockEventPublisher) {
+func createTestService() (*Service, *mockAudioRecorder, *mockTranscriptionService, *mockObjectStore, *mockSessionRegistry, *mockEventPublisher) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	
 	audioRecorder := &mockAudioRecorder{}
 	transcriptionSvc := &mockTranscriptionService{}
 	objectStore := &mockObjectStore{}
+	sessionRegistry := &mockSessionRegistry{}
 	eventPublisher := &mockEventPublisher{}
 	
-	service := NewService(audioRecorder, transcriptionSvc, objectStore, eventPublisher, logger)
+	service := NewService(audioRecorder, transcriptionSvc, objectStore, sessionRegistry, eventPublisher, logger)
 	
-	return service, audioRecorder, transcriptionSvc, objectStore, eventPublisher
+	return service, audioRecorder, transcriptionSvc, objectStore, sessionRegistry, eventPublisher
 }
 
 func TestService_StartRecording(t *testing.T) {
-	service, _, _, _, eventPublisher := createTestService()
+	service, _, _, _, _, eventPublisher := createTestService()
 	
 	ctx := context.Background()
 	cmd := StartRecordingCommand{
@@ -106,7 +171,7 @@ func TestService_StartRecording(t *testing.T) {
 	}
 	
 	// Test successful start recording
-	err := service.StartRecording(ctx, cmd)
+	_, err := service.StartRecording(ctx, cmd)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -122,8 +187,22 @@ func TestService_StartRecording(t *testing.T) {
 	}
 }
 
+func TestService_StartRecording_RecorderID(t *testing.T) {
+	service, _, _, _, _, eventPublisher := createTestService()
+	WithRecorderID("desk")(service)
+
+	if _, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if data["recorder_id"] != "desk" {
+		t.Errorf("Expected recorder_id 'desk', got %v", data["recorder_id"])
+	}
+}
+
 func TestService_StopRecording(t *testing.T) {
-	service, _, _, _, eventPublisher := createTestService()
+	service, _, _, _, _, eventPublisher := createTestService()
 	
 	ctx := context.Background()
 	cmd := StopRecordingCommand{
@@ -147,10 +226,14 @@ func TestService_StopRecording(t *testing.T) {
 	if event.Subject != "speakr.event.recording.finished" {
 		t.Errorf("Expected subject 'speakr.event.recording.finished', got %s", event.Subject)
 	}
+
+	if reason := event.Data.(map[string]interface{})["stop_reason"]; reason != StopReasonManual {
+		t.Errorf("Expected stop_reason %q, got %v", StopReasonManual, reason)
+	}
 }
 
 func TestService_CancelRecording(t *testing.T) {
-	service, _, _, _, eventPublisher := createTestService()
+	service, _, _, _, _, eventPublisher := createTestService()
 	
 	ctx := context.Background()
 	cmd := CancelRecordingCommand{
@@ -175,7 +258,7 @@ func TestService_CancelRecording(t *testing.T) {
 }
 
 func TestService_TranscribeAudio(t *testing.T) {
-	service, _, _, _, eventPublisher := createTestService()
+	service, _, _, _, _, eventPublisher := createTestService()
 	
 	ctx := context.Background()
 	cmd := TranscriptionCommand{
@@ -185,7 +268,7 @@ func TestService_TranscribeAudio(t *testing.T) {
 	}
 	
 	// Test successful transcription
-	err := service.TranscribeAudio(ctx, cmd)
+	_, err := service.TranscribeAudio(ctx, cmd)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -199,4 +282,847 @@ func TestService_TranscribeAudio(t *testing.T) {
 	if event.Subject != "speakr.event.transcription.succeeded" {
 		t.Errorf("Expected subject 'speakr.event.transcription.succeeded', got %s", event.Subject)
 	}
-}
\ No newline at end of file
+}
+
+func TestService_TranscribeAudio_RawAudioData(t *testing.T) {
+	service, _, transcriptionSvc, objectStore, _, eventPublisher := createTestService()
+
+	var storedID, storedData, storedFormat string
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		data, _ := io.ReadAll(audioData)
+		storedID = recordingID
+		storedData = string(data)
+		storedFormat = format
+		return "s3://speakr-audio/recordings/" + recordingID + "." + format, nil
+	}
+
+	var transcribedData, transcribedFormat string
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		data, _ := io.ReadAll(audioData)
+		transcribedData = string(data)
+		transcribedFormat = format
+		return &ports.TranscriptionResult{Text: "voicemail transcription"}, nil
+	}
+
+	ctx := context.Background()
+	cmd := TranscriptionCommand{
+		AudioData: base64.StdEncoding.EncodeToString([]byte("ID3 raw voicemail audio")),
+		Tags:      []string{"project-y", "voicemail"},
+		Metadata:  map[string]interface{}{"source": "twilio-integration"},
+	}
+
+	recordingID, err := service.TranscribeAudio(ctx, cmd)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if storedID == "" || storedID != recordingID {
+		t.Fatalf("Expected audio to be stored under the returned recording ID %q, got %q", recordingID, storedID)
+	}
+
+	if storedData != "ID3 raw voicemail audio" || transcribedData != "ID3 raw voicemail audio" {
+		t.Errorf("Expected decoded audio to be stored and transcribed, got stored=%q transcribed=%q", storedData, transcribedData)
+	}
+
+	if storedFormat != AudioFormatMP3 || transcribedFormat != AudioFormatMP3 {
+		t.Errorf("Expected detected format mp3, got stored=%q transcribed=%q", storedFormat, transcribedFormat)
+	}
+
+	if len(eventPublisher.publishedEvents) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(eventPublisher.publishedEvents))
+	}
+
+	event := eventPublisher.publishedEvents[0]
+	if event.Subject != "speakr.event.transcription.succeeded" {
+		t.Errorf("Expected subject 'speakr.event.transcription.succeeded', got %s", event.Subject)
+	}
+
+	data := event.Data.(map[string]interface{})
+	if data["recording_id"] != storedID {
+		t.Errorf("Expected event recording_id %s, got %v", storedID, data["recording_id"])
+	}
+}
+
+func TestService_TranscribeAudio_InvalidRawAudioData(t *testing.T) {
+	service, _, _, objectStore, _, eventPublisher := createTestService()
+
+	stored := false
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		stored = true
+		return "", nil
+	}
+
+	ctx := context.Background()
+	cmd := TranscriptionCommand{
+		AudioData: "not base64 at all!",
+		Tags:      []string{"voicemail"},
+	}
+
+	_, err := service.TranscribeAudio(ctx, cmd)
+	if !errors.Is(err, ErrInvalidAudioData) {
+		t.Fatalf("Expected ErrInvalidAudioData, got %v", err)
+	}
+
+	if stored {
+		t.Error("Expected invalid audio not to be stored")
+	}
+
+	if len(eventPublisher.publishedEvents) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(eventPublisher.publishedEvents))
+	}
+
+	event := eventPublisher.publishedEvents[0]
+	if event.Subject != "speakr.event.transcription.failed" {
+		t.Errorf("Expected subject 'speakr.event.transcription.failed', got %s", event.Subject)
+	}
+
+	data := event.Data.(map[string]interface{})
+	if id, ok := data["recording_id"]; ok {
+		t.Errorf("Expected no recording ID for audio that was never stored, got %v", id)
+	}
+
+	// A transport that chose the ID up front still gets it in the failure
+	cmd.AudioID = "raw-audio-id"
+	recordingID, _ := service.TranscribeAudio(ctx, cmd)
+	data = eventPublisher.publishedEvents[1].Data.(map[string]interface{})
+	if recordingID != "raw-audio-id" || data["recording_id"] != "raw-audio-id" {
+		t.Errorf("Expected the failure under the supplied audio ID, got %q and %v", recordingID, data["recording_id"])
+	}
+}
+
+func TestService_TranscribeAudio_MissingAudioSource(t *testing.T) {
+	service, _, _, _, _, _ := createTestService()
+
+	_, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{})
+	if err != ErrMissingAudioSource {
+		t.Errorf("Expected ErrMissingAudioSource, got %v", err)
+	}
+}
+
+func TestDecodeAudioData(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("audio bytes"))
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"padded", encoded, "audio bytes", nil},
+		{"unpadded", strings.TrimRight(encoded, "="), "audio bytes", nil},
+		{"line wrapped", encoded[:6] + "\n" + encoded[6:], "audio bytes", nil},
+		{"invalid", "%%%", "", ErrInvalidAudioData},
+		{"whitespace only", " \n ", "", ErrEmptyAudioData},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeAudioData(tc.input)
+			if err != tc.wantErr {
+				t.Fatalf("Expected error %v, got %v", tc.wantErr, err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestService_StartRecording_RegistersSession(t *testing.T) {
+	service, _, _, _, sessionRegistry, eventPublisher := createTestService()
+
+	cmd := StartRecordingCommand{
+		OutputFormat: "wav",
+		Tags:         []string{"project-x", "daily-standup"},
+		Metadata:     map[string]interface{}{"triggered_by": "cli-adapter"},
+	}
+
+	if _, err := service.StartRecording(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	recordingID := eventPublisher.publishedEvents[0].Data.(map[string]interface{})["recording_id"].(string)
+	session, _ := sessionRegistry.GetSession(context.Background(), recordingID)
+	if session == nil {
+		t.Fatal("Expected recording session to be registered")
+	}
+
+	if session.Format != "wav" || session.Caller != "cli-adapter" || session.StartedAt.IsZero() {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	if len(session.Tags) != 2 || session.Tags[0] != "project-x" {
+		t.Errorf("Expected start tags in session, got %v", session.Tags)
+	}
+}
+
+func TestService_StopRecording_CarriesSessionContext(t *testing.T) {
+	service, _, _, _, sessionRegistry, eventPublisher := createTestService()
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{
+		RecordingID: "test-recording-id",
+		Tags:        []string{"project-x", "daily-standup"},
+		Metadata:    map[string]interface{}{"triggered_by": "cli-adapter"},
+	})
+
+	cmd := StopRecordingCommand{
+		RecordingID:      "test-recording-id",
+		TranscribeOnStop: true,
+		Metadata:         map[string]interface{}{"copy_to_clipboard": true},
+	}
+
+	if err := service.StopRecording(ctx, cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(eventPublisher.publishedEvents) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(eventPublisher.publishedEvents))
+	}
+
+	for _, event := range eventPublisher.publishedEvents {
+		data := event.Data.(map[string]interface{})
+
+		tags := data["tags"].([]string)
+		if len(tags) != 2 || tags[0] != "project-x" || tags[1] != "daily-standup" {
+			t.Errorf("%s: expected start tags, got %v", event.Subject, tags)
+		}
+
+		metadata := data["metadata"].(map[string]interface{})
+		if metadata["triggered_by"] != "cli-adapter" || metadata["copy_to_clipboard"] != true {
+			t.Errorf("%s: expected merged metadata, got %v", event.Subject, metadata)
+		}
+	}
+
+	session, _ := sessionRegistry.GetSession(ctx, "test-recording-id")
+	if session == nil || session.StoppedAt == nil {
+		t.Error("Expected session to be marked as stopped")
+	}
+}
+
+func TestService_TranscribeAudio_MergesSessionTags(t *testing.T) {
+	service, _, _, _, sessionRegistry, eventPublisher := createTestService()
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{
+		RecordingID: "test-recording-id",
+		Tags:        []string{"project-x", "daily-standup"},
+	})
+
+	cmd := TranscriptionCommand{
+		RecordingID: "test-recording-id",
+		Tags:        []string{"daily-standup", "additional-tag"},
+	}
+
+	if _, err := service.TranscribeAudio(ctx, cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tags := eventPublisher.publishedEvents[0].Data.(map[string]interface{})["tags"].([]string)
+	expected := []string{"project-x", "daily-standup", "additional-tag"}
+	if strings.Join(tags, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected tags %v, got %v", expected, tags)
+	}
+}
+
+func TestService_CancelRecording_DeletesSession(t *testing.T) {
+	service, _, _, _, sessionRegistry, _ := createTestService()
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{RecordingID: "test-recording-id"})
+
+	if err := service.CancelRecording(ctx, CancelRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if session, _ := sessionRegistry.GetSession(ctx, "test-recording-id"); session != nil {
+		t.Error("Expected session to be deleted after cancel")
+	}
+}
+
+func TestService_ListRecordings_IncludesSessionTags(t *testing.T) {
+	service, recorder, _, _, sessionRegistry, _ := createTestService()
+
+	startedAt := time.Now().Add(-90 * time.Second)
+	recorder.listRecordingsFunc = func(ctx context.Context) ([]ports.RecordingStatus, error) {
+		return []ports.RecordingStatus{
+			{
+				RecordingID:  "test-recording-id",
+				Format:       "wav",
+				InputDevice:  "hw:1",
+				State:        ports.RecordingStateRecording,
+				StartedAt:    startedAt,
+				Duration:     90 * time.Second,
+				BytesWritten: 2048,
+			},
+			{RecordingID: "orphaned-recording-id", State: ports.RecordingStateRecording},
+		}, nil
+	}
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{
+		RecordingID: "test-recording-id",
+		Tags:        []string{"project-x"},
+		Caller:      "cli-adapter",
+	})
+
+	recordings, err := service.ListRecordings(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(recordings) != 2 {
+		t.Fatalf("Expected 2 recordings, got %d", len(recordings))
+	}
+
+	first := recordings[0]
+	if first.DurationSeconds != 90 || first.BytesWritten != 2048 || first.InputDevice != "hw:1" {
+		t.Errorf("Unexpected recording info: %+v", first)
+	}
+
+	if len(first.Tags) != 1 || first.Tags[0] != "project-x" || first.Caller != "cli-adapter" {
+		t.Errorf("Expected session tags and caller, got %+v", first)
+	}
+
+	// A recording without a registered session is still listed
+	if recordings[1].Tags == nil || len(recordings[1].Tags) != 0 {
+		t.Errorf("Expected empty tags for unregistered recording, got %v", recordings[1].Tags)
+	}
+}
+
+func TestService_RecordingStatus(t *testing.T) {
+	service, recorder, _, _, _, _ := createTestService()
+
+	recorder.listRecordingsFunc = func(ctx context.Context) ([]ports.RecordingStatus, error) {
+		return []ports.RecordingStatus{{RecordingID: "test-recording-id", State: ports.RecordingStateRecording}}, nil
+	}
+
+	ctx := context.Background()
+
+	info, err := service.RecordingStatus(ctx, RecordingStatusCommand{RecordingID: "test-recording-id"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if info.RecordingID != "test-recording-id" || info.State != "recording" {
+		t.Errorf("Unexpected recording info: %+v", info)
+	}
+
+	_, err = service.RecordingStatus(ctx, RecordingStatusCommand{RecordingID: "unknown"})
+	if !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("Expected ErrRecordingNotFound, got %v", err)
+	}
+}
+
+func TestService_PauseAndResumeRecording(t *testing.T) {
+	service, _, _, _, sessionRegistry, eventPublisher := createTestService()
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{
+		RecordingID: "test-recording-id",
+		Tags:        []string{"project-x"},
+	})
+
+	if err := service.PauseRecording(ctx, PauseRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error pausing, got %v", err)
+	}
+
+	if err := service.ResumeRecording(ctx, ResumeRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error resuming, got %v", err)
+	}
+
+	if len(eventPublisher.publishedEvents) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(eventPublisher.publishedEvents))
+	}
+
+	paused := eventPublisher.publishedEvents[0]
+	if paused.Subject != "speakr.event.recording.paused" {
+		t.Errorf("Expected paused event, got %s", paused.Subject)
+	}
+
+	tags := paused.Data.(map[string]interface{})["tags"].([]string)
+	if len(tags) != 1 || tags[0] != "project-x" {
+		t.Errorf("Expected session tags on paused event, got %v", tags)
+	}
+
+	if eventPublisher.publishedEvents[1].Subject != "speakr.event.recording.resumed" {
+		t.Errorf("Expected resumed event, got %s", eventPublisher.publishedEvents[1].Subject)
+	}
+}
+
+func TestService_PauseRecording_RecorderError(t *testing.T) {
+	service, recorder, _, _, _, eventPublisher := createTestService()
+
+	recorder.pauseRecordingFunc = func(ctx context.Context, recordingID string) error {
+		return errors.New("recording not found")
+	}
+
+	err := service.PauseRecording(context.Background(), PauseRecordingCommand{RecordingID: "unknown"})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if len(eventPublisher.publishedEvents) != 0 {
+		t.Errorf("Expected no events, got %d", len(eventPublisher.publishedEvents))
+	}
+}
+
+func TestService_TranscribeAudio_UnknownRawAudioFormat(t *testing.T) {
+	service, _, _, objectStore, _, eventPublisher := createTestService()
+
+	stored := false
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		stored = true
+		return "", nil
+	}
+
+	cmd := TranscriptionCommand{
+		AudioData: base64.StdEncoding.EncodeToString([]byte("definitely not audio")),
+	}
+
+	_, err := service.TranscribeAudio(context.Background(), cmd)
+	if !errors.Is(err, ErrUnknownAudioFormat) {
+		t.Fatalf("Expected ErrUnknownAudioFormat, got %v", err)
+	}
+
+	if stored {
+		t.Error("Expected audio of unknown format not to be stored")
+	}
+
+	if len(eventPublisher.publishedEvents) != 1 || eventPublisher.publishedEvents[0].Subject != "speakr.event.transcription.failed" {
+		t.Errorf("Expected a transcription.failed event, got %+v", eventPublisher.publishedEvents)
+	}
+}
+
+func TestService_StopRecording_StoresDetectedFormat(t *testing.T) {
+	service, recorder, _, objectStore, sessionRegistry, eventPublisher := createTestService()
+
+	recorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return strings.NewReader("ID3 recorded mp3 audio"), nil
+	}
+
+	var storedFormat, storedData string
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		data, _ := io.ReadAll(audioData)
+		storedData = string(data)
+		storedFormat = format
+		return "s3://speakr-audio/recordings/" + recordingID + "." + format, nil
+	}
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{RecordingID: "test-recording-id", Format: "wav"})
+
+	if err := service.StopRecording(ctx, StopRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if storedFormat != AudioFormatMP3 {
+		t.Errorf("Expected detected format mp3 over the requested wav, got %s", storedFormat)
+	}
+
+	if storedData != "ID3 recorded mp3 audio" {
+		t.Errorf("Expected the complete audio to be stored, got %q", storedData)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if data["format"] != AudioFormatMP3 {
+		t.Errorf("Expected recording.finished format mp3, got %v", data["format"])
+	}
+
+	if session, _ := sessionRegistry.GetSession(ctx, "test-recording-id"); session.Format != AudioFormatMP3 {
+		t.Errorf("Expected session format to be updated, got %s", session.Format)
+	}
+}
+
+func TestService_TranscribeAudio_UsesStoredFormat(t *testing.T) {
+	service, _, transcriptionSvc, objectStore, _, _ := createTestService()
+
+	objectStore.retrieveAudioFunc = func(ctx context.Context, recordingID string) (io.Reader, string, error) {
+		return strings.NewReader("audio"), AudioFormatFLAC, nil
+	}
+
+	var transcribedFormat string
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		transcribedFormat = format
+		return &ports.TranscriptionResult{Text: "text"}, nil
+	}
+
+	if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if transcribedFormat != AudioFormatFLAC {
+		t.Errorf("Expected stored format flac, got %s", transcribedFormat)
+	}
+}
+
+func TestService_TranscribeAudio_EventIncludesSegments(t *testing.T) {
+	service, _, transcriptionSvc, _, _, eventPublisher := createTestService()
+
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		return &ports.TranscriptionResult{
+			Text:     "Hello there. General Kenobi.",
+			Language: "english",
+			Duration: 3.5,
+			Segments: []ports.TranscriptSegment{
+				{ID: 0, Start: 0, End: 1.2, Text: "Hello there.", AvgLogprob: -0.2},
+				{ID: 1, Start: 1.2, End: 3.5, Text: "General Kenobi.", AvgLogprob: -0.3},
+			},
+			Words:    []ports.TranscriptWord{{Word: "Hello", Start: 0, End: 0.5}},
+			Provider: "groq",
+		}, nil
+	}
+
+	if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if data["transcribed_text"] != "Hello there. General Kenobi." {
+		t.Errorf("Expected transcribed_text to be kept, got %v", data["transcribed_text"])
+	}
+
+	segments, ok := data["segments"].([]ports.TranscriptSegment)
+	if !ok || len(segments) != 2 || segments[1].Start != 1.2 {
+		t.Errorf("Expected two timed segments, got %v", data["segments"])
+	}
+
+	if words, ok := data["words"].([]ports.TranscriptWord); !ok || len(words) != 1 {
+		t.Errorf("Expected word timings, got %v", data["words"])
+	}
+
+	if data["provider"] != "groq" {
+		t.Errorf("Expected the serving provider, got %v", data["provider"])
+	}
+}
+
+func TestService_TranscribeAudio_EventSegmentsNeverNil(t *testing.T) {
+	service, _, _, _, _, eventPublisher := createTestService()
+
+	if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if segments, ok := data["segments"].([]ports.TranscriptSegment); !ok || segments == nil {
+		t.Errorf("Expected an empty segment list, got %#v", data["segments"])
+	}
+
+	if _, ok := data["words"]; ok {
+		t.Error("Expected words to be omitted when the provider returns none")
+	}
+
+	if _, ok := data["provider"]; ok {
+		t.Error("Expected provider to be omitted when unknown")
+	}
+}
+
+func TestService_TranscribeAudio_StoresSubtitles(t *testing.T) {
+	service, _, transcriptionSvc, objectStore, _, eventPublisher := createTestService()
+
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		return &ports.TranscriptionResult{
+			Text:     "Welcome to the demo.",
+			Segments: []ports.TranscriptSegment{{ID: 0, Start: 0, End: 2.5, Text: "Welcome to the demo."}},
+		}, nil
+	}
+
+	stored := map[string]string{}
+	objectStore.storeSubtitleFunc = func(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error) {
+		data, _ := io.ReadAll(subtitleData)
+		stored[format] = string(data)
+		return "s3://speakr-audio/recordings/" + recordingID + "." + format, nil
+	}
+
+	cmd := TranscriptionCommand{RecordingID: "demo", SubtitleFormats: []string{"SRT", "vtt", "srt"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(stored) != 2 || !strings.HasPrefix(stored["vtt"], "WEBVTT") || !strings.HasPrefix(stored["srt"], "1\n") {
+		t.Errorf("Expected one SRT and one WebVTT file, got %v", stored)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	files, ok := data["subtitle_files"].(map[string]string)
+	if !ok || files["srt"] != "s3://speakr-audio/recordings/demo.srt" || files["vtt"] != "s3://speakr-audio/recordings/demo.vtt" {
+		t.Errorf("Expected subtitle file paths in the event, got %v", data["subtitle_files"])
+	}
+}
+
+func TestService_TranscribeAudio_UnsupportedSubtitleFormat(t *testing.T) {
+	service, _, transcriptionSvc, _, _, eventPublisher := createTestService()
+
+	called := false
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		called = true
+		return &ports.TranscriptionResult{Text: "text"}, nil
+	}
+
+	cmd := TranscriptionCommand{RecordingID: "demo", SubtitleFormats: []string{"ass"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); !errors.Is(err, ErrUnsupportedSubtitleFormat) {
+		t.Fatalf("Expected ErrUnsupportedSubtitleFormat, got %v", err)
+	}
+
+	if called || len(eventPublisher.publishedEvents) != 0 {
+		t.Error("Expected the command to be rejected before transcription")
+	}
+}
+
+func TestService_StopRecording_RemoteTranscription(t *testing.T) {
+	service, _, transcriptionSvc, _, sessionRegistry, eventPublisher := createTestService()
+
+	commands := &mockEventPublisher{}
+	WithRemoteTranscription(commands)(service)
+
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		t.Error("Expected no transcription on a host that only records")
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	sessionRegistry.SaveSession(ctx, ports.RecordingSession{
+		RecordingID: "test-recording-id",
+		Format:      "wav",
+		Tags:        []string{"standup"},
+		Language:    "pl",
+		Prompt:      "Daily standup",
+		Task:        ports.TaskTranslate,
+	})
+
+	cmd := StopRecordingCommand{RecordingID: "test-recording-id", TranscribeOnStop: true, SubtitleFormats: []string{"SRT"}}
+	if err := service.StopRecording(ctx, cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(eventPublisher.publishedEvents) != 1 || eventPublisher.publishedEvents[0].Subject != "speakr.event.recording.finished" {
+		t.Errorf("Expected only recording.finished, got %+v", eventPublisher.publishedEvents)
+	}
+
+	if len(commands.publishedEvents) != 1 || commands.publishedEvents[0].Subject != "speakr.command.transcription.run" {
+		t.Fatalf("Expected a transcription.run command, got %+v", commands.publishedEvents)
+	}
+	run := commands.publishedEvents[0].Data.(TranscriptionCommand)
+	if run.RecordingID != "test-recording-id" || len(run.SubtitleFormats) != 1 || run.SubtitleFormats[0] != SubtitleFormatSRT {
+		t.Errorf("Unexpected transcription command: %+v", run)
+	}
+
+	// The worker may not see the session, so the start command's hints are sent along
+	if run.Language != "pl" || run.Prompt != "Daily standup" || run.Task != ports.TaskTranslate {
+		t.Errorf("Expected the session's language, prompt and task, got %+v", run)
+	}
+	if len(run.Tags) != 1 || run.Tags[0] != "standup" {
+		t.Errorf("Expected the session's tags, got %v", run.Tags)
+	}
+}
+
+func TestService_StartRecording_LiveTranscriptionWithoutProvider(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewService(&mockAudioRecorder{}, nil, &mockObjectStore{}, &mockSessionRegistry{}, &mockEventPublisher{}, logger)
+
+	_, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav", LiveTranscription: true})
+	if !errors.Is(err, ErrLiveTranscriptionUnavailable) {
+		t.Fatalf("Expected ErrLiveTranscriptionUnavailable, got %v", err)
+	}
+}
+
+func TestService_StopRecording_UnsupportedSubtitleFormat(t *testing.T) {
+	service, recorder, _, _, _, eventPublisher := createTestService()
+
+	stopped := false
+	recorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		stopped = true
+		return strings.NewReader("mock audio data"), nil
+	}
+
+	cmd := StopRecordingCommand{RecordingID: "test-recording-id", TranscribeOnStop: true, SubtitleFormats: []string{"ass"}}
+	if err := service.StopRecording(context.Background(), cmd); !errors.Is(err, ErrUnsupportedSubtitleFormat) {
+		t.Fatalf("Expected ErrUnsupportedSubtitleFormat, got %v", err)
+	}
+
+	if stopped || len(eventPublisher.publishedEvents) != 0 {
+		t.Error("Expected the command to be rejected before the recording is stopped")
+	}
+}
+
+func TestService_TranscribeAudio_SubtitlesSkippedWithoutSegments(t *testing.T) {
+	service, _, _, objectStore, _, eventPublisher := createTestService()
+
+	objectStore.storeSubtitleFunc = func(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error) {
+		t.Error("Expected no subtitle file without segments")
+		return "", nil
+	}
+
+	cmd := TranscriptionCommand{RecordingID: "demo", SubtitleFormats: []string{"srt"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if _, ok := data["subtitle_files"]; ok {
+		t.Error("Expected no subtitle_files in the event")
+	}
+}
+
+func TestService_StartRecording_InvalidTask(t *testing.T) {
+	service, audioRecorder, _, _, _, _ := createTestService()
+
+	started := false
+	audioRecorder.startRecordingFunc = func(ctx context.Context, recordingID string, format string, opts ports.RecordingOptions) error {
+		started = true
+		return nil
+	}
+
+	_, err := service.StartRecording(context.Background(), StartRecordingCommand{OutputFormat: "wav", Task: "summarize"})
+	if !errors.Is(err, ErrInvalidTask) {
+		t.Fatalf("Expected ErrInvalidTask, got %v", err)
+	}
+
+	if started {
+		t.Error("Expected the recorder not to start")
+	}
+}
+
+func TestService_TranscribeAudio_InheritsSessionOptions(t *testing.T) {
+	service, _, transcriptionSvc, _, _, eventPublisher := createTestService()
+
+	var received ports.TranscriptionOptions
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		received = opts
+		return &ports.TranscriptionResult{Text: "Good morning, everyone."}, nil
+	}
+
+	ctx := context.Background()
+	recordingID, err := service.StartRecording(ctx, StartRecordingCommand{
+		OutputFormat: "wav",
+		Language:     "PL",
+		Prompt:       "Daily standup",
+		Task:         "Translate",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The command's own prompt wins over the one from the start command
+	if _, err := service.TranscribeAudio(ctx, TranscriptionCommand{RecordingID: recordingID, Prompt: "Sprint review"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := ports.TranscriptionOptions{Language: "pl", Prompt: "Sprint review", Task: ports.TaskTranslate}
+	if received != expected {
+		t.Errorf("Expected options %+v, got %+v", expected, received)
+	}
+
+	data := eventPublisher.publishedEvents[len(eventPublisher.publishedEvents)-1].Data.(map[string]interface{})
+	if data["task"] != ports.TaskTranslate {
+		t.Errorf("Expected task translate in the event, got %v", data["task"])
+	}
+
+	// The provider reported no language, so the hint is used
+	if data["language"] != "pl" {
+		t.Errorf("Expected language pl in the event, got %v", data["language"])
+	}
+}
+
+func TestService_TranscribeAudio_NormalizesDetectedLanguage(t *testing.T) {
+	service, _, transcriptionSvc, _, _, eventPublisher := createTestService()
+
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		return &ports.TranscriptionResult{Text: "Dzień dobry", Language: "Polish"}, nil
+	}
+
+	if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id", Language: "en"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The detected language wins over the hint and is published as its code
+	data := eventPublisher.publishedEvents[len(eventPublisher.publishedEvents)-1].Data.(map[string]interface{})
+	if data["language"] != "pl" {
+		t.Errorf("Expected language pl in the event, got %v", data["language"])
+	}
+}
+
+func TestService_TranscribeAudio_InvalidTask(t *testing.T) {
+	service, _, _, _, _, eventPublisher := createTestService()
+
+	_, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: "test-recording-id", Task: "dub"})
+	if !errors.Is(err, ErrInvalidTask) {
+		t.Fatalf("Expected ErrInvalidTask, got %v", err)
+	}
+
+	if len(eventPublisher.publishedEvents) != 0 {
+		t.Error("Expected no events for a rejected command")
+	}
+}
+
+func TestService_TranscribeAudio_AppliesProfile(t *testing.T) {
+	service, _, transcriptionSvc, _, _, eventPublisher := createTestService()
+
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Failed to parse profiles: %v", err)
+	}
+	WithProfiles(profiles)(service)
+
+	var received ports.TranscriptionOptions
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		received = opts
+		return &ports.TranscriptionResult{Text: "Restart nuts."}, nil
+	}
+
+	cmd := TranscriptionCommand{RecordingID: "test-recording-id", Tags: []string{"standup"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if received.Model != "whisper-1" || received.Language != "en" || received.Temperature != 0.2 {
+		t.Errorf("Expected the engineering profile's options, got %+v", received)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if data["profile"] != "engineering" || data["transcribed_text"] != "Restart NATS." {
+		t.Errorf("Expected the profile name and replaced text, got profile=%v text=%v", data["profile"], data["transcribed_text"])
+	}
+}
+
+func TestService_TranscribeAudio_UnknownProfile(t *testing.T) {
+	service, _, _, _, _, eventPublisher := createTestService()
+
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Failed to parse profiles: %v", err)
+	}
+	WithProfiles(profiles)(service)
+
+	cmd := TranscriptionCommand{RecordingID: "test-recording-id", Metadata: map[string]interface{}{"profile": "legal"}}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("Expected ErrUnknownProfile, got %v", err)
+	}
+
+	if len(eventPublisher.publishedEvents) != 1 || eventPublisher.publishedEvents[0].Subject != "speakr.event.transcription.failed" {
+		t.Errorf("Expected a transcription.failed event, got %+v", eventPublisher.publishedEvents)
+	}
+}
+
+func TestService_TranscribeAudio_UnknownProfileRawAudioNotStored(t *testing.T) {
+	service, _, _, objectStore, _, _ := createTestService()
+
+	profiles, err := ParseProfiles([]byte(testProfilesJSON))
+	if err != nil {
+		t.Fatalf("Failed to parse profiles: %v", err)
+	}
+	WithProfiles(profiles)(service)
+
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		t.Error("Expected no audio to be stored for an unknown profile")
+		return "", nil
+	}
+
+	cmd := TranscriptionCommand{
+		AudioData: base64.StdEncoding.EncodeToString([]byte("ID3 raw voicemail audio")),
+		Metadata:  map[string]interface{}{"profile": "legal"},
+	}
+	if _, err := service.TranscribeAudio(context.Background(), cmd); !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("Expected ErrUnknownProfile, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// lookupSession returns the registered session for a recording, or nil if it is
+// unknown or the registry fails. Events are still published without it.
+func (s *Service) lookupSession(ctx context.Context, logger *slog.Logger, recordingID string) *ports.RecordingSession {
+	session, err := s.sessionRegistry.GetSession(ctx, recordingID)
+	if err != nil {
+		logger.Warn("Failed to look up recording session", "error", err)
+		return nil
+	}
+	if session == nil {
+		logger.Debug("No recording session registered")
+	}
+	return session
+}
+
+// mergeTags concatenates tag lists in order, dropping duplicates. It never returns nil.
+func mergeTags(tagLists ...[]string) []string {
+	merged := []string{}
+	seen := make(map[string]bool)
+	for _, tags := range tagLists {
+		for _, tag := range tags {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// mergeMetadata returns a new map with the overrides applied on top of the base
+func mergeMetadata(base, overrides map[string]interface{}) map[string]interface{} {
+	if base == nil && overrides == nil {
+		return nil
+	}
+
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// callerFromMetadata identifies who started a recording from the contract's triggered_by field
+func callerFromMetadata(metadata map[string]interface{}) string {
+	if caller, ok := metadata["triggered_by"].(string); ok {
+		return caller
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecordingStatusCommand represents the recording status command payload
+type RecordingStatusCommand struct {
+	RecordingID string `json:"recording_id"`
+}
+
+// RecordingInfo describes an active recording, combining the recorder's progress
+// with the tags and metadata it was started with
+type RecordingInfo struct {
+	RecordingID     string                 `json:"recording_id"`
+	State           string                 `json:"state"`
+	Format          string                 `json:"format"`
+	InputDevice     string                 `json:"input_device"`
+	StartedAt       time.Time              `json:"started_at"`
+	DurationSeconds float64                `json:"duration_seconds"`
+	BytesWritten    int64                  `json:"bytes_written"`
+	Tags            []string               `json:"tags"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	Caller          string                 `json:"caller,omitempty"`
+	RecorderID      string                 `json:"recorder_id,omitempty"`
+}
+
+// RecordingStatus returns the status of a single active recording
+func (s *Service) RecordingStatus(ctx context.Context, cmd RecordingStatusCommand) (*RecordingInfo, error) {
+	correlationID := s.getCorrelationID(ctx)
+	logger := s.logger.With(
+		"correlation_id", correlationID,
+		"recording_id", cmd.RecordingID,
+		"operation", "recording_status",
+	)
+
+	recordings, err := s.ListRecordings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range recordings {
+		if recordings[i].RecordingID == cmd.RecordingID {
+			return &recordings[i], nil
+		}
+	}
+
+	logger.Info("Recording is not in progress")
+	return nil, ErrRecordingNotFound
+}
+
+// ListRecordings returns every active recording, oldest first. It never returns a nil slice.
+func (s *Service) ListRecordings(ctx context.Context) ([]RecordingInfo, error) {
+	correlationID := s.getCorrelationID(ctx)
+	logger := s.logger.With(
+		"correlation_id", correlationID,
+		"operation", "list_recordings",
+	)
+
+	statuses, err := s.audioRecorder.ListRecordings(ctx)
+	if err != nil {
+		logger.Error("Failed to list recordings", "error", err)
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	recordings := make([]RecordingInfo, 0, len(statuses))
+	for _, status := range statuses {
+		info := RecordingInfo{
+			RecordingID:     status.RecordingID,
+			State:           string(status.State),
+			Format:          status.Format,
+			InputDevice:     status.InputDevice,
+			StartedAt:       status.StartedAt.UTC(),
+			DurationSeconds: status.Duration.Seconds(),
+			BytesWritten:    status.BytesWritten,
+			Tags:            []string{},
+			RecorderID:      s.recorderID,
+		}
+
+		if session := s.lookupSession(ctx, logger.With("recording_id", status.RecordingID), status.RecordingID); session != nil {
+			info.Tags = mergeTags(session.Tags)
+			info.Metadata = session.Metadata
+			info.Caller = session.Caller
+		}
+
+		recordings = append(recordings, info)
+	}
+
+	logger.Debug("Listed recordings", "count", len(recordings))
+	return recordings, nil
+}
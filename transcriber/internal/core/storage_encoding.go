@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// storageContainers maps encodings to the format DetectAudioFormat reports for them
+var storageContainers = map[string]string{
+	"opus": AudioFormatOGG,
+}
+
+// storageContainer returns the format stored audio of an encoding is detected as
+func storageContainer(encoding string) string {
+	if container, ok := storageContainers[encoding]; ok {
+		return container
+	}
+	return encoding
+}
+
+// encodeForStorage re-encodes audio into the storage format when transcode-on-store is
+// configured, returning the audio to store and its format. Audio already in the storage
+// format is passed through, and audio that fails to transcode is stored as it is.
+func (s *Service) encodeForStorage(ctx context.Context, logger *slog.Logger, audioData io.Reader, format string) (io.Reader, string, error) {
+	if s.transcoder == nil || s.storageFormat == "" || storageContainer(s.storageFormat) == format {
+		return audioData, format, nil
+	}
+
+	// Kept so the original can be stored if transcoding fails
+	original, err := io.ReadAll(audioData)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	transcoded, err := s.transcoder.Transcode(ctx, bytes.NewReader(original), format, s.storageFormat, s.storagePreset)
+	if err != nil {
+		logger.Warn("Failed to transcode audio for storage, storing it as recorded", "error", err, "format", format, "storage_format", s.storageFormat)
+		return bytes.NewReader(original), format, nil
+	}
+
+	transcoded, storedFormat, err := sniffAudioFormat(transcoded)
+	if err != nil || storedFormat == "" {
+		logger.Warn("Transcoded audio is unreadable, storing it as recorded", "error", err, "format", format, "storage_format", s.storageFormat)
+		return bytes.NewReader(original), format, nil
+	}
+
+	logger.Info("Audio transcoded for storage", "format", format, "storage_format", storedFormat, "preset", s.storagePreset, "size", len(original))
+	return transcoded, storedFormat, nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type mockTranscoder struct {
+	calls        int
+	targetFormat string
+	preset       string
+	err          error
+}
+
+func (m *mockTranscoder) Transcode(ctx context.Context, audioData io.Reader, format, targetFormat, preset string) (io.Reader, error) {
+	m.calls++
+	m.targetFormat, m.preset = targetFormat, preset
+	if m.err != nil {
+		return nil, m.err
+	}
+	data, _ := io.ReadAll(audioData)
+	return strings.NewReader("OggS" + string(data)), nil
+}
+
+const testWAV = "RIFF\x24\x08\x00\x00WAVEfmt "
+
+func TestService_StopRecording_TranscodesForStorage(t *testing.T) {
+	service, audioRecorder, _, objectStore, _, eventPublisher := createTestService()
+
+	transcoder := &mockTranscoder{}
+	WithStorageTranscoding(transcoder, "opus", "speech")(service)
+
+	audioRecorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return strings.NewReader(testWAV), nil
+	}
+
+	var storedData, storedFormat string
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		data, _ := io.ReadAll(audioData)
+		storedData, storedFormat = string(data), format
+		return "s3://speakr-audio/recordings/" + recordingID + "." + format, nil
+	}
+
+	if err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if transcoder.targetFormat != "opus" || transcoder.preset != "speech" {
+		t.Errorf("Expected a speech opus transcode, got %s/%s", transcoder.targetFormat, transcoder.preset)
+	}
+	if storedData != "OggS"+testWAV || storedFormat != AudioFormatOGG {
+		t.Errorf("Expected the transcoded audio stored as ogg, got %q as %s", storedData, storedFormat)
+	}
+
+	data := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if data["format"] != AudioFormatOGG {
+		t.Errorf("Expected the stored format in the event, got %v", data["format"])
+	}
+}
+
+func TestService_StopRecording_StoresOriginalWhenTranscodingFails(t *testing.T) {
+	service, audioRecorder, _, objectStore, _, _ := createTestService()
+
+	WithStorageTranscoding(&mockTranscoder{err: errors.New("ffmpeg exited")}, "flac", "standard")(service)
+
+	audioRecorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return strings.NewReader(testWAV), nil
+	}
+
+	var storedData, storedFormat string
+	objectStore.storeAudioFunc = func(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error) {
+		data, _ := io.ReadAll(audioData)
+		storedData, storedFormat = string(data), format
+		return "", nil
+	}
+
+	if err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if storedData != testWAV || storedFormat != AudioFormatWAV {
+		t.Errorf("Expected the original audio stored, got %q as %s", storedData, storedFormat)
+	}
+}
+
+func TestService_StopRecording_SkipsTranscodeInStorageFormat(t *testing.T) {
+	service, audioRecorder, _, _, _, _ := createTestService()
+
+	transcoder := &mockTranscoder{}
+	WithStorageTranscoding(transcoder, "opus", "speech")(service)
+
+	audioRecorder.stopRecordingFunc = func(ctx context.Context, recordingID string) (io.Reader, error) {
+		return strings.NewReader("OggS already opus"), nil
+	}
+
+	if err := service.StopRecording(context.Background(), StopRecordingCommand{RecordingID: "test-recording-id"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if transcoder.calls != 0 {
+		t.Errorf("Expected audio already in the storage format not to be transcoded, got %d calls", transcoder.calls)
+	}
+}
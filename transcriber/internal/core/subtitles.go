@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// Subtitle formats that can be generated from transcript segments
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// normalizeSubtitleFormats lower-cases and de-duplicates the requested subtitle formats,
+// returning ErrUnsupportedSubtitleFormat for anything that cannot be generated
+func normalizeSubtitleFormats(formats []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(formats))
+
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "webvtt" {
+			format = SubtitleFormatVTT
+		}
+
+		if format != SubtitleFormatSRT && format != SubtitleFormatVTT {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedSubtitleFormat, format)
+		}
+
+		if !seen[format] {
+			seen[format] = true
+			normalized = append(normalized, format)
+		}
+	}
+
+	return normalized, nil
+}
+
+// renderSubtitles renders segments in a subtitle format returned by normalizeSubtitleFormats
+func renderSubtitles(format string, segments []ports.TranscriptSegment) string {
+	if format == SubtitleFormatVTT {
+		return RenderWebVTT(segments)
+	}
+	return RenderSRT(segments)
+}
+
+// RenderSRT renders transcript segments as a SubRip (.srt) subtitle file
+func RenderSRT(segments []ports.TranscriptSegment) string {
+	var b strings.Builder
+
+	cue := 0
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+
+		cue++
+		if cue > 1 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n",
+			cue,
+			formatSubtitleTimestamp(segment.Start, ","),
+			formatSubtitleTimestamp(segment.End, ","),
+			text)
+	}
+
+	return b.String()
+}
+
+// RenderWebVTT renders transcript segments as a WebVTT (.vtt) subtitle file
+func RenderWebVTT(segments []ports.TranscriptSegment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n",
+			formatSubtitleTimestamp(segment.Start, "."),
+			formatSubtitleTimestamp(segment.End, "."),
+			text)
+	}
+
+	return b.String()
+}
+
+// formatSubtitleTimestamp formats seconds as HH:MM:SS followed by the
+// millisecond separator (a comma for SRT, a period for WebVTT) and milliseconds
+func formatSubtitleTimestamp(seconds float64, separator string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	millis := int64(math.Round(seconds * 1000))
+	hours := millis / 3600000
+	minutes := millis / 60000 % 60
+	secs := millis / 1000 % 60
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, separator, millis%1000)
+}
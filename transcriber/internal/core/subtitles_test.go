@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+var subtitleSegments = []ports.TranscriptSegment{
+	{ID: 0, Start: 0, End: 2.5, Text: " Welcome to the demo."},
+	{ID: 1, Start: 2.5, End: 3.5, Text: "   "},
+	{ID: 2, Start: 3661.0004, End: 3663.25, Text: "Thanks for watching."},
+}
+
+func TestRenderSRT(t *testing.T) {
+	expected := "1\n" +
+		"00:00:00,000 --> 00:00:02,500\n" +
+		"Welcome to the demo.\n" +
+		"\n" +
+		"2\n" +
+		"01:01:01,000 --> 01:01:03,250\n" +
+		"Thanks for watching.\n"
+
+	if got := RenderSRT(subtitleSegments); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRenderWebVTT(t *testing.T) {
+	expected := "WEBVTT\n" +
+		"\n" +
+		"00:00:00.000 --> 00:00:02.500\n" +
+		"Welcome to the demo.\n" +
+		"\n" +
+		"01:01:01.000 --> 01:01:03.250\n" +
+		"Thanks for watching.\n"
+
+	if got := RenderWebVTT(subtitleSegments); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestNormalizeSubtitleFormats(t *testing.T) {
+	formats, err := normalizeSubtitleFormats([]string{"SRT", "webvtt", " srt "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(formats) != 2 || formats[0] != SubtitleFormatSRT || formats[1] != SubtitleFormatVTT {
+		t.Errorf("Expected [srt vtt], got %v", formats)
+	}
+
+	if _, err := normalizeSubtitleFormats([]string{"ass"}); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records the service's operations and the port calls they are waiting on.
+// Spans are dropped until the service installs a tracer provider.
+var tracer = otel.Tracer("speakr/transcriber/internal/core")
+
+// startSpan starts the span of an operation on a recording
+func startSpan(ctx context.Context, name, recordingID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attribute.String("recording_id", recordingID)))
+}
+
+// endSpan ends a span, marking it failed when its operation returned an error
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// WithTranscriptionCache reuses the transcripts of audio that was transcribed before
+// with the same model, profile and options instead of calling the provider again.
+// providers describes the configured provider chain and the models it uses when no
+// profile picks one, so changing them stops older transcripts from being reused.
+func WithTranscriptionCache(cache ports.TranscriptionCache, providers string) ServiceOption {
+	return func(s *Service) {
+		s.cache = cache
+		s.cacheProviders = providers
+	}
+}
+
+// transcriptionCacheKey identifies a transcript by the SHA-256 of the audio followed by
+// a hash of everything else that shapes it: providers, model, profile, hints and
+// pre-processing
+func transcriptionCacheKey(audio []byte, providers string, opts ports.TranscriptionOptions, profile *TranscriptionProfile, preprocessing []string) string {
+	profileName := ""
+	if profile != nil {
+		profileName = profile.Name
+	}
+
+	params := sha256.New()
+	fmt.Fprintf(params, "providers=%s\x00model=%s\x00profile=%s\x00language=%s\x00prompt=%s\x00task=%s\x00temperature=%g\x00preprocessing=%s",
+		providers, opts.Model, profileName, opts.Language, opts.Prompt, taskOrDefault(opts.Task), opts.Temperature, strings.Join(preprocessing, ","))
+
+	return fmt.Sprintf("%x.%x", sha256.Sum256(audio), params.Sum(nil)[:8])
+}
+
+// lookupTranscriptionCache hashes the audio and returns its cached transcript, or nil
+// on a miss, along with the audio to transcribe instead and the key to cache it under.
+// A cache that cannot be read counts as a miss.
+func (s *Service) lookupTranscriptionCache(ctx context.Context, logger *slog.Logger, audioData io.Reader, opts ports.TranscriptionOptions, profile *TranscriptionProfile, preprocessing []string) (io.Reader, string, *ports.TranscriptionResult, error) {
+	audio, err := io.ReadAll(audioData)
+	if err != nil {
+		logger.Error("Failed to read audio", "error", err)
+		return nil, "", nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	key := transcriptionCacheKey(audio, s.cacheProviders, opts, profile, preprocessing)
+
+	result, err := s.cache.GetTranscription(ctx, key)
+	if err != nil {
+		logger.Warn("Failed to read transcription cache, transcribing the audio", "error", err, "cache_key", key)
+		result = nil
+	}
+	if result != nil {
+		logger.Info("Reusing cached transcription", "cache_key", key)
+	}
+
+	return bytes.NewReader(audio), key, result, nil
+}
+
+// saveTranscriptionCache caches a transcript before profile replacements are applied.
+// Failures only cost a later provider call, so they are logged.
+func (s *Service) saveTranscriptionCache(ctx context.Context, logger *slog.Logger, key string, result *ports.TranscriptionResult) {
+	if err := s.cache.SaveTranscription(ctx, key, result); err != nil {
+		logger.Warn("Failed to cache transcription", "error", err, "cache_key", key)
+	}
+}
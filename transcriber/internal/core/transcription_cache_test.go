@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"speakr/transcriber/internal/ports"
+)
+
+type mockTranscriptionCache struct {
+	results map[string]ports.TranscriptionResult
+}
+
+func newMockTranscriptionCache() *mockTranscriptionCache {
+	return &mockTranscriptionCache{results: make(map[string]ports.TranscriptionResult)}
+}
+
+func (m *mockTranscriptionCache) GetTranscription(ctx context.Context, key string) (*ports.TranscriptionResult, error) {
+	result, ok := m.results[key]
+	if !ok {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+func (m *mockTranscriptionCache) SaveTranscription(ctx context.Context, key string, result *ports.TranscriptionResult) error {
+	m.results[key] = *result
+	return nil
+}
+
+func TestService_TranscribeAudio_ReusesCachedTranscription(t *testing.T) {
+	service, _, transcriptionSvc, objectStore, _, eventPublisher := createTestService()
+	WithTranscriptionCache(newMockTranscriptionCache(), "openai:whisper-1")(service)
+
+	objectStore.retrieveAudioFunc = func(ctx context.Context, recordingID string) (io.Reader, string, error) {
+		return strings.NewReader("same voicemail"), "wav", nil
+	}
+
+	calls := 0
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		calls++
+		data, _ := io.ReadAll(audioData)
+		if string(data) != "same voicemail" {
+			t.Errorf("Expected the full audio after hashing, got %q", data)
+		}
+		return &ports.TranscriptionResult{Text: "call me back", Provider: "openai"}, nil
+	}
+
+	for _, recordingID := range []string{"first-id", "second-id"} {
+		if _, err := service.TranscribeAudio(context.Background(), TranscriptionCommand{RecordingID: recordingID}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected the provider called once, got %d", calls)
+	}
+
+	first := eventPublisher.publishedEvents[0].Data.(map[string]interface{})
+	if _, ok := first["cached"]; ok {
+		t.Error("Expected cached to be omitted for a fresh transcription")
+	}
+
+	second := eventPublisher.publishedEvents[1].Data.(map[string]interface{})
+	if second["cached"] != true || second["transcribed_text"] != "call me back" || second["recording_id"] != "second-id" {
+		t.Errorf("Expected the cached transcript for the second recording, got %v", second)
+	}
+}
+
+func TestService_TranscribeAudio_CacheKeyedByOptions(t *testing.T) {
+	service, _, transcriptionSvc, _, _, _ := createTestService()
+	WithTranscriptionCache(newMockTranscriptionCache(), "openai:whisper-1")(service)
+
+	calls := 0
+	transcriptionSvc.transcribeAudioFunc = func(ctx context.Context, audioData io.Reader, format string, opts ports.TranscriptionOptions) (*ports.TranscriptionResult, error) {
+		calls++
+		return &ports.TranscriptionResult{Text: "hello"}, nil
+	}
+
+	for _, cmd := range []TranscriptionCommand{
+		{RecordingID: "test-recording-id"},
+		{RecordingID: "test-recording-id", Task: ports.TaskTranslate},
+		{RecordingID: "test-recording-id", Language: "pl"},
+	} {
+		if _, err := service.TranscribeAudio(context.Background(), cmd); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected each set of options transcribed separately, got %d provider calls", calls)
+	}
+}
+
+func TestTranscriptionCacheKey(t *testing.T) {
+	opts := ports.TranscriptionOptions{Model: "whisper-1"}
+	key := transcriptionCacheKey([]byte("audio"), "openai:whisper-1", opts, nil, nil)
+
+	if !strings.HasPrefix(key, "6ed8919ce20490a5e3ad8630a4fab69475297abd07db73918dd5f36fcfaeb11b.") || len(key) != 64+1+16 {
+		t.Errorf("Expected the audio SHA-256 followed by an options hash, got %q", key)
+	}
+
+	if key != transcriptionCacheKey([]byte("audio"), "openai:whisper-1", ports.TranscriptionOptions{Model: "whisper-1", Task: ports.TaskTranscribe}, nil, nil) {
+		t.Error("Expected the default task to share a key with an explicit transcribe")
+	}
+
+	profile := &TranscriptionProfile{Name: "engineering"}
+	if key == transcriptionCacheKey([]byte("audio"), "openai:whisper-1", opts, profile, nil) {
+		t.Error("Expected the profile to change the key")
+	}
+	if key == transcriptionCacheKey([]byte("audio"), "openai:whisper-1", ports.TranscriptionOptions{Model: "gpt-4o-transcribe"}, nil, nil) {
+		t.Error("Expected the model to change the key")
+	}
+}
+
+func TestTranscriptionCacheKey_ConfiguredModel(t *testing.T) {
+	// Without a profile the model is the one the provider is configured with
+	opts := ports.TranscriptionOptions{Language: "en"}
+	key := transcriptionCacheKey([]byte("audio"), "openai:whisper-1", opts, nil, nil)
+
+	if key == transcriptionCacheKey([]byte("audio"), "openai:gpt-4o-transcribe", opts, nil, nil) {
+		t.Error("Expected the configured model to change the key")
+	}
+	if key == transcriptionCacheKey([]byte("audio"), "openai:whisper-1,groq:whisper-large-v3", opts, nil, nil) {
+		t.Error("Expected the fallback providers to change the key")
+	}
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"speakr/transcriber/internal/ports"
+)
+
+// normalizeTranscriptionOptions validates the provider hints of a command, lower-casing
+// the language and task. An empty task means transcribe.
+func normalizeTranscriptionOptions(language, prompt, task string) (ports.TranscriptionOptions, error) {
+	opts := ports.TranscriptionOptions{
+		Language: strings.ToLower(strings.TrimSpace(language)),
+		Prompt:   strings.TrimSpace(prompt),
+		Task:     strings.ToLower(strings.TrimSpace(task)),
+	}
+
+	switch opts.Task {
+	case "", ports.TaskTranscribe, ports.TaskTranslate:
+	default:
+		return opts, fmt.Errorf("%w: %q", ErrInvalidTask, task)
+	}
+
+	return opts, nil
+}
+
+// inheritTranscriptionOptions fills hints missing from a transcription command
+// with the ones given when the recording was started
+func inheritTranscriptionOptions(opts ports.TranscriptionOptions, session *ports.RecordingSession) ports.TranscriptionOptions {
+	if opts.Language == "" {
+		opts.Language = session.Language
+	}
+	if opts.Prompt == "" {
+		opts.Prompt = session.Prompt
+	}
+	if opts.Task == "" {
+		opts.Task = session.Task
+	}
+	return opts
+}
+
+// taskOrDefault returns the task, defaulting to transcribe
+func taskOrDefault(task string) string {
+	if task == "" {
+		return ports.TaskTranscribe
+	}
+	return task
+}
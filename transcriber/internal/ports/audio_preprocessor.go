@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Pre-processing steps, applied in the order given
+const (
+	// PreprocessHighpass removes low-frequency rumble such as air conditioning and desk knocks
+	PreprocessHighpass = "highpass"
+	// PreprocessDenoise reduces steady background noise
+	PreprocessDenoise = "denoise"
+	// PreprocessLoudnorm normalizes loudness so quiet speakers are not lost
+	PreprocessLoudnorm = "loudnorm"
+	// PreprocessTrimSilence cuts silence from the start and end of the audio
+	PreprocessTrimSilence = "trim_silence"
+	// PreprocessResample converts the audio to 16 kHz mono, the input speech models expect
+	PreprocessResample = "resample"
+)
+
+// PreprocessedAudio is audio cleaned up for transcription
+type PreprocessedAudio struct {
+	Audio  io.Reader
+	Format string
+	// Trimmed is how much audio was cut from the start. Timings in a transcript of
+	// the processed audio are this much earlier than in the original.
+	Trimmed time.Duration
+}
+
+// AudioPreprocessor defines the interface for cleaning up audio before it is transcribed
+type AudioPreprocessor interface {
+	Preprocess(ctx context.Context, audioData io.Reader, format string, steps []string) (*PreprocessedAudio, error)
+}
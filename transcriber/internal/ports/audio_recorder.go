@@ -2,12 +2,102 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
-// AudioRecorder defines the interface for recording audio
+// Errors returned, possibly wrapped, by recorders for failures callers tell apart
+var (
+	ErrRecorderUnavailable    = errors.New("recorder unavailable")
+	ErrRecordingExists        = errors.New("recording already exists")
+	ErrRecordingNotFound      = errors.New("recording not found")
+	ErrRecordingFileNotFound  = errors.New("recording file not found")
+	ErrRecordingPaused        = errors.New("recording already paused")
+	ErrRecordingNotPaused     = errors.New("recording not paused")
+	ErrRecordingTimeExhausted = errors.New("recording time exhausted")
+	ErrInvalidRecordingFormat = errors.New("recording format not supported")
+	ErrInvalidPreset          = errors.New("encoding preset not supported")
+	ErrInsufficientDiskSpace  = errors.New("insufficient disk space")
+	ErrPermissionDenied       = errors.New("permission denied")
+	ErrDeviceNotFound         = errors.New("audio device not found")
+	ErrDeviceBusy             = errors.New("audio device busy")
+	ErrUnsupportedPlatform    = errors.New("platform not supported")
+)
+
+// RecordingState describes what an active recording is doing
+type RecordingState string
+
+const (
+	RecordingStateRecording RecordingState = "recording"
+	RecordingStatePaused    RecordingState = "paused"
+)
+
+// RecordingStatus is a snapshot of an active recording
+type RecordingStatus struct {
+	RecordingID  string
+	Format       string
+	InputDevice  string
+	State        RecordingState
+	StartedAt    time.Time
+	Duration     time.Duration
+	BytesWritten int64
+}
+
+// LiveSegment is a closed stretch of an in-progress recording, written alongside
+// the main recording so it can be transcribed before the recording stops
+type LiveSegment struct {
+	RecordingID string
+	// Sequence numbers segments from 0 in recording order
+	Sequence int
+	// Offset is where the segment starts in the recording, excluding pauses
+	Offset   time.Duration
+	Duration time.Duration
+	Format   string
+	Audio    []byte
+}
+
+// Encoding presets for recorded and stored audio
+const (
+	// RecordingPresetStandard keeps the recorder's sample rate and channels at music-grade bitrates
+	RecordingPresetStandard = "standard"
+	// RecordingPresetSpeech encodes 16 kHz mono at low bitrates, e.g. Opus at 24 kbps
+	RecordingPresetSpeech = "speech"
+)
+
+// RecordingOptions configures a single recording
+type RecordingOptions struct {
+	// Preset selects how the recording's format is encoded; empty means standard
+	Preset string
+	// LiveSegmentDuration, when positive, makes the recorder also write rolling
+	// segments of this length and pass each one to OnLiveSegment once it is closed
+	LiveSegmentDuration time.Duration
+	// OnLiveSegment is called from a recorder goroutine and must not block for long.
+	// Every segment has been delivered by the time StopRecording returns.
+	OnLiveSegment func(segment LiveSegment)
+	// SilenceTimeout, when positive, makes the recorder call OnSilence once the input
+	// has been silent this long. The recorder keeps recording; stopping is up to the caller.
+	SilenceTimeout time.Duration
+	// OnSilence is called from a recorder goroutine and must not block; StopRecording
+	// cannot complete until it returns
+	OnSilence func()
+	// MaxDuration and MaxBytes, when positive, limit the recording's length (excluding
+	// pauses) and size. The recorder's own maximum duration applies regardless.
+	MaxDuration time.Duration
+	MaxBytes    int64
+	// OnLimit is called once, from a recorder goroutine, when a limit is reached. It must
+	// not block. Without it, a recording at the recorder's maximum duration just goes quiet.
+	OnLimit func()
+}
+
+// AudioRecorder defines the interface for recording audio. StopRecording and
+// CancelRecording drop the recording even when they fail, unless they fail with
+// ErrRecordingNotFound.
 type AudioRecorder interface {
-	StartRecording(ctx context.Context, recordingID string, format string) error
+	StartRecording(ctx context.Context, recordingID string, format string, opts RecordingOptions) error
 	StopRecording(ctx context.Context, recordingID string) (io.Reader, error)
 	CancelRecording(ctx context.Context, recordingID string) error
-}
\ No newline at end of file
+	PauseRecording(ctx context.Context, recordingID string) error
+	ResumeRecording(ctx context.Context, recordingID string) error
+	ListRecordings(ctx context.Context) ([]RecordingStatus, error)
+}
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// AudioTranscoder defines the interface for re-encoding audio, e.g. into a compact
+// codec before it is stored
+type AudioTranscoder interface {
+	Transcode(ctx context.Context, audioData io.Reader, format, targetFormat, preset string) (io.Reader, error)
+}
@@ -2,11 +2,24 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"io"
 )
 
-// ObjectStore defines the interface for storing and retrieving audio files
+// Errors returned, possibly wrapped, by object stores for failures callers tell apart
+var (
+	ErrStorageUnavailable  = errors.New("object storage unavailable")
+	ErrStorageAccessDenied = errors.New("object storage access denied")
+	ErrInsufficientStorage = errors.New("insufficient object storage")
+	ErrObjectNotFound      = errors.New("object not found")
+)
+
+// ObjectStore defines the interface for storing and retrieving audio files and their subtitles
 type ObjectStore interface {
-	StoreAudio(ctx context.Context, recordingID string, audioData io.Reader) (string, error)
-	RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, error)
-}
\ No newline at end of file
+	// StoreAudio stores audio in the given format, such as "wav" or "mp3"
+	StoreAudio(ctx context.Context, recordingID string, audioData io.Reader, format string) (string, error)
+	// RetrieveAudio returns the stored audio and the format it was stored with
+	RetrieveAudio(ctx context.Context, recordingID string) (io.Reader, string, error)
+	// StoreSubtitle stores a subtitle file, such as "srt" or "vtt", next to the recording's audio
+	StoreSubtitle(ctx context.Context, recordingID string, subtitleData io.Reader, format string) (string, error)
+}
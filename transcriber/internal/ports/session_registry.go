@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RecordingSession captures the start command of a recording so later events can carry its context
+type RecordingSession struct {
+	RecordingID string                 `json:"recording_id"`
+	Format      string                 `json:"format"`
+	Tags        []string               `json:"tags"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Caller      string                 `json:"caller,omitempty"`
+	Language    string                 `json:"language,omitempty"`
+	Prompt      string                 `json:"prompt,omitempty"`
+	Task        string                 `json:"task,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	StoppedAt   *time.Time             `json:"stopped_at,omitempty"`
+}
+
+// SessionRegistry defines the interface for tracking recording sessions.
+// GetSession returns nil without an error when the session is unknown.
+type SessionRegistry interface {
+	SaveSession(ctx context.Context, session RecordingSession) error
+	GetSession(ctx context.Context, recordingID string) (*RecordingSession, error)
+	DeleteSession(ctx context.Context, recordingID string) error
+}
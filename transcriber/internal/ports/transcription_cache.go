@@ -0,0 +1,11 @@
+package ports
+
+import "context"
+
+// TranscriptionCache defines the interface for reusing the transcripts of audio that was
+// transcribed before with the same options. GetTranscription returns nil without an
+// error on a miss.
+type TranscriptionCache interface {
+	GetTranscription(ctx context.Context, key string) (*TranscriptionResult, error)
+	SaveTranscription(ctx context.Context, key string, result *TranscriptionResult) error
+}
@@ -2,10 +2,94 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"slices"
+	"strings"
 )
 
+// ErrNoSpeech is returned, possibly wrapped, when audio contains nothing to transcribe
+var ErrNoSpeech = errors.New("no speech in audio")
+
+// ErrProviderUnavailable is returned, possibly wrapped, when a provider is rate limited or
+// cannot be reached, so the same request may succeed with another provider
+var ErrProviderUnavailable = errors.New("transcription provider unavailable")
+
+// Errors returned, possibly wrapped, by transcription providers for failures callers
+// tell apart. Quota and timeout failures are also ErrProviderUnavailable.
+var (
+	ErrProviderAuthFailed    = errors.New("transcription provider rejected the credentials")
+	ErrProviderQuotaExceeded = fmt.Errorf("transcription provider quota exceeded: %w", ErrProviderUnavailable)
+	ErrProviderTimeout       = fmt.Errorf("transcription provider timed out: %w", ErrProviderUnavailable)
+	ErrAudioTooLarge         = errors.New("audio exceeds the provider's size limit")
+	ErrUnsupportedAudio      = errors.New("audio format not supported by the provider")
+)
+
+// TranscriptSegment is a timed stretch of a transcript. Times are in seconds from the start of the audio.
+type TranscriptSegment struct {
+	ID         int     `json:"id"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Text       string  `json:"text"`
+	AvgLogprob float64 `json:"avg_logprob"`
+}
+
+// TranscriptWord is the timing of a single transcribed word
+type TranscriptWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TranscriptionResult is the outcome of transcribing audio. Segments and words are
+// empty when the provider does not report timings.
+type TranscriptionResult struct {
+	Text     string              `json:"text"`
+	Language string              `json:"language,omitempty"`
+	Duration float64             `json:"duration,omitempty"`
+	Segments []TranscriptSegment `json:"segments,omitempty"`
+	Words    []TranscriptWord    `json:"words,omitempty"`
+	// Provider names the provider that transcribed the audio, if known
+	Provider string `json:"provider,omitempty"`
+}
+
+// JoinProviders names the providers of results combined into one, in order of first use
+func JoinProviders(results []*TranscriptionResult) string {
+	var providers []string
+	for _, result := range results {
+		if result == nil || result.Provider == "" || slices.Contains(providers, result.Provider) {
+			continue
+		}
+		providers = append(providers, result.Provider)
+	}
+	return strings.Join(providers, ",")
+}
+
+// Transcription tasks
+const (
+	// TaskTranscribe transcribes speech in its original language
+	TaskTranscribe = "transcribe"
+	// TaskTranslate transcribes speech and translates it into English
+	TaskTranslate = "translate"
+)
+
+// TranscriptionOptions are per-request hints for the provider. Empty fields leave the
+// choice to the provider, which auto-detects the language and transcribes.
+type TranscriptionOptions struct {
+	// Language is the ISO-639-1 code of the spoken language, e.g. "pl"
+	Language string
+	// Prompt guides the style and vocabulary of the transcript, e.g. names and jargon
+	Prompt string
+	// Task is TaskTranscribe or TaskTranslate
+	Task string
+	// Model overrides the provider's configured model
+	Model string
+	// Temperature is the sampling temperature between 0 and 1; 0 is the provider default
+	Temperature float64
+}
+
 // TranscriptionService defines the interface for transcribing audio
 type TranscriptionService interface {
-	TranscribeAudio(ctx context.Context, audioData io.Reader, format string) (string, error)
-}
\ No newline at end of file
+	TranscribeAudio(ctx context.Context, audioData io.Reader, format string, opts TranscriptionOptions) (*TranscriptionResult, error)
+}